@@ -1,13 +1,21 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"tenant-management-service/internal/api/v1"
 	"tenant-management-service/internal/config"
 	"tenant-management-service/pkg/database"
 	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/worker"
 )
 
 func main() {
@@ -34,22 +42,49 @@ func main() {
 	defer database.Close(db)
 
 	// Run auto migrations (not recommended in prod)
-	if err := database.RunMigrations(db); err != nil {
+	if err := database.RunMigrations(db, appConfig.Database); err != nil {
 		logger.Error("Failed to run migrations", zap.String("error", err.Error()))
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
+	if err := database.SeedChannels(db); err != nil {
+		logger.Error("Failed to seed channel catalog", zap.String("error", err.Error()))
+		log.Fatalf("Failed to seed channel catalog: %v", err)
+	}
 
 	// Initialize Gin router
 	router := gin.Default()
 
-	// Register routes
-	v1.RegisterRoutes(router, db)
+	// Register routes and their background workers
+	workers := worker.NewGroup()
+	v1.RegisterRoutes(router, db, appConfig, workers)
+
+	// Start server; long-lived requests such as change streams end when shutdown begins
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	server := &http.Server{
+		Addr:        appConfig.Server.Port,
+		Handler:     router,
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
+	}
+	server.RegisterOnShutdown(cancelRequests)
+	go func() {
+		logger.Info("Server is starting", zap.String("port", appConfig.Server.Port))
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Failed to start server", zap.String("error", err.Error()))
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// Wait for a termination signal, then drain requests before stopping the workers
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	logger.Info("Server is shutting down")
 
-	// Start server
-	logger.Info("Server is starting", zap.String("port", appConfig.Server.Port))
-	if err := router.Run(appConfig.Server.Port); err != nil {
-		logger.Error("Failed to start server", zap.String("error", err.Error()))
-		log.Fatalf("Failed to start server: %v", err)
+	ctx, cancel := context.WithTimeout(context.Background(), appConfig.Server.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Error("Failed to shut down server gracefully", zap.String("error", err.Error()))
 	}
+	workers.Stop()
 
 }
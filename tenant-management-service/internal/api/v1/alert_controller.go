@@ -0,0 +1,79 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"tenant-management-service/internal/response"
+	"tenant-management-service/internal/service"
+	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/utils"
+)
+
+const (
+	defaultAlertLimit = 50
+	maxAlertLimit     = 500
+)
+
+type AlertController struct {
+	service *service.AlertService
+}
+
+func NewAlertController(service *service.AlertService) *AlertController {
+	return &AlertController{service: service}
+}
+
+// GetAlerts retrieves the quota alert history of a tenant, newest first.
+func (c *AlertController) GetAlerts(ctx *gin.Context) {
+	tenantID := ctx.Param("tenant_id")
+	channel := ctx.Query("channel")
+
+	limit, err := parseBoundedInt(ctx.Query("limit"), defaultAlertLimit, maxAlertLimit)
+	if err != nil {
+		logger.Warn("Invalid limit in GetAlerts", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid limit", "INVALID_INPUT", err.Error())
+		return
+	}
+	offset, err := parseOffset(ctx.Query("offset"))
+	if err != nil {
+		logger.Warn("Invalid offset in GetAlerts", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid offset", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	// Call service to fetch alerts
+	alerts, total, err := c.service.GetAlerts(tenantID, channel, limit, offset)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			logger.Warn("Invalid input in GetAlerts", zap.Error(err))
+			response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+			return
+		}
+		logger.Error("Failed to fetch quota alerts", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to fetch quota alerts", "FETCH_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Quota alerts retrieved successfully", zap.String("tenant_id", tenantID))
+	response.Success(ctx, http.StatusOK, "Quota alerts retrieved successfully", alerts,
+		gin.H{"total": total, "limit": limit, "offset": offset})
+}
+
+// parseOffset parses an optional non-negative pagination offset.
+func parseOffset(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, fmt.Errorf("value must not be negative, got %d", n)
+	}
+	return n, nil
+}
@@ -0,0 +1,199 @@
+package v1
+
+import (
+	"bytes"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"html/template"
+	"net/http"
+	"strconv"
+	"tenant-management-service/internal/model"
+	"tenant-management-service/internal/model/dto"
+	"tenant-management-service/internal/response"
+	"tenant-management-service/internal/service"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/utils"
+	"time"
+)
+
+const (
+	defaultInvoiceLimit = 20
+	maxInvoiceLimit     = 100
+	invoiceFormatJSON   = "json"
+	invoiceFormatHTML   = "html"
+)
+
+// invoiceTemplate renders an invoice as a printable HTML document.
+var invoiceTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"amount": model.FormatAmount,
+	"date":   func(t time.Time) string { return t.Format(time.DateOnly) },
+	"lastDay": func(t time.Time) string {
+		return t.AddDate(0, 0, -1).Format(time.DateOnly)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.Number}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ddd; padding: 0.4em; text-align: left; }
+td.num, th.num { text-align: right; }
+tfoot td { font-weight: bold; border-bottom: none; }
+</style>
+</head>
+<body>
+<h1>Invoice {{.Number}}</h1>
+<p>Tenant {{.TenantID}}, {{.BillingTier}} plan<br>
+Billing period {{date .PeriodStart}} to {{lastDay .PeriodEnd}}<br>
+Issued {{date .IssuedAt}}</p>
+<table>
+<thead><tr><th>Description</th><th class="num">Quantity</th><th class="num">Price per 1000</th><th class="num">Amount</th></tr></thead>
+<tbody>
+{{- $currency := .Currency}}
+{{- range .LineItems}}
+<tr><td>{{.Description}}</td><td class="num">{{.Quantity}}</td><td class="num">{{if ne .Kind "base"}}{{amount .PricePerThousand $currency}}{{end}}</td><td class="num">{{amount .Amount $currency}}</td></tr>
+{{- end}}
+</tbody>
+<tfoot><tr><td colspan="3">Total</td><td class="num">{{amount .Total .Currency}}</td></tr></tfoot>
+</table>
+</body>
+</html>
+`))
+
+type BillingController struct {
+	service *service.BillingService
+}
+
+func NewBillingController(service *service.BillingService) *BillingController {
+	return &BillingController{service: service}
+}
+
+// UpsertPlan handles creating or updating the plan of a billing tier.
+func (c *BillingController) UpsertPlan(ctx *gin.Context) {
+	var planDTO dto.PlanDTO
+
+	// Validate input
+	if err := ctx.ShouldBindJSON(&planDTO); err != nil {
+		logger.Warn("Invalid input in UpsertPlan", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	// Call service to upsert the plan
+	plan, err := c.service.UpsertPlan(planDTO)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) || errors.Is(err, pkgerr.ErrInvalidInput) {
+			logger.Warn("Invalid plan in UpsertPlan", zap.Error(err))
+			response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+			return
+		}
+		logger.Error("Failed to upsert plan", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to upsert plan", "UPSERT_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Plan upserted successfully", zap.String("billing_tier", plan.BillingTier))
+	response.Success(ctx, http.StatusOK, "Plan upserted successfully", plan, nil)
+}
+
+// GetPlans retrieves all plans.
+func (c *BillingController) GetPlans(ctx *gin.Context) {
+	plans, err := c.service.GetPlans()
+	if err != nil {
+		logger.Error("Failed to fetch plans", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to fetch plans", "FETCH_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Plans retrieved successfully")
+	response.Success(ctx, http.StatusOK, "Plans retrieved successfully", plans, nil)
+}
+
+// GetInvoices retrieves the invoices of a tenant, newest period first.
+func (c *BillingController) GetInvoices(ctx *gin.Context) {
+	tenantID := ctx.Param("tenant_id")
+
+	limit, err := parseBoundedInt(ctx.Query("limit"), defaultInvoiceLimit, maxInvoiceLimit)
+	if err != nil {
+		logger.Warn("Invalid limit in GetInvoices", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid limit", "INVALID_INPUT", err.Error())
+		return
+	}
+	offset, err := parseOffset(ctx.Query("offset"))
+	if err != nil {
+		logger.Warn("Invalid offset in GetInvoices", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid offset", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	// Call service to fetch invoices
+	invoices, total, err := c.service.GetInvoices(tenantID, limit, offset)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			logger.Warn("Invalid input in GetInvoices", zap.Error(err))
+			response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+			return
+		}
+		logger.Error("Failed to fetch invoices", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to fetch invoices", "FETCH_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Invoices retrieved successfully", zap.String("tenant_id", tenantID))
+	response.Success(ctx, http.StatusOK, "Invoices retrieved successfully", invoices,
+		gin.H{"total": total, "limit": limit, "offset": offset})
+}
+
+// GetInvoice retrieves an invoice of a tenant with its line items, as JSON or, with
+// format=html, as a printable HTML document.
+func (c *BillingController) GetInvoice(ctx *gin.Context) {
+	tenantID := ctx.Param("tenant_id")
+
+	// Validate input
+	id, err := strconv.ParseUint(ctx.Param("invoice_id"), 10, 64)
+	if err != nil {
+		logger.Warn("Invalid invoice ID in GetInvoice", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid invoice ID", "INVALID_INPUT", err.Error())
+		return
+	}
+	format := ctx.DefaultQuery("format", invoiceFormatJSON)
+	if format != invoiceFormatJSON && format != invoiceFormatHTML {
+		logger.Warn("Invalid format in GetInvoice", zap.String("format", format))
+		response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", "format must be json or html")
+		return
+	}
+
+	// Call service to fetch the invoice
+	invoice, err := c.service.GetInvoice(tenantID, uint(id))
+	if err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			logger.Warn("Invoice not found", zap.String("tenant_id", tenantID), zap.Uint64("invoice_id", id))
+			response.Error(ctx, http.StatusNotFound, "Invoice not found", "NOT_FOUND", err.Error())
+			return
+		}
+		logger.Error("Failed to fetch invoice", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to fetch invoice", "FETCH_FAILED", err.Error())
+		return
+	}
+
+	if format == invoiceFormatHTML {
+		var page bytes.Buffer
+		if err := invoiceTemplate.Execute(&page, invoice); err != nil {
+			logger.Error("Failed to render invoice", zap.Error(err))
+			response.Error(ctx, http.StatusInternalServerError, "Failed to render invoice", "RENDER_FAILED", err.Error())
+			return
+		}
+		logger.Info("Invoice rendered successfully", zap.String("tenant_id", tenantID), zap.String("number", invoice.Number))
+		ctx.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+		return
+	}
+
+	logger.Info("Invoice retrieved successfully", zap.String("tenant_id", tenantID), zap.String("number", invoice.Number))
+	response.Success(ctx, http.StatusOK, "Invoice retrieved successfully", invoice, nil)
+}
@@ -0,0 +1,169 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strconv"
+	"tenant-management-service/internal/response"
+	"tenant-management-service/internal/service"
+	"tenant-management-service/pkg/logger"
+	"time"
+)
+
+const (
+	defaultChangeLimit   = 100
+	maxChangeLimit       = 500
+	defaultChangeTimeout = 30 * time.Second
+	maxChangeTimeout     = 60 * time.Second
+	streamHeartbeat      = 15 * time.Second
+)
+
+type ChangeController struct {
+	service *service.ChangeService
+}
+
+func NewChangeController(service *service.ChangeService) *ChangeController {
+	return &ChangeController{service: service}
+}
+
+// Watch long-polls configuration and quota changes of a tenant.
+func (c *ChangeController) Watch(ctx *gin.Context) {
+	c.watch(ctx, ctx.Param("tenant_id"))
+}
+
+// WatchAll long-polls configuration and quota changes of every tenant.
+func (c *ChangeController) WatchAll(ctx *gin.Context) {
+	c.watch(ctx, "")
+}
+
+// Stream pushes configuration and quota changes of a tenant as Server-Sent Events.
+func (c *ChangeController) Stream(ctx *gin.Context) {
+	c.stream(ctx, ctx.Param("tenant_id"))
+}
+
+// StreamAll pushes configuration and quota changes of every tenant as Server-Sent Events.
+func (c *ChangeController) StreamAll(ctx *gin.Context) {
+	c.stream(ctx, "")
+}
+
+func (c *ChangeController) watch(ctx *gin.Context, tenantID string) {
+	since, err := parseRevision(ctx.Query("since"))
+	if err != nil {
+		logger.Warn("Invalid revision in WatchChanges", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid revision", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	limit, err := parseBoundedInt(ctx.Query("limit"), defaultChangeLimit, maxChangeLimit)
+	if err != nil {
+		logger.Warn("Invalid limit in WatchChanges", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid limit", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	timeoutSeconds, err := parseBoundedInt(ctx.Query("timeout"), int(defaultChangeTimeout.Seconds()), int(maxChangeTimeout.Seconds()))
+	if err != nil {
+		logger.Warn("Invalid timeout in WatchChanges", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid timeout", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	// Block until changes are available or the timeout elapses
+	events, err := c.service.Poll(ctx.Request.Context(), tenantID, since, limit, time.Duration(timeoutSeconds)*time.Second)
+	if err != nil {
+		logger.Error("Failed to fetch changes", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to fetch changes", "FETCH_FAILED", err.Error())
+		return
+	}
+
+	// The cursor to resume from is the last revision delivered
+	revision := since
+	if len(events) > 0 {
+		revision = events[len(events)-1].ID
+	}
+
+	response.Success(ctx, http.StatusOK, "Changes retrieved successfully", events, gin.H{"revision": revision})
+}
+
+func (c *ChangeController) stream(ctx *gin.Context, tenantID string) {
+	// Resume from Last-Event-ID on reconnect, otherwise from the since query parameter
+	cursor := ctx.GetHeader("Last-Event-ID")
+	if cursor == "" {
+		cursor = ctx.Query("since")
+	}
+	since, err := parseRevision(cursor)
+	if err != nil {
+		logger.Warn("Invalid revision in StreamChanges", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid revision", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	ctx.Header("X-Accel-Buffering", "no")
+
+	logger.Info("Change stream opened", zap.String("tenant_id", tenantID), zap.Uint64("since", since))
+	ctx.Stream(func(w io.Writer) bool {
+		// Stop once the client disconnects or the server shuts down
+		if ctx.Request.Context().Err() != nil {
+			return false
+		}
+
+		events, err := c.service.Poll(ctx.Request.Context(), tenantID, since, maxChangeLimit, streamHeartbeat)
+		if err != nil {
+			logger.Error("Failed to fetch changes for stream", zap.Error(err))
+			return false
+		}
+
+		// Keep idle connections alive through proxies
+		if len(events) == 0 {
+			_, err = fmt.Fprint(w, ": heartbeat\n\n")
+			return err == nil
+		}
+
+		for _, event := range events {
+			if err := sse.Encode(w, sse.Event{
+				Id:    strconv.FormatUint(event.ID, 10),
+				Event: event.Resource,
+				Data:  event,
+			}); err != nil {
+				return false
+			}
+			since = event.ID
+		}
+		return true
+	})
+	logger.Info("Change stream closed", zap.String("tenant_id", tenantID), zap.Uint64("revision", since))
+}
+
+// parseRevision parses a revision cursor, treating an empty value as the beginning of the log.
+func parseRevision(value string) (uint64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
+
+// parseBoundedInt parses a positive integer query value, falling back to def when empty
+// and capping it at max.
+func parseBoundedInt(value string, def, max int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if n <= 0 {
+		return 0, fmt.Errorf("value must be positive, got %d", n)
+	}
+	if n > max {
+		n = max
+	}
+	return n, nil
+}
@@ -0,0 +1,79 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"tenant-management-service/internal/model/dto"
+	"tenant-management-service/internal/response"
+	"tenant-management-service/internal/service"
+	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/middleware"
+	"tenant-management-service/pkg/utils"
+)
+
+type ChannelController struct {
+	service *service.ChannelService
+}
+
+func NewChannelController(service *service.ChannelService) *ChannelController {
+	return &ChannelController{service: service}
+}
+
+// UpsertChannel handles creating or updating a channel of the catalog.
+func (c *ChannelController) UpsertChannel(ctx *gin.Context) {
+	var channelDTO dto.ChannelDTO
+
+	// Validate input
+	if err := ctx.ShouldBindJSON(&channelDTO); err != nil {
+		logger.Warn("Invalid input in UpsertChannel", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	// Call service to upsert the channel
+	channel, err := c.service.UpsertChannel(channelDTO)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			logger.Warn("Invalid channel in UpsertChannel", zap.Error(err))
+			response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+			return
+		}
+		logger.Error("Failed to upsert channel", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to upsert channel", "UPSERT_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Channel upserted successfully", zap.String("code", channel.Code))
+	response.Success(ctx, http.StatusOK, "Channel upserted successfully", channel, nil)
+}
+
+// GetChannels retrieves the whole channel catalog.
+func (c *ChannelController) GetChannels(ctx *gin.Context) {
+	channels, err := c.service.GetChannels()
+	if err != nil {
+		logger.Error("Failed to fetch channels", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to fetch channels", "FETCH_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Channels retrieved successfully")
+	response.Success(ctx, http.StatusOK, "Channels retrieved successfully", channels, nil)
+}
+
+// GetAvailableChannels lists the channel catalog with availability on the calling tenant's plan.
+func (c *ChannelController) GetAvailableChannels(ctx *gin.Context) {
+	tenant := middleware.CurrentTenant(ctx)
+
+	channels, err := c.service.GetAvailableChannels(tenant)
+	if err != nil {
+		logger.Error("Failed to fetch channels", zap.Uint("tenant_id", tenant.ID), zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to fetch channels", "FETCH_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Channels retrieved successfully", zap.Uint("tenant_id", tenant.ID))
+	response.Success(ctx, http.StatusOK, "Channels retrieved successfully", channels, nil)
+}
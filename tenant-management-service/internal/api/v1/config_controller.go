@@ -1,12 +1,16 @@
 package v1
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"io"
 	"net/http"
 	"tenant-management-service/internal/response"
 	"tenant-management-service/internal/service"
+	pkgerr "tenant-management-service/pkg/error"
 	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/utils"
 )
 
 type ConfigController struct {
@@ -25,6 +29,7 @@ func (c *ConfigController) UpsertConfig(ctx *gin.Context) {
 		ConfigKey   string `json:"config_key" binding:"required"`
 		ConfigValue string `json:"config_value" binding:"required"`
 		IsGlobal    bool   `json:"is_global"`
+		IsSecret    bool   `json:"is_secret"`
 	}
 
 	// Validate input
@@ -39,10 +44,10 @@ func (c *ConfigController) UpsertConfig(ctx *gin.Context) {
 		ConfigKey   string
 		ConfigValue string
 		IsGlobal    bool
+		IsSecret    bool
 	}(configs))
 	if err != nil {
-		logger.Error("Failed to upsert configurations", zap.Error(err))
-		response.Error(ctx, http.StatusInternalServerError, "Failed to upsert configurations", "UPSERT_FAILED", err.Error())
+		respondConfigError(ctx, err, "Failed to upsert configurations", "UPSERT_FAILED")
 		return
 	}
 
@@ -65,3 +70,107 @@ func (c *ConfigController) GetConfigs(ctx *gin.Context) {
 	logger.Info("Configurations retrieved successfully", zap.String("tenant_id", tenantID))
 	response.Success(ctx, 200, "Configurations retrieved successfully", configs, nil)
 }
+
+// GetJSONConfig retrieves the structured value of a configuration key, optionally narrowed
+// to the sub-document selected by the pointer query parameter.
+func (c *ConfigController) GetJSONConfig(ctx *gin.Context) {
+	tenantID := ctx.Param("tenant_id")
+	configKey := ctx.Param("key")
+
+	value, err := c.service.GetJSONConfiguration(tenantID, configKey, ctx.Query("pointer"))
+	if err != nil {
+		respondConfigError(ctx, err, "Failed to fetch configuration", "FETCH_FAILED")
+		return
+	}
+
+	logger.Info("JSON configuration retrieved successfully", zap.String("tenant_id", tenantID), zap.String("config_key", configKey))
+	response.Success(ctx, http.StatusOK, "Configuration retrieved successfully", value, nil)
+}
+
+// PutJSONConfig replaces the structured value of a configuration key.
+func (c *ConfigController) PutJSONConfig(ctx *gin.Context) {
+	c.setJSONConfig(ctx, "")
+}
+
+// PatchJSONConfig replaces the sub-document selected by the pointer query parameter.
+func (c *ConfigController) PatchJSONConfig(ctx *gin.Context) {
+	pointer := ctx.Query("pointer")
+	if pointer == "" {
+		logger.Warn("Missing pointer in PatchJSONConfig")
+		response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", "pointer query parameter is required")
+		return
+	}
+	c.setJSONConfig(ctx, pointer)
+}
+
+func (c *ConfigController) setJSONConfig(ctx *gin.Context, pointer string) {
+	tenantID := ctx.Param("tenant_id")
+	configKey := ctx.Param("key")
+
+	// Read the raw document, rejecting bodies above the size limit
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, service.MaxJSONConfigSize+1))
+	if err != nil {
+		logger.Warn("Invalid input in SetJSONConfig", zap.Error(err))
+		response.Error(ctx, http.StatusRequestEntityTooLarge, "Invalid input", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	config, err := c.service.SetJSONConfiguration(tenantID, configKey, pointer, body)
+	if err != nil {
+		respondConfigError(ctx, err, "Failed to store configuration", "UPSERT_FAILED")
+		return
+	}
+
+	logger.Info("JSON configuration stored successfully", zap.String("tenant_id", tenantID), zap.String("config_key", configKey))
+	response.Success(ctx, http.StatusOK, "Configuration stored successfully", config, nil)
+}
+
+// UpsertSchema registers or replaces the JSON Schema of a configuration key.
+func (c *ConfigController) UpsertSchema(ctx *gin.Context) {
+	configKey := ctx.Param("key")
+
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, service.MaxJSONConfigSize))
+	if err != nil {
+		logger.Warn("Invalid input in UpsertSchema", zap.Error(err))
+		response.Error(ctx, http.StatusRequestEntityTooLarge, "Invalid input", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	schema, err := c.service.UpsertConfigSchema(configKey, body)
+	if err != nil {
+		respondConfigError(ctx, err, "Failed to upsert configuration schema", "UPSERT_FAILED")
+		return
+	}
+
+	logger.Info("Configuration schema upserted successfully", zap.String("config_key", configKey))
+	response.Success(ctx, http.StatusOK, "Configuration schema upserted successfully", schema, nil)
+}
+
+// GetSchemas retrieves all registered configuration schemas.
+func (c *ConfigController) GetSchemas(ctx *gin.Context) {
+	schemas, err := c.service.GetConfigSchemas()
+	if err != nil {
+		logger.Error("Failed to fetch configuration schemas", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to fetch configuration schemas", "FETCH_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Configuration schemas retrieved successfully")
+	response.Success(ctx, http.StatusOK, "Configuration schemas retrieved successfully", schemas, nil)
+}
+
+// respondConfigError maps configuration service errors to HTTP responses.
+func respondConfigError(ctx *gin.Context, err error, message, errorCode string) {
+	var validationErr *utils.ValidationError
+	switch {
+	case errors.As(err, &validationErr), errors.Is(err, pkgerr.ErrInvalidInput):
+		logger.Warn("Invalid configuration input", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+	case errors.Is(err, pkgerr.ErrNotFound):
+		logger.Warn("Configuration not found", zap.Error(err))
+		response.Error(ctx, http.StatusNotFound, "Configuration not found", "NOT_FOUND", err.Error())
+	default:
+		logger.Error(message, zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, message, errorCode, err.Error())
+	}
+}
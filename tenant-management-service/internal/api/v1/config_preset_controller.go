@@ -0,0 +1,131 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"tenant-management-service/internal/model/dto"
+	"tenant-management-service/internal/response"
+	"tenant-management-service/internal/service"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/utils"
+)
+
+type ConfigPresetController struct {
+	service *service.ConfigPresetService
+}
+
+func NewConfigPresetController(service *service.ConfigPresetService) *ConfigPresetController {
+	return &ConfigPresetController{service: service}
+}
+
+// UpsertPreset handles creating or updating a configuration preset.
+func (c *ConfigPresetController) UpsertPreset(ctx *gin.Context) {
+	var presetDTO dto.ConfigPresetDTO
+
+	// Validate input
+	if err := ctx.ShouldBindJSON(&presetDTO); err != nil {
+		logger.Warn("Invalid input in UpsertPreset", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	// Call service to upsert the preset
+	preset, err := c.service.UpsertPreset(presetDTO)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			logger.Warn("Invalid preset in UpsertPreset", zap.Error(err))
+			response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+			return
+		}
+		logger.Error("Failed to upsert configuration preset", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to upsert configuration preset", "UPSERT_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Configuration preset upserted successfully", zap.String("preset", preset.Name))
+	response.Success(ctx, http.StatusOK, "Configuration preset upserted successfully", preset, nil)
+}
+
+// GetPresets retrieves all configuration presets.
+func (c *ConfigPresetController) GetPresets(ctx *gin.Context) {
+	presets, err := c.service.GetPresets()
+	if err != nil {
+		logger.Error("Failed to fetch configuration presets", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to fetch configuration presets", "FETCH_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Configuration presets retrieved successfully")
+	response.Success(ctx, http.StatusOK, "Configuration presets retrieved successfully", presets, nil)
+}
+
+// DeletePreset handles deleting a configuration preset by name.
+func (c *ConfigPresetController) DeletePreset(ctx *gin.Context) {
+	name := ctx.Param("name")
+
+	if err := c.service.DeletePreset(name); err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			logger.Warn("Configuration preset not found", zap.String("preset", name))
+			response.Error(ctx, http.StatusNotFound, "Configuration preset not found", "NOT_FOUND", err.Error())
+			return
+		}
+		logger.Error("Failed to delete configuration preset", zap.String("preset", name), zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to delete configuration preset", "DELETE_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Configuration preset deleted successfully", zap.String("preset", name))
+	response.Success(ctx, http.StatusOK, "Configuration preset deleted successfully", nil, nil)
+}
+
+// ApplyPreset applies a configuration preset to a list of tenants or a billing tier.
+func (c *ConfigPresetController) ApplyPreset(ctx *gin.Context) {
+	c.apply(ctx, false)
+}
+
+// PreviewPreset reports what applying a configuration preset would change, without writing.
+func (c *ConfigPresetController) PreviewPreset(ctx *gin.Context) {
+	c.apply(ctx, true)
+}
+
+func (c *ConfigPresetController) apply(ctx *gin.Context, preview bool) {
+	name := ctx.Param("name")
+	var applyDTO dto.ApplyPresetDTO
+
+	// Validate input
+	if err := ctx.ShouldBindJSON(&applyDTO); err != nil {
+		logger.Warn("Invalid input in ApplyPreset", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+		return
+	}
+	applyDTO.DryRun = applyDTO.DryRun || preview
+
+	// Call service to apply the preset
+	reports, err := c.service.ApplyPreset(name, applyDTO)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			logger.Warn("Invalid input in ApplyPreset", zap.Error(err))
+			response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+		case errors.Is(err, pkgerr.ErrNotFound):
+			logger.Warn("Configuration preset not found", zap.String("preset", name))
+			response.Error(ctx, http.StatusNotFound, "Configuration preset not found", "NOT_FOUND", err.Error())
+		default:
+			logger.Error("Failed to apply configuration preset", zap.String("preset", name), zap.Error(err))
+			response.Error(ctx, http.StatusInternalServerError, "Failed to apply configuration preset", "APPLY_FAILED", err.Error())
+		}
+		return
+	}
+
+	message := "Configuration preset applied successfully"
+	if applyDTO.DryRun {
+		message = "Configuration preset previewed successfully"
+	}
+	logger.Info(message, zap.String("preset", name))
+	response.Success(ctx, http.StatusOK, message, reports, gin.H{"dry_run": applyDTO.DryRun})
+}
@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"tenant-management-service/internal/model/dto"
+	"tenant-management-service/internal/response"
+	"tenant-management-service/internal/service"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/middleware"
+	"tenant-management-service/pkg/utils"
+)
+
+type DeviceController struct {
+	service *service.DeviceService
+}
+
+func NewDeviceController(service *service.DeviceService) *DeviceController {
+	return &DeviceController{service: service}
+}
+
+// RegisterDevice handles registering a push device token for a recipient of the calling tenant.
+func (c *DeviceController) RegisterDevice(ctx *gin.Context) {
+	tenantID := strconv.FormatUint(uint64(middleware.CurrentTenant(ctx).ID), 10)
+	var deviceDTO dto.DeviceTokenDTO
+
+	// Validate input
+	if err := ctx.ShouldBindJSON(&deviceDTO); err != nil {
+		logger.Warn("Invalid input in RegisterDevice", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	// Call service to register the device
+	device, err := c.service.RegisterDevice(tenantID, deviceDTO)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			logger.Warn("Invalid device token in RegisterDevice", zap.Error(err))
+			response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+			return
+		}
+		logger.Error("Failed to register device token", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to register device token", "UPSERT_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Device token registered successfully", zap.String("tenant_id", tenantID), zap.Uint("device_id", device.ID))
+	response.Success(ctx, http.StatusOK, "Device token registered successfully", device, nil)
+}
+
+// GetDevices retrieves the device tokens registered for the recipient given as query parameter.
+func (c *DeviceController) GetDevices(ctx *gin.Context) {
+	tenantID := strconv.FormatUint(uint64(middleware.CurrentTenant(ctx).ID), 10)
+	recipient := ctx.Query("recipient")
+
+	devices, err := c.service.GetDevices(tenantID, recipient)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			logger.Warn("Invalid input in GetDevices", zap.Error(err))
+			response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+			return
+		}
+		logger.Error("Failed to fetch device tokens", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to fetch device tokens", "FETCH_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Device tokens retrieved successfully", zap.String("tenant_id", tenantID))
+	response.Success(ctx, http.StatusOK, "Device tokens retrieved successfully", devices, nil)
+}
+
+// DeleteDevice unregisters a device token of the calling tenant.
+func (c *DeviceController) DeleteDevice(ctx *gin.Context) {
+	tenantID := strconv.FormatUint(uint64(middleware.CurrentTenant(ctx).ID), 10)
+
+	// Validate input
+	id, err := strconv.ParseUint(ctx.Param("device_id"), 10, 64)
+	if err != nil {
+		logger.Warn("Invalid device ID in DeleteDevice", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid device ID", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	if err := c.service.DeleteDevice(tenantID, uint(id)); err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			logger.Warn("Device token not found", zap.String("tenant_id", tenantID), zap.Uint64("device_id", id))
+			response.Error(ctx, http.StatusNotFound, "Device token not found", "NOT_FOUND", err.Error())
+			return
+		}
+		logger.Error("Failed to delete device token", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to delete device token", "DELETE_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Device token deleted successfully", zap.String("tenant_id", tenantID), zap.Uint64("device_id", id))
+	response.Success(ctx, http.StatusOK, "Device token deleted successfully", nil, nil)
+}
@@ -0,0 +1,99 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"tenant-management-service/internal/model/dto"
+	"tenant-management-service/internal/response"
+	"tenant-management-service/internal/service"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/middleware"
+	"tenant-management-service/pkg/utils"
+)
+
+type FeatureFlagController struct {
+	service *service.FeatureFlagService
+}
+
+func NewFeatureFlagController(service *service.FeatureFlagService) *FeatureFlagController {
+	return &FeatureFlagController{service: service}
+}
+
+// UpsertFlag handles creating or updating a feature flag definition.
+func (c *FeatureFlagController) UpsertFlag(ctx *gin.Context) {
+	var flagDTO dto.FeatureFlagDTO
+
+	// Validate input
+	if err := ctx.ShouldBindJSON(&flagDTO); err != nil {
+		logger.Warn("Invalid input in UpsertFlag", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	// Call service to upsert the flag
+	flag, err := c.service.UpsertFlag(flagDTO)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			logger.Warn("Invalid feature flag in UpsertFlag", zap.Error(err))
+			response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+			return
+		}
+		logger.Error("Failed to upsert feature flag", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to upsert feature flag", "UPSERT_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Feature flag upserted successfully", zap.String("key", flag.Key))
+	response.Success(ctx, http.StatusOK, "Feature flag upserted successfully", flag, nil)
+}
+
+// GetFlags retrieves all feature flag definitions.
+func (c *FeatureFlagController) GetFlags(ctx *gin.Context) {
+	flags, err := c.service.GetFlags()
+	if err != nil {
+		logger.Error("Failed to fetch feature flags", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to fetch feature flags", "FETCH_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Feature flags retrieved successfully")
+	response.Success(ctx, http.StatusOK, "Feature flags retrieved successfully", flags, nil)
+}
+
+// DeleteFlag handles deleting a feature flag definition by its key.
+func (c *FeatureFlagController) DeleteFlag(ctx *gin.Context) {
+	key := ctx.Param("key")
+
+	if err := c.service.DeleteFlag(key); err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			logger.Warn("Feature flag not found", zap.String("key", key))
+			response.Error(ctx, http.StatusNotFound, "Feature flag not found", "NOT_FOUND", err.Error())
+			return
+		}
+		logger.Error("Failed to delete feature flag", zap.String("key", key), zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to delete feature flag", "DELETE_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Feature flag deleted successfully", zap.String("key", key))
+	response.Success(ctx, http.StatusOK, "Feature flag deleted successfully", nil, nil)
+}
+
+// Evaluate returns the state of every feature flag for the calling tenant.
+func (c *FeatureFlagController) Evaluate(ctx *gin.Context) {
+	tenant := middleware.CurrentTenant(ctx)
+
+	evaluations, err := c.service.EvaluateFlags(tenant)
+	if err != nil {
+		logger.Error("Failed to evaluate feature flags", zap.Uint("tenant_id", tenant.ID), zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to evaluate feature flags", "EVALUATION_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Feature flags evaluated successfully", zap.Uint("tenant_id", tenant.ID))
+	response.Success(ctx, http.StatusOK, "Feature flags evaluated successfully", evaluations, nil)
+}
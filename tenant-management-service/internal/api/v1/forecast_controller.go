@@ -0,0 +1,49 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"tenant-management-service/internal/response"
+	"tenant-management-service/internal/service"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/utils"
+)
+
+type ForecastController struct {
+	service *service.ForecastService
+}
+
+func NewForecastController(service *service.ForecastService) *ForecastController {
+	return &ForecastController{service: service}
+}
+
+// GetForecast projects usage of a tenant's channels to the end of the month and reports
+// when each quota window is projected to run out.
+func (c *ForecastController) GetForecast(ctx *gin.Context) {
+	tenantID := ctx.Param("tenant_id")
+	channel := ctx.Query("channel")
+
+	// Call service to compute the forecast
+	forecasts, err := c.service.GetForecast(tenantID, channel)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			logger.Warn("Invalid input in GetForecast", zap.Error(err))
+			response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+		case errors.Is(err, pkgerr.ErrNotFound):
+			logger.Warn("Tenant not found in GetForecast", zap.String("tenant_id", tenantID))
+			response.Error(ctx, http.StatusNotFound, "Tenant not found", "NOT_FOUND", err.Error())
+		default:
+			logger.Error("Failed to compute usage forecast", zap.Error(err))
+			response.Error(ctx, http.StatusInternalServerError, "Failed to compute usage forecast", "FETCH_FAILED", err.Error())
+		}
+		return
+	}
+
+	logger.Info("Usage forecast computed successfully", zap.String("tenant_id", tenantID), zap.String("channel", channel))
+	response.Success(ctx, http.StatusOK, "Usage forecast computed successfully", forecasts, nil)
+}
@@ -0,0 +1,120 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"tenant-management-service/internal/model/dto"
+	"tenant-management-service/internal/response"
+	"tenant-management-service/internal/service"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/middleware"
+	"tenant-management-service/pkg/utils"
+)
+
+type NotificationController struct {
+	service *service.NotificationService
+}
+
+func NewNotificationController(service *service.NotificationService) *NotificationController {
+	return &NotificationController{service: service}
+}
+
+// Send accepts a notification of the calling tenant for delivery, answering 429 when a
+// rate limit or quota would be exceeded. Delivery happens in the background; its progress
+// is available through GetNotification.
+func (c *NotificationController) Send(ctx *gin.Context) {
+	tenant := middleware.CurrentTenant(ctx)
+	tenantID := strconv.FormatUint(uint64(tenant.ID), 10)
+	var notificationDTO dto.NotificationDTO
+
+	// Validate input
+	if err := ctx.ShouldBindJSON(&notificationDTO); err != nil {
+		logger.Warn("Invalid input in Send", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	// Call service to accept the notification
+	notification, decision, err := c.service.Send(tenant, notificationDTO)
+	if decision != nil {
+		setRateLimitHeaders(ctx, decision)
+	}
+	if err != nil {
+		respondQuotaError(ctx, err, tenantID, notificationDTO.Channel)
+		return
+	}
+
+	logger.Info("Notification accepted", zap.String("tenant_id", tenantID), zap.String("notification_id", notification.ID),
+		zap.String("channel", notification.Channel))
+	response.Success(ctx, http.StatusAccepted, "Notification accepted", notification, nil)
+}
+
+// GetNotification retrieves a notification of the calling tenant with its delivery status.
+func (c *NotificationController) GetNotification(ctx *gin.Context) {
+	tenantID := strconv.FormatUint(uint64(middleware.CurrentTenant(ctx).ID), 10)
+	id := ctx.Param("notification_id")
+
+	notification, err := c.service.GetNotification(tenantID, id)
+	if err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			logger.Warn("Notification not found", zap.String("tenant_id", tenantID), zap.String("notification_id", id))
+			response.Error(ctx, http.StatusNotFound, "Notification not found", "NOT_FOUND", err.Error())
+			return
+		}
+		logger.Error("Failed to fetch notification", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to fetch notification", "FETCH_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Notification retrieved successfully", zap.String("tenant_id", tenantID), zap.String("notification_id", id))
+	response.Success(ctx, http.StatusOK, "Notification retrieved successfully", notification, nil)
+}
+
+// GetAttempts retrieves the delivery attempts of a notification of the calling tenant.
+func (c *NotificationController) GetAttempts(ctx *gin.Context) {
+	tenantID := strconv.FormatUint(uint64(middleware.CurrentTenant(ctx).ID), 10)
+	id := ctx.Param("notification_id")
+
+	attempts, err := c.service.GetAttempts(tenantID, id)
+	if err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			logger.Warn("Notification not found", zap.String("tenant_id", tenantID), zap.String("notification_id", id))
+			response.Error(ctx, http.StatusNotFound, "Notification not found", "NOT_FOUND", err.Error())
+			return
+		}
+		logger.Error("Failed to fetch notification attempts", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to fetch notification attempts", "FETCH_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Notification attempts retrieved successfully", zap.String("tenant_id", tenantID), zap.String("notification_id", id))
+	response.Success(ctx, http.StatusOK, "Notification attempts retrieved successfully", attempts, nil)
+}
+
+// CheckProviderHealth checks the provider the calling tenant selected for a channel with
+// the tenant's channel settings.
+func (c *NotificationController) CheckProviderHealth(ctx *gin.Context) {
+	tenantID := strconv.FormatUint(uint64(middleware.CurrentTenant(ctx).ID), 10)
+	channel := ctx.Param("code")
+
+	report, err := c.service.CheckProviderHealth(ctx.Request.Context(), tenantID, channel)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			logger.Warn("Invalid input in CheckProviderHealth", zap.Error(err))
+			response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+			return
+		}
+		logger.Error("Failed to check provider health", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to check provider health", "HEALTH_CHECK_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Provider health checked", zap.String("tenant_id", tenantID), zap.String("channel", report.Channel),
+		zap.String("provider", report.Provider), zap.Bool("healthy", report.Healthy))
+	response.Success(ctx, http.StatusOK, "Provider health checked", report, nil)
+}
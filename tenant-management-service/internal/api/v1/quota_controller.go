@@ -1,13 +1,20 @@
 package v1
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"math"
 	"net/http"
+	"strconv"
 	"tenant-management-service/internal/model/dto"
 	"tenant-management-service/internal/response"
 	"tenant-management-service/internal/service"
+	pkgerr "tenant-management-service/pkg/error"
 	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/ratelimit"
+	"tenant-management-service/pkg/utils"
+	"time"
 )
 
 type QuotaController struct {
@@ -33,6 +40,12 @@ func (c *QuotaController) UpdateQuota(ctx *gin.Context) {
 	// Call service to update quotas
 	err := c.service.UpdateQuotas(tenantID, quotas)
 	if err != nil {
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			logger.Warn("Invalid quota in UpdateQuota", zap.Error(err))
+			response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+			return
+		}
 		logger.Error("Failed to update quotas", zap.Error(err))
 		response.Error(ctx, http.StatusInternalServerError, "Failed to update quotas", "UPDATE_FAILED", err.Error())
 		return
@@ -57,3 +70,203 @@ func (c *QuotaController) GetQuotas(ctx *gin.Context) {
 	logger.Info("Quotas retrieved successfully", zap.String("tenant_id", tenantID))
 	response.Success(ctx, http.StatusOK, "Quotas retrieved successfully", quotas, nil)
 }
+
+// GetQuotaStatus returns the limit, usage, remaining allowance and reset time of every
+// quota window of a tenant, optionally filtered by channel.
+func (c *QuotaController) GetQuotaStatus(ctx *gin.Context) {
+	tenantID := ctx.Param("tenant_id")
+	channel := ctx.Query("channel") // Optional query parameter to filter by channel
+
+	statuses, err := c.service.GetQuotaStatus(tenantID, channel)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			logger.Warn("Invalid input in GetQuotaStatus", zap.Error(err))
+			response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+		case errors.Is(err, pkgerr.ErrNotFound):
+			logger.Warn("Quota not found", zap.String("tenant_id", tenantID), zap.String("channel", channel))
+			response.Error(ctx, http.StatusNotFound, "Quota not found", "NOT_FOUND", err.Error())
+		default:
+			logger.Error("Failed to compute quota status", zap.Error(err))
+			response.Error(ctx, http.StatusInternalServerError, "Failed to compute quota status", "FETCH_FAILED", err.Error())
+		}
+		return
+	}
+
+	logger.Info("Quota status retrieved successfully", zap.String("tenant_id", tenantID), zap.String("channel", channel))
+	response.Success(ctx, http.StatusOK, "Quota status retrieved successfully", statuses, nil)
+}
+
+// ConsumeQuota atomically consumes quota for a channel, answering 429 when a limit would be exceeded.
+func (c *QuotaController) ConsumeQuota(ctx *gin.Context) {
+	tenantID := ctx.Param("tenant_id")
+	var consumeDTO dto.QuotaConsumeDTO
+
+	// Validate input
+	if err := ctx.ShouldBindJSON(&consumeDTO); err != nil {
+		logger.Warn("Invalid input in ConsumeQuota", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	// Enforce the rate limits before touching the quota windows
+	decision, err := c.service.CheckRateLimit(tenantID, consumeDTO.Channel, consumeDTO.Count)
+	if err != nil {
+		if decision != nil {
+			setRateLimitHeaders(ctx, decision)
+		}
+		respondQuotaError(ctx, err, tenantID, consumeDTO.Channel)
+		return
+	}
+
+	// Call service to consume quota, refunding the rate limit tokens if it is rejected
+	consumption, err := c.service.Consume(tenantID, consumeDTO.Channel, consumeDTO.Count)
+	if err != nil {
+		c.service.RefundRateLimit(decision)
+	}
+	setRateLimitHeaders(ctx, decision)
+	if err != nil {
+		respondQuotaError(ctx, err, tenantID, consumeDTO.Channel)
+		return
+	}
+
+	logger.Info("Quota consumed successfully", zap.String("tenant_id", tenantID), zap.String("channel", consumeDTO.Channel),
+		zap.Int("count", consumeDTO.Count))
+	response.Success(ctx, http.StatusOK, "Quota consumed successfully", consumption, nil)
+}
+
+// respondQuotaError maps quota enforcement errors to HTTP responses, answering 429 for
+// exhausted rate limits and quota windows.
+func respondQuotaError(ctx *gin.Context, err error, tenantID, channel string) {
+	var rateLimitedErr *service.RateLimitedError
+	var exceededErr *service.QuotaExceededError
+	var validationErr *utils.ValidationError
+	switch {
+	case errors.As(err, &rateLimitedErr):
+		logger.Info("Rate limit exceeded", zap.String("tenant_id", tenantID), zap.String("channel", channel))
+		response.Error(ctx, http.StatusTooManyRequests, "Rate limit exceeded", "RATE_LIMITED", nil)
+	case errors.As(err, &exceededErr):
+		retryAfter := int(math.Ceil(time.Until(exceededErr.ResetAt).Seconds()))
+		ctx.Header("Retry-After", strconv.Itoa(max(retryAfter, 1)))
+		logger.Info("Quota exceeded", zap.String("tenant_id", tenantID), zap.String("channel", channel),
+			zap.String("window", exceededErr.Window))
+		response.Error(ctx, http.StatusTooManyRequests, "Quota exceeded", "QUOTA_EXCEEDED", exceededErr)
+	case errors.As(err, &validationErr):
+		logger.Warn("Invalid quota input", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+	case errors.Is(err, pkgerr.ErrNotFound):
+		logger.Warn("Quota not found", zap.String("tenant_id", tenantID), zap.String("channel", channel))
+		response.Error(ctx, http.StatusNotFound, "Quota not found", "NOT_FOUND", err.Error())
+	case errors.Is(err, pkgerr.ErrConflict):
+		logger.Warn("Quota request conflicts with current state", zap.String("tenant_id", tenantID), zap.Error(err))
+		response.Error(ctx, http.StatusConflict, "Conflict", "CONFLICT", err.Error())
+	default:
+		logger.Error("Failed to consume quota", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to consume quota", "CONSUME_FAILED", err.Error())
+	}
+}
+
+// ReserveQuota holds quota capacity for a channel until it is committed, released or expires.
+func (c *QuotaController) ReserveQuota(ctx *gin.Context) {
+	tenantID := ctx.Param("tenant_id")
+	var reservationDTO dto.QuotaReservationDTO
+
+	// Validate input
+	if err := ctx.ShouldBindJSON(&reservationDTO); err != nil {
+		logger.Warn("Invalid input in ReserveQuota", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	// Call service to reserve quota
+	ttl := time.Duration(reservationDTO.TTLSeconds) * time.Second
+	reservation, windows, err := c.service.ReserveQuota(tenantID, reservationDTO.Channel, reservationDTO.Count, ttl)
+	if err != nil {
+		respondQuotaError(ctx, err, tenantID, reservationDTO.Channel)
+		return
+	}
+
+	logger.Info("Quota reserved successfully", zap.String("tenant_id", tenantID), zap.String("reservation_id", reservation.ID))
+	response.Success(ctx, http.StatusCreated, "Quota reserved successfully", reservation, gin.H{"windows": windows})
+}
+
+// GetReservation retrieves a quota reservation.
+func (c *QuotaController) GetReservation(ctx *gin.Context) {
+	tenantID := ctx.Param("tenant_id")
+	reservationID := ctx.Param("reservation_id")
+
+	reservation, err := c.service.GetReservation(tenantID, reservationID)
+	if err != nil {
+		respondReservationError(ctx, err, tenantID, reservationID)
+		return
+	}
+
+	logger.Info("Reservation retrieved successfully", zap.String("tenant_id", tenantID), zap.String("reservation_id", reservationID))
+	response.Success(ctx, http.StatusOK, "Reservation retrieved successfully", reservation, nil)
+}
+
+// CommitReservation records the actually used part of a reservation as usage.
+func (c *QuotaController) CommitReservation(ctx *gin.Context) {
+	tenantID := ctx.Param("tenant_id")
+	reservationID := ctx.Param("reservation_id")
+	var commitDTO dto.ReservationCommitDTO
+
+	// Validate input
+	if err := ctx.ShouldBindJSON(&commitDTO); err != nil {
+		logger.Warn("Invalid input in CommitReservation", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	reservation, err := c.service.CommitReservation(tenantID, reservationID, commitDTO.Count)
+	if err != nil {
+		respondReservationError(ctx, err, tenantID, reservationID)
+		return
+	}
+
+	logger.Info("Reservation committed successfully", zap.String("tenant_id", tenantID), zap.String("reservation_id", reservationID),
+		zap.Int("count", commitDTO.Count))
+	response.Success(ctx, http.StatusOK, "Reservation committed successfully", reservation, nil)
+}
+
+// ReleaseReservation returns the remaining capacity of a reservation.
+func (c *QuotaController) ReleaseReservation(ctx *gin.Context) {
+	tenantID := ctx.Param("tenant_id")
+	reservationID := ctx.Param("reservation_id")
+
+	reservation, err := c.service.ReleaseReservation(tenantID, reservationID)
+	if err != nil {
+		respondReservationError(ctx, err, tenantID, reservationID)
+		return
+	}
+
+	logger.Info("Reservation released successfully", zap.String("tenant_id", tenantID), zap.String("reservation_id", reservationID))
+	response.Success(ctx, http.StatusOK, "Reservation released successfully", reservation, nil)
+}
+
+func respondReservationError(ctx *gin.Context, err error, tenantID, reservationID string) {
+	if errors.Is(err, pkgerr.ErrNotFound) {
+		logger.Warn("Reservation not found", zap.String("tenant_id", tenantID), zap.String("reservation_id", reservationID))
+		response.Error(ctx, http.StatusNotFound, "Reservation not found", "NOT_FOUND", err.Error())
+		return
+	}
+	respondQuotaError(ctx, err, tenantID, "")
+}
+
+// setRateLimitHeaders exposes the tightest rate limit bucket through the standard
+// RateLimit-* response headers, adding Retry-After when the request was rejected.
+func setRateLimitHeaders(ctx *gin.Context, decision *ratelimit.Decision) {
+	tightest := decision.Tightest()
+	if tightest == nil {
+		return
+	}
+
+	ctx.Header("RateLimit-Policy", decision.Policy())
+	ctx.Header("RateLimit-Limit", strconv.Itoa(tightest.Limit.Burst))
+	ctx.Header("RateLimit-Remaining", strconv.Itoa(tightest.Remaining))
+	ctx.Header("RateLimit-Reset", strconv.Itoa(int(math.Ceil(tightest.Reset.Seconds()))))
+	if !decision.Allowed {
+		ctx.Header("Retry-After", strconv.Itoa(max(int(math.Ceil(tightest.RetryAfter.Seconds())), 1)))
+	}
+}
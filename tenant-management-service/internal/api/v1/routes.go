@@ -3,30 +3,79 @@ package v1
 import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"tenant-management-service/internal/config"
+	"tenant-management-service/internal/provider"
 	"tenant-management-service/internal/repository"
 	"tenant-management-service/internal/service"
+	"tenant-management-service/pkg/broadcast"
 	"tenant-management-service/pkg/middleware"
+	"tenant-management-service/pkg/ratelimit"
+	"tenant-management-service/pkg/worker"
 )
 
-func RegisterRoutes(router *gin.Engine, db *gorm.DB) {
+func RegisterRoutes(router *gin.Engine, db *gorm.DB, appConfig *config.Config, workers *worker.Group) {
 
 	// Initialize repositories
 	tenantRepo := repository.NewTenantRepository(db)
 	configRepo := repository.NewConfigRepository(db)
+	configSchemaRepo := repository.NewConfigSchemaRepository(db)
 	quotaRepo := repository.NewQuotaRepository(db)
 	usageRepo := repository.NewUsageRepository(db)
+	changeRepo := repository.NewChangeRepository(db)
+	reservationRepo := repository.NewReservationRepository(db)
+	transactor := repository.NewTransactor(db)
+	flagRepo := repository.NewFeatureFlagRepository(db)
+	presetRepo := repository.NewConfigPresetRepository(db)
+	alertRepo := repository.NewAlertRepository(db)
+	channelRepo := repository.NewChannelRepository(db)
+	planRepo := repository.NewPlanRepository(db)
+	invoiceRepo := repository.NewInvoiceRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	deviceRepo := repository.NewDeviceRepository(db)
+
+	// Initialize channel providers
+	providers := provider.NewRegistry()
+	providers.Register("email", provider.NewSMTPProvider())
+	providers.Register("sms", provider.NewTwilioProvider(appConfig.Providers.Twilio.BaseURL))
+	providers.Register("webhook", provider.NewWebhookProvider(webhookRepo, appConfig.Providers.Webhook.Timeout))
+	providers.Register("push", provider.NewPushProvider(deviceRepo,
+		provider.NewFCMSender(appConfig.Providers.FCM.BaseURL, appConfig.Providers.FCM.TokenURL),
+		provider.NewAPNsSender(appConfig.Providers.APNs.BaseURL, appConfig.Providers.APNs.SandboxBaseURL)))
 
 	// Initialize services
+	changeService := service.NewChangeService(changeRepo, transactor, broadcast.NewBroadcaster())
 	tenantService := service.NewTenantService(tenantRepo)
-	configService := service.NewConfigService(configRepo)
-	quotaService := service.NewQuotaService(quotaRepo)
-	usageService := service.NewUsageService(usageRepo)
+	channelService := service.NewChannelService(channelRepo, tenantRepo)
+	configService := service.NewConfigService(configRepo, configSchemaRepo, channelService, changeService)
+	alertService := service.NewAlertService(alertRepo, tenantRepo, configRepo, transactor, appConfig.Alerts)
+	quotaService := service.NewQuotaService(quotaRepo, reservationRepo, transactor, channelService,
+		ratelimit.NewLimiter(ratelimit.NewMemoryStore()), changeService, alertService, appConfig.Quota)
+	usageService := service.NewUsageService(usageRepo, tenantRepo, channelService, alertService, appConfig.Usage)
+	flagService := service.NewFeatureFlagService(flagRepo, configRepo)
+	presetService := service.NewConfigPresetService(presetRepo, tenantRepo, configRepo, configService)
+	forecastService := service.NewForecastService(transactor, channelService)
+	billingService := service.NewBillingService(planRepo, invoiceRepo, usageRepo, tenantRepo, channelService, appConfig.Billing)
+	notificationService := service.NewNotificationService(notificationRepo, tenantRepo, configRepo, channelService, quotaService, usageService,
+		providers, appConfig.Notifications)
+	webhookService := service.NewWebhookService(webhookRepo)
+	deviceService := service.NewDeviceService(deviceRepo)
 
 	// Initialize controllers
 	tenantController := NewTenantController(tenantService)
 	configController := NewConfigController(configService)
 	quotaController := NewQuotaController(quotaService)
 	usageController := NewUsageController(usageService)
+	changeController := NewChangeController(changeService)
+	flagController := NewFeatureFlagController(flagService)
+	presetController := NewConfigPresetController(presetService)
+	alertController := NewAlertController(alertService)
+	channelController := NewChannelController(channelService)
+	billingController := NewBillingController(billingService)
+	forecastController := NewForecastController(forecastService)
+	notificationController := NewNotificationController(notificationService)
+	webhookController := NewWebhookController(webhookService)
+	deviceController := NewDeviceController(deviceService)
 
 	// Define routes
 	api := router.Group("/api/v1")
@@ -34,24 +83,114 @@ func RegisterRoutes(router *gin.Engine, db *gorm.DB) {
 	// Public Route
 	api.POST("/tenants", tenantController.Create)
 
+	// Admin Routes
+	admin := api.Group("/admin", middleware.AdminMiddleware(appConfig.Admin.APIKey))
+	{
+		// Change Stream Routes
+		admin.GET("/changes", changeController.WatchAll)
+		admin.GET("/changes/stream", changeController.StreamAll)
+
+		// Channel Catalog Routes
+		admin.PUT("/channels", channelController.UpsertChannel)
+		admin.GET("/channels", channelController.GetChannels)
+
+		// Billing Plan Routes
+		admin.PUT("/plans", billingController.UpsertPlan)
+		admin.GET("/plans", billingController.GetPlans)
+
+		// Feature Flag Management Routes
+		admin.PUT("/flags", flagController.UpsertFlag)
+		admin.GET("/flags", flagController.GetFlags)
+		admin.DELETE("/flags/:key", flagController.DeleteFlag)
+
+		// Configuration Schema Routes
+		admin.PUT("/config-schemas/:key", configController.UpsertSchema)
+		admin.GET("/config-schemas", configController.GetSchemas)
+
+		// Configuration Preset Routes
+		admin.PUT("/config-presets", presetController.UpsertPreset)
+		admin.GET("/config-presets", presetController.GetPresets)
+		admin.DELETE("/config-presets/:name", presetController.DeletePreset)
+		admin.POST("/config-presets/:name/preview", presetController.PreviewPreset)
+		admin.POST("/config-presets/:name/apply", presetController.ApplyPreset)
+	}
+
 	// Protected Routes
-	protected := api.Use(middleware.AuthMiddleware(tenantService))
+	protected := api.Group("", middleware.AuthMiddleware(tenantService))
 	{
-		// Tenant Management Routes
-		protected.GET("/tenants/:tenant_id", tenantController.Get)
-		protected.PUT("/tenants/:tenant_id", tenantController.Update)
-		protected.DELETE("/tenants/:tenant_id", tenantController.Delete)
+		// Tenant-Scoped Routes, restricted to the authenticated tenant
+		tenant := protected.Group("/tenants/:tenant_id", middleware.TenantOwnershipMiddleware())
+		{
+			// Tenant Management Routes
+			tenant.GET("", tenantController.Get)
+			tenant.PUT("", tenantController.Update)
+			tenant.DELETE("", tenantController.Delete)
 
-		// Configuration Management Routes
-		protected.PUT("/tenants/:tenant_id/configs", configController.UpsertConfig)
-		protected.GET("/tenants/:tenant_id/configs", configController.GetConfigs)
+			// Configuration Management Routes
+			tenant.PUT("/configs", configController.UpsertConfig)
+			tenant.GET("/configs", configController.GetConfigs)
+			tenant.GET("/configs/:key/json", configController.GetJSONConfig)
+			tenant.PUT("/configs/:key/json", configController.PutJSONConfig)
+			tenant.PATCH("/configs/:key/json", configController.PatchJSONConfig)
 
-		// Quota Management Routes
-		protected.PUT("/tenants/:tenant_id/quotas", quotaController.UpdateQuota)
-		protected.GET("/tenants/:tenant_id/quotas", quotaController.GetQuotas)
+			// Quota Management Routes
+			tenant.PUT("/quotas", quotaController.UpdateQuota)
+			tenant.GET("/quotas", quotaController.GetQuotas)
+			tenant.GET("/quotas/status", quotaController.GetQuotaStatus)
+			tenant.POST("/quotas/consume", quotaController.ConsumeQuota)
+			tenant.POST("/quotas/reservations", quotaController.ReserveQuota)
+			tenant.GET("/quotas/reservations/:reservation_id", quotaController.GetReservation)
+			tenant.POST("/quotas/reservations/:reservation_id/commit", quotaController.CommitReservation)
+			tenant.POST("/quotas/reservations/:reservation_id/release", quotaController.ReleaseReservation)
+			tenant.GET("/quotas/alerts", alertController.GetAlerts)
 
-		// Usage Management Routes
-		protected.GET("/tenants/:tenant_id/usage", usageController.GetUsage)
+			// Usage Management Routes
+			tenant.GET("/usage", usageController.GetUsage)
+			tenant.POST("/usage/events", usageController.RecordEvents)
+			tenant.GET("/usage/export", usageController.ExportUsage)
+			tenant.GET("/usage/forecast", forecastController.GetForecast)
+
+			// Invoice Routes
+			tenant.GET("/invoices", billingController.GetInvoices)
+			tenant.GET("/invoices/:invoice_id", billingController.GetInvoice)
+
+			// Change Stream Routes
+			tenant.GET("/changes", changeController.Watch)
+			tenant.GET("/changes/stream", changeController.Stream)
+		}
+
+		// Notification Routes
+		protected.POST("/notifications", notificationController.Send)
+		protected.GET("/notifications/:notification_id", notificationController.GetNotification)
+		protected.GET("/notifications/:notification_id/attempts", notificationController.GetAttempts)
+
+		// Webhook Endpoint Routes
+		protected.PUT("/webhooks", webhookController.UpsertEndpoint)
+		protected.GET("/webhooks", webhookController.GetEndpoints)
+		protected.POST("/webhooks/:name/secret", webhookController.RotateSecret)
+		protected.DELETE("/webhooks/:name", webhookController.DeleteEndpoint)
+
+		// Push Device Routes
+		protected.PUT("/devices", deviceController.RegisterDevice)
+		protected.GET("/devices", deviceController.GetDevices)
+		protected.DELETE("/devices/:device_id", deviceController.DeleteDevice)
+
+		// Feature Flag Routes
+		protected.GET("/flags/evaluate", flagController.Evaluate)
+
+		// Channel Catalog Routes
+		protected.GET("/channels", channelController.GetAvailableChannels)
+		protected.GET("/channels/:code/health", notificationController.CheckProviderHealth)
 	}
 
+	// Start background workers
+	workers.Every("reservation-sweeper", appConfig.Quota.ReservationSweepInterval, quotaService.ExpireReservations)
+	workers.Go("alert-dispatcher", alertService.Run)
+	workers.Every("alert-sweeper", appConfig.Alerts.SweepInterval, alertService.Sweep)
+	workers.Go("usage-flusher", usageService.RunFlusher)
+	workers.Every("usage-rollup", appConfig.Usage.RollupInterval, usageService.RollupUsage)
+	workers.Every("invoice-issuer", appConfig.Billing.InvoiceInterval, billingService.IssueDueInvoices)
+	workers.Go("notification-dispatcher", notificationService.Run)
+	workers.Every("notification-sweeper", appConfig.Notifications.SweepInterval, notificationService.Sweep)
+
 }
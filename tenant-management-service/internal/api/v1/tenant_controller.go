@@ -21,11 +21,13 @@ func NewTenantController(service *service.TenantService) *TenantController {
 // Create handles the creation of a new tenant.
 func (c *TenantController) Create(ctx *gin.Context) {
 	var req struct {
-		Name            string `json:"name" binding:"required"`
-		Email           string `json:"email" binding:"required,email"`
-		Phone           string `json:"phone" binding:"required"`
-		BillingTier     string `json:"billing_tier" binding:"required"`
-		DefaultLanguage string `json:"default_language" binding:"required"`
+		Name               string `json:"name" binding:"required"`
+		Email              string `json:"email" binding:"required,email"`
+		Phone              string `json:"phone" binding:"required"`
+		BillingTier        string `json:"billing_tier" binding:"required"`
+		DefaultLanguage    string `json:"default_language" binding:"required"`
+		Timezone           string `json:"timezone"`
+		BillingCycleAnchor int    `json:"billing_cycle_anchor"`
 	}
 
 	// Bind the JSON request body to the struct
@@ -36,7 +38,8 @@ func (c *TenantController) Create(ctx *gin.Context) {
 	}
 
 	// Call the service to create the tenant
-	tenant, err := c.service.CreateTenant(req.Name, req.Email, req.Phone, req.BillingTier, req.DefaultLanguage)
+	tenant, err := c.service.CreateTenant(req.Name, req.Email, req.Phone, req.BillingTier, req.DefaultLanguage,
+		req.Timezone, req.BillingCycleAnchor)
 	if err != nil {
 		logger.Error("Failed to create tenant", zap.Error(err))
 		response.Error(ctx, http.StatusInternalServerError, "Failed to create tenant", "CREATE_FAILED", err.Error())
@@ -80,11 +83,13 @@ func (c *TenantController) Update(ctx *gin.Context) {
 	}
 
 	var req struct {
-		Name            string `json:"name"`
-		Email           string `json:"email" binding:"email"`
-		Phone           string `json:"phone"`
-		BillingTier     string `json:"billing_tier"`
-		DefaultLanguage string `json:"default_language"`
+		Name               string `json:"name"`
+		Email              string `json:"email" binding:"email"`
+		Phone              string `json:"phone"`
+		BillingTier        string `json:"billing_tier"`
+		DefaultLanguage    string `json:"default_language"`
+		Timezone           string `json:"timezone"`
+		BillingCycleAnchor int    `json:"billing_cycle_anchor"`
 	}
 
 	// Bind the JSON request body to the struct
@@ -95,7 +100,8 @@ func (c *TenantController) Update(ctx *gin.Context) {
 	}
 
 	// Call the service to update the tenant
-	err = c.service.UpdateTenant(uint(id), req.Name, req.Email, req.Phone, req.BillingTier, req.DefaultLanguage)
+	err = c.service.UpdateTenant(uint(id), req.Name, req.Email, req.Phone, req.BillingTier, req.DefaultLanguage,
+		req.Timezone, req.BillingCycleAnchor)
 	if err != nil {
 		logger.Error("Failed to update tenant", zap.Int("tenant_id", id), zap.Error(err))
 		response.Error(ctx, http.StatusInternalServerError, "Failed to update tenant", "UPDATE_FAILED", err.Error())
@@ -1,12 +1,36 @@
 package v1
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"tenant-management-service/internal/model"
+	"tenant-management-service/internal/model/dto"
 	"tenant-management-service/internal/response"
 	"tenant-management-service/internal/service"
+	pkgerr "tenant-management-service/pkg/error"
 	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/utils"
+	"time"
+)
+
+const (
+	defaultUsageLimit = 100
+	maxUsageLimit     = 1000
+	// maxUsageEventsBody bounds the size of a usage event request body.
+	maxUsageEventsBody = 1 << 20
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+	// exportFlushRows is how many exported rows are buffered before they are sent.
+	exportFlushRows = 500
 )
 
 type UsageController struct {
@@ -17,19 +41,249 @@ func NewUsageController(service *service.UsageService) *UsageController {
 	return &UsageController{service: service}
 }
 
-// GetUsage retrieves usage data for a tenant.
+// GetUsage retrieves usage of a tenant aggregated per period and grouping, with totals
+// per outcome and the delivery and failure rates over the whole range in the response meta.
 func (c *UsageController) GetUsage(ctx *gin.Context) {
 	tenantID := ctx.Param("tenant_id")
-	channel := ctx.Query("channel") // Optional query parameter to filter by channel
+
+	// Validate input
+	query, err := parseUsageQuery(ctx, tenantID)
+	if err != nil {
+		logger.Warn("Invalid input in GetUsage", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+		return
+	}
+	query.Limit, err = parseBoundedInt(ctx.Query("limit"), defaultUsageLimit, maxUsageLimit)
+	if err != nil {
+		logger.Warn("Invalid limit in GetUsage", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid limit", "INVALID_INPUT", err.Error())
+		return
+	}
+	query.Offset, err = parseOffset(ctx.Query("offset"))
+	if err != nil {
+		logger.Warn("Invalid offset in GetUsage", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid offset", "INVALID_INPUT", err.Error())
+		return
+	}
 
 	// Fetch usage data
-	usage, err := c.service.GetUsage(tenantID, channel)
+	points, totals, total, err := c.service.GetUsage(query)
 	if err != nil {
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			logger.Warn("Invalid input in GetUsage", zap.Error(err))
+			response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+			return
+		}
 		logger.Error("Failed to fetch usage data", zap.Error(err))
 		response.Error(ctx, http.StatusInternalServerError, "Failed to fetch usage data", "FETCH_FAILED", err.Error())
 		return
 	}
 
-	logger.Info("Usage data retrieved successfully", zap.String("tenant_id", tenantID), zap.String("channel", channel))
-	response.Success(ctx, http.StatusOK, "Usage data retrieved successfully", usage, nil)
+	logger.Info("Usage data retrieved successfully", zap.String("tenant_id", tenantID), zap.String("channel", query.Channel))
+	response.Success(ctx, http.StatusOK, "Usage data retrieved successfully", points, gin.H{
+		"totals":      totals,
+		"rates":       model.NewUsageRates(totals),
+		"total":       total,
+		"limit":       query.Limit,
+		"offset":      query.Offset,
+		"granularity": query.Granularity,
+	})
+}
+
+// ExportUsage streams usage of a tenant as CSV or NDJSON, selected by the format query
+// parameter, using the filters of GetUsage without pagination. Errors after the first
+// row has been written can only be logged and end the export early.
+func (c *UsageController) ExportUsage(ctx *gin.Context) {
+	tenantID := ctx.Param("tenant_id")
+
+	// Validate input
+	format := ctx.DefaultQuery("format", exportFormatCSV)
+	if format != exportFormatCSV && format != exportFormatNDJSON {
+		logger.Warn("Invalid format in ExportUsage", zap.String("format", format))
+		response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT",
+			fmt.Sprintf("format must be %s or %s", exportFormatCSV, exportFormatNDJSON))
+		return
+	}
+	query, err := parseUsageQuery(ctx, tenantID)
+	if err == nil {
+		err = c.service.ValidateUsageQuery(&query)
+	}
+	if err != nil {
+		logger.Warn("Invalid input in ExportUsage", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	filename := fmt.Sprintf("usage-%s-%s.%s", tenantID, time.Now().UTC().Format("20060102"), format)
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	var write func(point model.UsagePoint) error
+	var flush func() error
+	rows := 0
+	if format == exportFormatCSV {
+		ctx.Header("Content-Type", "text/csv; charset=utf-8")
+		writer := csv.NewWriter(ctx.Writer)
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+		if err := writer.Write([]string{"period_start", "channel", "outcome", "count", "delivery_rate", "failure_rate"}); err != nil {
+			logger.Warn("Usage export aborted", zap.String("tenant_id", tenantID), zap.Error(err))
+			return
+		}
+		write = func(point model.UsagePoint) error {
+			return writer.Write([]string{
+				point.PeriodStart.Format(time.DateOnly), point.Channel, point.Outcome, strconv.Itoa(point.Count),
+				formatRate(point.DeliveryRate), formatRate(point.FailureRate),
+			})
+		}
+	} else {
+		ctx.Header("Content-Type", "application/x-ndjson")
+		encoder := json.NewEncoder(ctx.Writer)
+		flush = func() error { return nil }
+		write = func(point model.UsagePoint) error {
+			return encoder.Encode(point)
+		}
+	}
+
+	// Stream rows as they are read, pushing them to the client in chunks
+	ctx.Status(http.StatusOK)
+	err = c.service.ExportUsage(query, func(point model.UsagePoint) error {
+		if err := write(point); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushRows == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			ctx.Writer.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		if !ctx.Writer.Written() {
+			ctx.Writer.Header().Del("Content-Disposition")
+			logger.Error("Failed to export usage data", zap.Error(err))
+			response.Error(ctx, http.StatusInternalServerError, "Failed to export usage data", "EXPORT_FAILED", err.Error())
+			return
+		}
+		logger.Warn("Usage export aborted", zap.String("tenant_id", tenantID), zap.Int("rows", rows), zap.Error(err))
+		return
+	}
+
+	logger.Info("Usage exported successfully", zap.String("tenant_id", tenantID), zap.String("format", format), zap.Int("rows", rows))
+}
+
+// formatRate formats an optional rate for CSV, leaving it empty when there is none.
+func formatRate(rate *float64) string {
+	if rate == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*rate, 'f', 4, 64)
+}
+
+// parseUsageQuery reads the usage filters shared by usage queries and exports: the channel,
+// from and to dates (YYYY-MM-DD), granularity and a comma-separated group_by list, which
+// defaults to grouping by channel.
+func parseUsageQuery(ctx *gin.Context, tenantID string) (model.UsageQuery, error) {
+	query := model.UsageQuery{
+		TenantID:    tenantID,
+		Channel:     ctx.Query("channel"),
+		Granularity: ctx.Query("granularity"),
+	}
+
+	for _, param := range []struct {
+		name string
+		dest **time.Time
+	}{{"from", &query.From}, {"to", &query.To}} {
+		value := ctx.Query(param.name)
+		if value == "" {
+			continue
+		}
+		date, err := time.Parse(time.DateOnly, value)
+		if err != nil {
+			return query, fmt.Errorf("%s must be a date in the form YYYY-MM-DD", param.name)
+		}
+		*param.dest = &date
+	}
+
+	groupBy, ok := ctx.GetQuery("group_by")
+	if !ok {
+		groupBy = model.UsageGroupByChannel
+	}
+	for _, group := range strings.Split(groupBy, ",") {
+		switch strings.TrimSpace(group) {
+		case "":
+		case model.UsageGroupByChannel:
+			query.GroupByChannel = true
+		case model.UsageGroupByOutcome:
+			query.GroupByOutcome = true
+		default:
+			return query, fmt.Errorf("group_by must only contain %s", strings.Join(model.UsageGroupings, ", "))
+		}
+	}
+	return query, nil
+}
+
+// RecordEvents accepts a single usage event or a batch of them as a JSON array. Accepted
+// events are counted asynchronously and show up in usage data after the next flush.
+func (c *UsageController) RecordEvents(ctx *gin.Context) {
+	tenantID := ctx.Param("tenant_id")
+
+	// Validate input
+	events, err := bindUsageEvents(ctx)
+	if err != nil {
+		logger.Warn("Invalid input in RecordEvents", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	// Call service to record the events
+	total, err := c.service.RecordEvents(tenantID, events)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		switch {
+		case errors.As(err, &validationErr):
+			logger.Warn("Invalid usage event in RecordEvents", zap.Error(err))
+			response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+		case errors.Is(err, pkgerr.ErrNotFound):
+			logger.Warn("Tenant not found in RecordEvents", zap.String("tenant_id", tenantID))
+			response.Error(ctx, http.StatusNotFound, "Tenant not found", "NOT_FOUND", err.Error())
+		default:
+			logger.Error("Failed to record usage events", zap.Error(err))
+			response.Error(ctx, http.StatusInternalServerError, "Failed to record usage events", "RECORD_FAILED", err.Error())
+		}
+		return
+	}
+
+	logger.Info("Usage events accepted", zap.String("tenant_id", tenantID), zap.Int("events", len(events)))
+	response.Success(ctx, http.StatusAccepted, "Usage events accepted", gin.H{"events": len(events), "count": total}, nil)
+}
+
+// bindUsageEvents decodes a request body holding either one usage event or an array of them.
+func bindUsageEvents(ctx *gin.Context) ([]dto.UsageEventDTO, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxUsageEventsBody))
+	if err != nil {
+		return nil, err
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var events []dto.UsageEventDTO
+		if err := json.Unmarshal(body, &events); err != nil {
+			return nil, err
+		}
+		return events, nil
+	}
+
+	var event dto.UsageEventDTO
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, err
+	}
+	return []dto.UsageEventDTO{event}, nil
 }
@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"tenant-management-service/internal/model/dto"
+	"tenant-management-service/internal/response"
+	"tenant-management-service/internal/service"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/middleware"
+	"tenant-management-service/pkg/utils"
+)
+
+type WebhookController struct {
+	service *service.WebhookService
+}
+
+func NewWebhookController(service *service.WebhookService) *WebhookController {
+	return &WebhookController{service: service}
+}
+
+// UpsertEndpoint handles registering or updating a webhook endpoint of the calling tenant.
+// The signing secret is included in the response only when the endpoint is created.
+func (c *WebhookController) UpsertEndpoint(ctx *gin.Context) {
+	tenantID := strconv.FormatUint(uint64(middleware.CurrentTenant(ctx).ID), 10)
+	var endpointDTO dto.WebhookEndpointDTO
+
+	// Validate input
+	if err := ctx.ShouldBindJSON(&endpointDTO); err != nil {
+		logger.Warn("Invalid input in UpsertEndpoint", zap.Error(err))
+		response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+		return
+	}
+
+	// Call service to upsert the endpoint
+	endpoint, err := c.service.UpsertEndpoint(tenantID, endpointDTO)
+	if err != nil {
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			logger.Warn("Invalid webhook endpoint in UpsertEndpoint", zap.Error(err))
+			response.Error(ctx, http.StatusBadRequest, "Invalid input", "INVALID_INPUT", err.Error())
+			return
+		}
+		logger.Error("Failed to upsert webhook endpoint", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to upsert webhook endpoint", "UPSERT_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Webhook endpoint upserted successfully", zap.String("tenant_id", tenantID), zap.String("name", endpoint.Name))
+	response.Success(ctx, http.StatusOK, "Webhook endpoint upserted successfully", endpoint, nil)
+}
+
+// GetEndpoints retrieves the webhook endpoints of the calling tenant.
+func (c *WebhookController) GetEndpoints(ctx *gin.Context) {
+	tenantID := strconv.FormatUint(uint64(middleware.CurrentTenant(ctx).ID), 10)
+
+	endpoints, err := c.service.GetEndpoints(tenantID)
+	if err != nil {
+		logger.Error("Failed to fetch webhook endpoints", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to fetch webhook endpoints", "FETCH_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Webhook endpoints retrieved successfully", zap.String("tenant_id", tenantID))
+	response.Success(ctx, http.StatusOK, "Webhook endpoints retrieved successfully", endpoints, nil)
+}
+
+// RotateSecret replaces the signing secret of a webhook endpoint of the calling tenant.
+func (c *WebhookController) RotateSecret(ctx *gin.Context) {
+	tenantID := strconv.FormatUint(uint64(middleware.CurrentTenant(ctx).ID), 10)
+	name := ctx.Param("name")
+
+	endpoint, err := c.service.RotateSecret(tenantID, name)
+	if err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			logger.Warn("Webhook endpoint not found", zap.String("tenant_id", tenantID), zap.String("name", name))
+			response.Error(ctx, http.StatusNotFound, "Webhook endpoint not found", "NOT_FOUND", err.Error())
+			return
+		}
+		logger.Error("Failed to rotate webhook secret", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to rotate webhook secret", "UPDATE_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Webhook secret rotated successfully", zap.String("tenant_id", tenantID), zap.String("name", name))
+	response.Success(ctx, http.StatusOK, "Webhook secret rotated successfully", endpoint, nil)
+}
+
+// DeleteEndpoint removes a webhook endpoint of the calling tenant.
+func (c *WebhookController) DeleteEndpoint(ctx *gin.Context) {
+	tenantID := strconv.FormatUint(uint64(middleware.CurrentTenant(ctx).ID), 10)
+	name := ctx.Param("name")
+
+	if err := c.service.DeleteEndpoint(tenantID, name); err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			logger.Warn("Webhook endpoint not found", zap.String("tenant_id", tenantID), zap.String("name", name))
+			response.Error(ctx, http.StatusNotFound, "Webhook endpoint not found", "NOT_FOUND", err.Error())
+			return
+		}
+		logger.Error("Failed to delete webhook endpoint", zap.Error(err))
+		response.Error(ctx, http.StatusInternalServerError, "Failed to delete webhook endpoint", "DELETE_FAILED", err.Error())
+		return
+	}
+
+	logger.Info("Webhook endpoint deleted successfully", zap.String("tenant_id", tenantID), zap.String("name", name))
+	response.Success(ctx, http.StatusOK, "Webhook endpoint deleted successfully", nil, nil)
+}
@@ -4,23 +4,111 @@ import (
 	"fmt"
 	"gopkg.in/yaml.v3"
 	"os"
+	"time"
 )
 
 type Config struct {
-	Server   ServerConfig   `yaml:"server"`
-	Database DatabaseConfig `yaml:"database"`
+	Server        ServerConfig       `yaml:"server"`
+	Database      DatabaseConfig     `yaml:"database"`
+	Admin         AdminConfig        `yaml:"admin"`
+	Quota         QuotaConfig        `yaml:"quota"`
+	Alerts        AlertConfig        `yaml:"alerts"`
+	Usage         UsageConfig        `yaml:"usage"`
+	Billing       BillingConfig      `yaml:"billing"`
+	Notifications NotificationConfig `yaml:"notifications"`
+	Providers     ProvidersConfig    `yaml:"providers"`
 }
 
 type ServerConfig struct {
-	Port string `yaml:"port"`
+	Port            string        `yaml:"port"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
 }
 
+// DatabaseConfig holds the connection settings of the database. LegacyTimezone is the
+// timezone the service ran in before it stored timestamps in UTC, the local timezone of
+// the server if empty. Databases holding data from before then are converted once on
+// startup; "UTC" skips the conversion for data that is already in UTC.
 type DatabaseConfig struct {
+	Host           string `yaml:"host"`
+	Port           int    `yaml:"port"`
+	User           string `yaml:"user"`
+	Password       string `yaml:"password"`
+	DBName         string `yaml:"dbname"`
+	LegacyTimezone string `yaml:"legacy_timezone"`
+}
+
+type AdminConfig struct {
+	APIKey string `yaml:"api_key"`
+}
+
+type QuotaConfig struct {
+	ReservationDefaultTTL    time.Duration `yaml:"reservation_default_ttl"`
+	ReservationMaxTTL        time.Duration `yaml:"reservation_max_ttl"`
+	ReservationSweepInterval time.Duration `yaml:"reservation_sweep_interval"`
+}
+
+type UsageConfig struct {
+	FlushInterval   time.Duration `yaml:"flush_interval"`
+	CounterShards   int           `yaml:"counter_shards"`
+	RollupInterval  time.Duration `yaml:"rollup_interval"`
+	RollupAfter     time.Duration `yaml:"rollup_after"`
+	BucketRetention time.Duration `yaml:"bucket_retention"`
+}
+
+type BillingConfig struct {
+	InvoiceInterval time.Duration `yaml:"invoice_interval"`
+	InvoiceDelay    time.Duration `yaml:"invoice_delay"`
+}
+
+type NotificationConfig struct {
+	QueueSize     int           `yaml:"queue_size"`
+	Workers       int           `yaml:"workers"`
+	MaxAttempts   int           `yaml:"max_attempts"`
+	RetryBackoff  time.Duration `yaml:"retry_backoff"`
+	SendTimeout   time.Duration `yaml:"send_timeout"`
+	SweepInterval time.Duration `yaml:"sweep_interval"`
+}
+
+type ProvidersConfig struct {
+	Twilio  TwilioConfig  `yaml:"twilio"`
+	Webhook WebhookConfig `yaml:"webhook"`
+	FCM     FCMConfig     `yaml:"fcm"`
+	APNs    APNsConfig    `yaml:"apns"`
+}
+
+type TwilioConfig struct {
+	BaseURL string `yaml:"base_url"`
+}
+
+type WebhookConfig struct {
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+type FCMConfig struct {
+	BaseURL  string `yaml:"base_url"`
+	TokenURL string `yaml:"token_url"`
+}
+
+type APNsConfig struct {
+	BaseURL        string `yaml:"base_url"`
+	SandboxBaseURL string `yaml:"sandbox_base_url"`
+}
+
+type AlertConfig struct {
+	QueueSize      int           `yaml:"queue_size"`
+	WebhookTimeout time.Duration `yaml:"webhook_timeout"`
+	SendTimeout    time.Duration `yaml:"send_timeout"`
+	SweepInterval  time.Duration `yaml:"sweep_interval"`
+	SMTP           SMTPConfig    `yaml:"smtp"`
+}
+
+type SMTPConfig struct {
 	Host     string `yaml:"host"`
 	Port     int    `yaml:"port"`
-	User     string `yaml:"user"`
+	Username string `yaml:"username"`
 	Password string `yaml:"password"`
-	DBName   string `yaml:"dbname"`
+	From     string `yaml:"from"`
+	TLS      string `yaml:"tls"`
 }
 
 func LoadConfig(path string) (*Config, error) {
@@ -41,5 +129,94 @@ func LoadConfig(path string) (*Config, error) {
 	if err := yaml.NewDecoder(file).Decode(config); err != nil {
 		return nil, err
 	}
+	config.applyDefaults()
 	return config, nil
 }
+
+// applyDefaults fills in settings left out of the configuration file.
+func (c *Config) applyDefaults() {
+	if c.Server.ShutdownTimeout == 0 {
+		c.Server.ShutdownTimeout = 10 * time.Second
+	}
+	if c.Quota.ReservationDefaultTTL == 0 {
+		c.Quota.ReservationDefaultTTL = 15 * time.Minute
+	}
+	if c.Quota.ReservationMaxTTL == 0 {
+		c.Quota.ReservationMaxTTL = 24 * time.Hour
+	}
+	if c.Quota.ReservationSweepInterval == 0 {
+		c.Quota.ReservationSweepInterval = time.Minute
+	}
+	if c.Usage.FlushInterval == 0 {
+		c.Usage.FlushInterval = time.Second
+	}
+	if c.Usage.CounterShards == 0 {
+		c.Usage.CounterShards = 32
+	}
+	if c.Billing.InvoiceInterval == 0 {
+		c.Billing.InvoiceInterval = time.Hour
+	}
+	if c.Billing.InvoiceDelay == 0 {
+		c.Billing.InvoiceDelay = time.Hour
+	}
+	if c.Usage.RollupInterval == 0 {
+		c.Usage.RollupInterval = time.Hour
+	}
+	if c.Usage.RollupAfter == 0 {
+		c.Usage.RollupAfter = 90 * 24 * time.Hour
+	}
+	if c.Usage.BucketRetention == 0 {
+		c.Usage.BucketRetention = 14 * 24 * time.Hour
+	}
+	if c.Notifications.QueueSize == 0 {
+		c.Notifications.QueueSize = 1000
+	}
+	if c.Notifications.Workers == 0 {
+		c.Notifications.Workers = 4
+	}
+	if c.Notifications.MaxAttempts == 0 {
+		c.Notifications.MaxAttempts = 5
+	}
+	if c.Notifications.RetryBackoff == 0 {
+		c.Notifications.RetryBackoff = 10 * time.Second
+	}
+	if c.Notifications.SendTimeout == 0 {
+		c.Notifications.SendTimeout = 30 * time.Second
+	}
+	if c.Notifications.SweepInterval == 0 {
+		c.Notifications.SweepInterval = 15 * time.Second
+	}
+	if c.Providers.Twilio.BaseURL == "" {
+		c.Providers.Twilio.BaseURL = "https://api.twilio.com"
+	}
+	if c.Providers.Webhook.Timeout == 0 {
+		c.Providers.Webhook.Timeout = 10 * time.Second
+	}
+	if c.Providers.FCM.BaseURL == "" {
+		c.Providers.FCM.BaseURL = "https://fcm.googleapis.com"
+	}
+	if c.Providers.FCM.TokenURL == "" {
+		c.Providers.FCM.TokenURL = "https://oauth2.googleapis.com/token"
+	}
+	if c.Providers.APNs.BaseURL == "" {
+		c.Providers.APNs.BaseURL = "https://api.push.apple.com"
+	}
+	if c.Providers.APNs.SandboxBaseURL == "" {
+		c.Providers.APNs.SandboxBaseURL = "https://api.sandbox.push.apple.com"
+	}
+	if c.Alerts.QueueSize == 0 {
+		c.Alerts.QueueSize = 1000
+	}
+	if c.Alerts.WebhookTimeout == 0 {
+		c.Alerts.WebhookTimeout = 5 * time.Second
+	}
+	if c.Alerts.SendTimeout == 0 {
+		c.Alerts.SendTimeout = 30 * time.Second
+	}
+	if c.Alerts.SweepInterval == 0 {
+		c.Alerts.SweepInterval = 15 * time.Second
+	}
+	if c.Alerts.SMTP.Port == 0 {
+		c.Alerts.SMTP.Port = 587
+	}
+}
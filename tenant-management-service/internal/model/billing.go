@@ -0,0 +1,133 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"time"
+)
+
+// Plan prices the billing tier it is named after. Amounts are in minor units of Currency,
+// e.g. cents. Notifications within quota are charged at the unit price of their channel
+// and billable overage through the channel's overage tiers.
+type Plan struct {
+	ID            uint               `gorm:"primaryKey" json:"id"`
+	BillingTier   string             `gorm:"size:50;uniqueIndex;not null" json:"billing_tier"`
+	Currency      string             `gorm:"size:3;not null" json:"currency"`
+	BasePrice     int64              `gorm:"not null" json:"base_price"`
+	ChannelPrices []PlanChannelPrice `gorm:"foreignKey:PlanID;constraint:OnDelete:CASCADE" json:"channel_prices"`
+	CreatedAt     time.Time          `json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+// PlanChannelPrice is the price of a channel on a plan, per thousand units so that
+// fractions of a minor unit per notification can be expressed.
+type PlanChannelPrice struct {
+	ID               uint              `gorm:"primaryKey" json:"-"`
+	PlanID           uint              `gorm:"not null;uniqueIndex:idx_plan_channel" json:"-"`
+	Channel          string            `gorm:"size:50;not null;uniqueIndex:idx_plan_channel" json:"channel"`
+	PricePerThousand int64             `gorm:"not null" json:"price_per_thousand"`
+	OverageTiers     []PlanOverageTier `gorm:"foreignKey:ChannelPriceID;constraint:OnDelete:CASCADE" json:"overage_tiers"`
+}
+
+// PlanOverageTier prices billable overage graduated by volume: the tier applies to the
+// overage units above the previous tier up to UpTo, or without bound if UpTo is 0. Only
+// the last tier may be unbounded; overage beyond a bounded last tier is priced like it.
+// Without tiers, overage is charged at the channel's unit price.
+type PlanOverageTier struct {
+	ID               uint  `gorm:"primaryKey" json:"-"`
+	ChannelPriceID   uint  `gorm:"not null;index" json:"-"`
+	UpTo             int   `gorm:"not null" json:"up_to"`
+	PricePerThousand int64 `gorm:"not null" json:"price_per_thousand"`
+}
+
+// Invoice line item kinds.
+const (
+	InvoiceLineBase    = "base"
+	InvoiceLineUsage   = "usage"
+	InvoiceLineOverage = "overage"
+)
+
+// ErrInvoiceImmutable is returned when an issued invoice is about to be changed.
+var ErrInvoiceImmutable = errors.New("invoices are immutable once issued")
+
+// Invoice bills a tenant for one billing period, from PeriodStart up to but excluding
+// PeriodEnd. Like Usage.Date, both are calendar days of the tenant's timezone. Line items
+// copy the plan prices in effect when the invoice was issued, so later plan changes do not
+// alter it. Invoices are never updated.
+type Invoice struct {
+	ID          uint              `gorm:"primaryKey" json:"id"`
+	Number      string            `gorm:"size:100;uniqueIndex;not null" json:"number"`
+	TenantID    string            `gorm:"size:255;not null;uniqueIndex:idx_invoice_period,priority:1" json:"tenant_id"`
+	PeriodStart time.Time         `gorm:"not null;uniqueIndex:idx_invoice_period,priority:2" json:"period_start"`
+	PeriodEnd   time.Time         `gorm:"not null" json:"period_end"`
+	BillingTier string            `gorm:"size:50;not null" json:"billing_tier"`
+	Currency    string            `gorm:"size:3;not null" json:"currency"`
+	Total       int64             `gorm:"not null" json:"total"`
+	LineItems   []InvoiceLineItem `gorm:"foreignKey:InvoiceID;constraint:OnDelete:CASCADE" json:"line_items"`
+	IssuedAt    time.Time         `gorm:"not null" json:"issued_at"`
+}
+
+// BeforeUpdate keeps issued invoices immutable.
+func (i *Invoice) BeforeUpdate(tx *gorm.DB) error {
+	return ErrInvoiceImmutable
+}
+
+// BeforeDelete keeps issued invoices immutable.
+func (i *Invoice) BeforeDelete(tx *gorm.DB) error {
+	return ErrInvoiceImmutable
+}
+
+// InvoiceLineItem is one charge of an invoice. Usage and overage lines charge Quantity
+// units at PricePerThousand; the base line charges the plan's base price once.
+type InvoiceLineItem struct {
+	ID               uint   `gorm:"primaryKey" json:"-"`
+	InvoiceID        uint   `gorm:"not null;index" json:"-"`
+	Kind             string `gorm:"size:20;not null" json:"kind"`
+	Channel          string `gorm:"size:50" json:"channel,omitempty"`
+	Description      string `gorm:"size:255;not null" json:"description"`
+	Quantity         int    `gorm:"not null" json:"quantity"`
+	PricePerThousand int64  `gorm:"not null" json:"price_per_thousand"`
+	Amount           int64  `gorm:"not null" json:"amount"`
+}
+
+// PriceUnits returns the amount charged for units at a price per thousand, rounded half
+// up to a minor unit.
+func PriceUnits(units int, pricePerThousand int64) int64 {
+	return (int64(units)*pricePerThousand + 500) / 1000
+}
+
+// currencyExponents are the ISO 4217 minor unit exponents of currencies that do not have
+// two decimals.
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// CurrencyExponent returns the number of decimals of a currency's minor unit.
+func CurrencyExponent(currency string) int {
+	if exponent, ok := currencyExponents[currency]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// FormatAmount formats an amount in minor units with the decimals of its currency and the
+// currency, e.g. "12.50 USD" or "1250 JPY".
+func FormatAmount(amount int64, currency string) string {
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	exponent := CurrencyExponent(currency)
+	if exponent == 0 {
+		return fmt.Sprintf("%s%d %s", sign, amount, currency)
+	}
+	scale := int64(1)
+	for i := 0; i < exponent; i++ {
+		scale *= 10
+	}
+	return fmt.Sprintf("%s%d.%0*d %s", sign, amount/scale, exponent, amount%scale, currency)
+}
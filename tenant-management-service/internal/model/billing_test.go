@@ -0,0 +1,26 @@
+package model
+
+import "testing"
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		currency string
+		want     string
+	}{
+		{1250, "USD", "12.50 USD"},
+		{5, "EUR", "0.05 EUR"},
+		{-1999, "USD", "-19.99 USD"},
+		{0, "GBP", "0.00 GBP"},
+		{1250, "JPY", "1250 JPY"},
+		{-300, "KRW", "-300 KRW"},
+		{1250, "BHD", "1.250 BHD"},
+		{7, "KWD", "0.007 KWD"},
+		{12345, "CLF", "1.2345 CLF"},
+	}
+	for _, tt := range tests {
+		if got := FormatAmount(tt.amount, tt.currency); got != tt.want {
+			t.Errorf("FormatAmount(%d, %s) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	ChangeResourceConfig = "config"
+	ChangeResourceQuota  = "quota"
+)
+
+// ChangeEvent is an entry in the append-only change log. Its ID doubles as the
+// revision cursor handed out to watchers and is assigned from the ChangeSequence.
+type ChangeEvent struct {
+	ID        uint64          `gorm:"primaryKey" json:"revision"`
+	TenantID  string          `gorm:"size:255;not null;index" json:"tenant_id"`
+	Resource  string          `gorm:"size:50;not null" json:"resource"`
+	Key       string          `gorm:"size:255;not null" json:"key"`
+	Payload   json.RawMessage `gorm:"type:json" json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// ChangeSequenceID is the ID of the single ChangeSequence row.
+const ChangeSequenceID = 1
+
+// ChangeSequence holds the last revision assigned to a change event. Writers hold its row
+// lock until their transaction commits, so revisions become visible in increasing order
+// and watchers never skip past a revision that is still to commit.
+type ChangeSequence struct {
+	ID       uint   `gorm:"primaryKey"`
+	Revision uint64 `gorm:"not null"`
+}
@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+// Channel is an entry of the channel catalog. Quotas, usage and channel configuration may
+// only refer to channels in the catalog, by their lowercase code. A disabled channel is
+// unavailable to every plan; otherwise TierOverrides decide availability per billing tier
+// and channels without an override for a tier are available to it.
+type Channel struct {
+	ID            uint                  `gorm:"primaryKey" json:"id"`
+	Code          string                `gorm:"size:50;uniqueIndex;not null" json:"code"`
+	Name          string                `gorm:"size:100;not null" json:"name"`
+	UnitOfMeasure string                `gorm:"size:50;not null" json:"unit_of_measure"`
+	Enabled       bool                  `gorm:"not null" json:"enabled"`
+	TierOverrides []ChannelTierOverride `gorm:"foreignKey:ChannelID;constraint:OnDelete:CASCADE" json:"tier_overrides"`
+	CreatedAt     time.Time             `json:"created_at"`
+	UpdatedAt     time.Time             `json:"updated_at"`
+}
+
+type ChannelTierOverride struct {
+	ID          uint   `gorm:"primaryKey" json:"-"`
+	ChannelID   uint   `gorm:"not null;uniqueIndex:idx_channel_tier" json:"-"`
+	BillingTier string `gorm:"size:50;not null;uniqueIndex:idx_channel_tier" json:"billing_tier"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// AvailableTo reports whether the channel can be used on a billing tier.
+func (c *Channel) AvailableTo(billingTier string) bool {
+	if !c.Enabled {
+		return false
+	}
+	for _, override := range c.TierOverrides {
+		if override.BillingTier == billingTier {
+			return override.Enabled
+		}
+	}
+	return true
+}
+
+// ChannelConfigPrefix prefixes configuration keys holding per-tenant channel settings,
+// e.g. "channels.email.from_address".
+const ChannelConfigPrefix = "channels."
+
+// DefaultChannels is the catalog the service starts with.
+var DefaultChannels = []Channel{
+	{Code: "email", Name: "Email", UnitOfMeasure: "message", Enabled: true},
+	{Code: "sms", Name: "SMS", UnitOfMeasure: "segment", Enabled: true},
+	{Code: "push", Name: "Mobile push", UnitOfMeasure: "notification", Enabled: true},
+	{Code: "webpush", Name: "Web push", UnitOfMeasure: "notification", Enabled: true},
+	{Code: "whatsapp", Name: "WhatsApp", UnitOfMeasure: "message", Enabled: true},
+	{Code: "slack", Name: "Slack", UnitOfMeasure: "message", Enabled: true},
+	{Code: "webhook", Name: "Webhook", UnitOfMeasure: "request", Enabled: true},
+	{Code: "in_app", Name: "In-app", UnitOfMeasure: "notification", Enabled: true},
+}
+
+// ChannelAvailability is a catalog channel as seen by one tenant.
+type ChannelAvailability struct {
+	Code          string `json:"code"`
+	Name          string `json:"name"`
+	UnitOfMeasure string `json:"unit_of_measure"`
+	Available     bool   `json:"available"`
+}
@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// ConfigPreset is a named bundle of configuration entries that admins apply to many tenants.
+type ConfigPreset struct {
+	ID          uint                `gorm:"primaryKey" json:"id"`
+	Name        string              `gorm:"size:100;uniqueIndex;not null" json:"name"`
+	Description string              `gorm:"size:255" json:"description"`
+	Entries     []ConfigPresetEntry `gorm:"foreignKey:PresetID;constraint:OnDelete:CASCADE" json:"entries"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
+}
+
+type ConfigPresetEntry struct {
+	ID          uint   `gorm:"primaryKey" json:"-"`
+	PresetID    uint   `gorm:"not null;index" json:"-"`
+	ConfigKey   string `gorm:"size:255;not null" json:"config_key"`
+	ConfigValue string `gorm:"type:text;not null" json:"config_value"`
+	IsGlobal    bool   `gorm:"default:false" json:"is_global"`
+}
+
+const (
+	PresetActionCreate = "create"
+	PresetActionUpdate = "update"
+	PresetActionSkip   = "skip"
+)
+
+// PresetKeyChange describes what applying a preset does to a single configuration key.
+type PresetKeyChange struct {
+	ConfigKey string `json:"config_key"`
+	Action    string `json:"action"`
+	Reason    string `json:"reason,omitempty"`
+	OldValue  string `json:"old_value,omitempty"`
+	NewValue  string `json:"new_value"`
+}
+
+// PresetTenantReport lists the keys applied to and skipped for one tenant.
+type PresetTenantReport struct {
+	TenantID string            `json:"tenant_id"`
+	Applied  []PresetKeyChange `json:"applied"`
+	Skipped  []PresetKeyChange `json:"skipped"`
+	Error    string            `json:"error,omitempty"`
+}
@@ -1,13 +1,69 @@
 package model
 
-import "time"
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
 
+// Configuration is a setting of a tenant. Secret configurations, such as channel
+// credentials, are stored as given but masked wherever they are returned or published.
+// Values are text, so that credentials such as key files fit as they are.
 type Configuration struct {
-	ID          uint      `gorm:"primaryKey" json:"id"`
-	TenantID    string    `gorm:"size:255;not null" json:"tenant_id"`
-	ConfigKey   string    `gorm:"size:255;not null" json:"config_key"`
-	ConfigValue string    `gorm:"size:255;not null" json:"config_value"`
-	IsGlobal    bool      `gorm:"default:false" json:"is_global"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uint            `gorm:"primaryKey" json:"id"`
+	TenantID    string          `gorm:"size:255;not null;uniqueIndex:idx_config_tenant_key" json:"tenant_id"`
+	ConfigKey   string          `gorm:"size:255;not null;uniqueIndex:idx_config_tenant_key" json:"config_key"`
+	ConfigValue string          `gorm:"type:text;not null" json:"config_value"`
+	JSONValue   json.RawMessage `gorm:"type:json" json:"json_value,omitempty"`
+	IsGlobal    bool            `gorm:"default:false" json:"is_global"`
+	IsSecret    bool            `gorm:"not null" json:"is_secret"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// SecretMask replaces the values of secret configurations in responses and change events.
+const SecretMask = "********"
+
+// SecretChannelSettings lists the channel settings holding credentials. Configurations of
+// these settings, e.g. "channels.sms.auth_token", are always secret.
+var SecretChannelSettings = []string{
+	"password",
+	"auth_token",
+	"fcm_service_account",
+	"apns_private_key",
+}
+
+// IsSecretConfigKey reports whether a configuration key holds a credential and must be secret.
+func IsSecretConfigKey(configKey string) bool {
+	if !strings.HasPrefix(configKey, ChannelConfigPrefix) {
+		return false
+	}
+	setting := configKey[strings.LastIndex(configKey, ".")+1:]
+	for _, secret := range SecretChannelSettings {
+		if setting == secret {
+			return true
+		}
+	}
+	return false
+}
+
+// Masked returns the configuration with its values masked if it is secret or holds a
+// credential, whether or not it was marked secret when it was stored.
+func (c Configuration) Masked() Configuration {
+	if c.IsSecret || IsSecretConfigKey(c.ConfigKey) {
+		c.ConfigValue = SecretMask
+		if c.JSONValue != nil {
+			c.JSONValue = json.RawMessage(`"` + SecretMask + `"`)
+		}
+	}
+	return c
+}
+
+// ConfigSchema is the JSON Schema that structured values of a configuration key must match.
+type ConfigSchema struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`
+	ConfigKey string          `gorm:"size:255;uniqueIndex;not null" json:"config_key"`
+	Schema    json.RawMessage `gorm:"type:json;not null" json:"schema"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
 }
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConfigurationMasked(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Configuration
+		masked bool
+	}{
+		{"plain", Configuration{ConfigKey: "locale", ConfigValue: "en"}, false},
+		{"channel setting", Configuration{ConfigKey: "channels.sms.from_number", ConfigValue: "+15005550006"}, false},
+		{"marked secret", Configuration{ConfigKey: "api.key", ConfigValue: "key", IsSecret: true}, true},
+		{"credential marked secret", Configuration{ConfigKey: "channels.sms.auth_token", ConfigValue: "token", IsSecret: true}, true},
+		// Stored before credentials were secret by key
+		{"credential not marked secret", Configuration{ConfigKey: "channels.sms.auth_token", ConfigValue: "token"}, true},
+		{"structured credential", Configuration{ConfigKey: "channels.push.fcm_service_account", JSONValue: json.RawMessage(`{"private_key": "key"}`)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.config.Masked()
+			if tt.masked {
+				if got.ConfigValue != SecretMask {
+					t.Errorf("ConfigValue = %q, want %q", got.ConfigValue, SecretMask)
+				}
+				if tt.config.JSONValue != nil && string(got.JSONValue) != `"`+SecretMask+`"` {
+					t.Errorf("JSONValue = %s, want the mask", got.JSONValue)
+				}
+				return
+			}
+			if got.ConfigValue != tt.config.ConfigValue {
+				t.Errorf("ConfigValue = %q, want %q", got.ConfigValue, tt.config.ConfigValue)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+const (
+	DevicePlatformFCM  = "fcm"
+	DevicePlatformAPNs = "apns"
+)
+
+// DevicePlatforms lists the push platforms device tokens can be registered for.
+var DevicePlatforms = []string{DevicePlatformFCM, DevicePlatformAPNs}
+
+// DeviceToken is a push token of a device registered for a recipient of a tenant. Push
+// notifications to the recipient go to all of its devices; tokens the push service reports
+// as invalid are removed.
+type DeviceToken struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	TenantID   string     `gorm:"size:255;not null;uniqueIndex:idx_device_tenant_token,priority:1;index:idx_device_tenant_recipient,priority:1" json:"tenant_id"`
+	Recipient  string     `gorm:"size:255;not null;index:idx_device_tenant_recipient,priority:2" json:"recipient"`
+	Platform   string     `gorm:"size:10;not null;uniqueIndex:idx_device_tenant_token,priority:2" json:"platform"`
+	Token      string     `gorm:"size:255;not null;uniqueIndex:idx_device_tenant_token,priority:3" json:"token"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+type ChannelDTO struct {
+	Code          string          `json:"code" binding:"required"`
+	Name          string          `json:"name" binding:"required"`
+	UnitOfMeasure string          `json:"unit_of_measure" binding:"required"`
+	Enabled       *bool           `json:"enabled"`
+	TierOverrides map[string]bool `json:"tier_overrides"`
+}
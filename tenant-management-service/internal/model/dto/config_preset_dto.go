@@ -0,0 +1,22 @@
+package dto
+
+type ConfigPresetDTO struct {
+	Name        string                 `json:"name" binding:"required"`
+	Description string                 `json:"description"`
+	Entries     []ConfigPresetEntryDTO `json:"entries" binding:"required,min=1,dive"`
+}
+
+type ConfigPresetEntryDTO struct {
+	ConfigKey   string `json:"config_key" binding:"required"`
+	ConfigValue string `json:"config_value" binding:"required"`
+	IsGlobal    bool   `json:"is_global"`
+}
+
+// ApplyPresetDTO selects the tenants a preset is applied to, either explicitly or by
+// billing tier. With DryRun set the changes are only previewed.
+type ApplyPresetDTO struct {
+	TenantIDs   []string `json:"tenant_ids"`
+	BillingTier string   `json:"billing_tier"`
+	Overwrite   bool     `json:"overwrite"`
+	DryRun      bool     `json:"dry_run"`
+}
@@ -0,0 +1,7 @@
+package dto
+
+type DeviceTokenDTO struct {
+	Recipient string `json:"recipient" binding:"required"`
+	Platform  string `json:"platform" binding:"required"`
+	Token     string `json:"token" binding:"required"`
+}
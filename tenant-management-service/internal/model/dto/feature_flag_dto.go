@@ -0,0 +1,9 @@
+package dto
+
+type FeatureFlagDTO struct {
+	Key               string          `json:"key" binding:"required"`
+	Description       string          `json:"description"`
+	DefaultEnabled    bool            `json:"default_enabled"`
+	RolloutPercentage *int            `json:"rollout_percentage" binding:"omitempty,min=0,max=100"`
+	TierOverrides     map[string]bool `json:"tier_overrides"`
+}
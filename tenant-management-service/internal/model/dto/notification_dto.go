@@ -0,0 +1,17 @@
+package dto
+
+// NotificationDTO requests a notification. Content is either given by Subject and Body or
+// rendered from a template.
+type NotificationDTO struct {
+	Channel   string                   `json:"channel" binding:"required"`
+	Recipient string                   `json:"recipient" binding:"required"`
+	Subject   string                   `json:"subject"`
+	Body      string                   `json:"body"`
+	Template  *NotificationTemplateDTO `json:"template"`
+	Metadata  map[string]string        `json:"metadata"`
+}
+
+type NotificationTemplateDTO struct {
+	Name string                 `json:"name" binding:"required"`
+	Data map[string]interface{} `json:"data"`
+}
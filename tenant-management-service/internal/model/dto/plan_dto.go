@@ -0,0 +1,19 @@
+package dto
+
+type PlanDTO struct {
+	BillingTier   string                `json:"billing_tier" binding:"required"`
+	Currency      string                `json:"currency" binding:"required"`
+	BasePrice     int64                 `json:"base_price" binding:"min=0"`
+	ChannelPrices []PlanChannelPriceDTO `json:"channel_prices" binding:"dive"`
+}
+
+type PlanChannelPriceDTO struct {
+	Channel          string               `json:"channel" binding:"required"`
+	PricePerThousand int64                `json:"price_per_thousand" binding:"min=0"`
+	OverageTiers     []PlanOverageTierDTO `json:"overage_tiers" binding:"dive"`
+}
+
+type PlanOverageTierDTO struct {
+	UpTo             int   `json:"up_to" binding:"min=0"`
+	PricePerThousand int64 `json:"price_per_thousand" binding:"min=0"`
+}
@@ -1,8 +1,40 @@
 package dto
 
 type QuotaDTO struct {
-	Channel      string `json:"channel" binding:"required"`
-	DailyLimit   int    `json:"daily_limit" binding:"required"`
-	MonthlyLimit int    `json:"monthly_limit" binding:"required"`
-	IsGlobal     bool   `json:"is_global"`
+	Channel               string              `json:"channel" binding:"required"`
+	DailyLimit            int                 `json:"daily_limit" binding:"required"`
+	MonthlyLimit          int                 `json:"monthly_limit" binding:"required"`
+	IsGlobal              bool                `json:"is_global"`
+	RateLimitPerSecond    int                 `json:"rate_limit_per_second" binding:"min=0"`
+	RateLimitPerMinute    int                 `json:"rate_limit_per_minute" binding:"min=0"`
+	RateLimitBurst        int                 `json:"rate_limit_burst" binding:"min=0"`
+	OveragePolicy         string              `json:"overage_policy"`
+	OverageCeilingPercent int                 `json:"overage_ceiling_percent" binding:"min=0"`
+	Windows               []QuotaWindowDTO    `json:"windows" binding:"dive"`
+	Thresholds            []QuotaThresholdDTO `json:"thresholds" binding:"dive"`
+}
+
+type QuotaWindowDTO struct {
+	Window string `json:"window" binding:"required"`
+	Hours  int    `json:"hours" binding:"min=0"`
+	Limit  int    `json:"limit" binding:"required,min=1"`
+}
+
+type QuotaThresholdDTO struct {
+	Percent int `json:"percent" binding:"required,min=1"`
+}
+
+type QuotaConsumeDTO struct {
+	Channel string `json:"channel" binding:"required"`
+	Count   int    `json:"count" binding:"required,min=1"`
+}
+
+type QuotaReservationDTO struct {
+	Channel    string `json:"channel" binding:"required"`
+	Count      int    `json:"count" binding:"required,min=1"`
+	TTLSeconds int    `json:"ttl_seconds" binding:"min=0"`
+}
+
+type ReservationCommitDTO struct {
+	Count int `json:"count" binding:"required,min=1"`
 }
@@ -0,0 +1,12 @@
+package dto
+
+import "time"
+
+// UsageEventDTO reports usage of a channel. Count defaults to 1, Timestamp to the time
+// the event is received and Outcome to "accepted", for which "sent" is an alias.
+type UsageEventDTO struct {
+	Channel   string     `json:"channel"`
+	Count     *int       `json:"count"`
+	Timestamp *time.Time `json:"timestamp"`
+	Outcome   string     `json:"outcome"`
+}
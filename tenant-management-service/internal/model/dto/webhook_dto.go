@@ -0,0 +1,7 @@
+package dto
+
+type WebhookEndpointDTO struct {
+	Name    string `json:"name" binding:"required"`
+	URL     string `json:"url" binding:"required"`
+	Enabled *bool  `json:"enabled"`
+}
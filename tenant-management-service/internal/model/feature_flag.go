@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// FeatureFlag is a globally defined flag. Per-tenant overrides live in the tenant's
+// configuration under the FeatureFlagConfigPrefix key prefix.
+type FeatureFlag struct {
+	ID                uint                      `gorm:"primaryKey" json:"id"`
+	Key               string                    `gorm:"size:100;uniqueIndex;not null" json:"key"`
+	Description       string                    `gorm:"size:255" json:"description"`
+	DefaultEnabled    bool                      `gorm:"default:false" json:"default_enabled"`
+	RolloutPercentage *int                      `json:"rollout_percentage"`
+	TierOverrides     []FeatureFlagTierOverride `gorm:"foreignKey:FlagID;constraint:OnDelete:CASCADE" json:"tier_overrides"`
+	CreatedAt         time.Time                 `json:"created_at"`
+	UpdatedAt         time.Time                 `json:"updated_at"`
+}
+
+type FeatureFlagTierOverride struct {
+	ID          uint   `gorm:"primaryKey" json:"-"`
+	FlagID      uint   `gorm:"not null;uniqueIndex:idx_flag_tier" json:"-"`
+	BillingTier string `gorm:"size:50;not null;uniqueIndex:idx_flag_tier" json:"billing_tier"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// FeatureFlagConfigPrefix prefixes configuration keys holding per-tenant flag overrides,
+// e.g. "feature.new_dashboard" = "true".
+const FeatureFlagConfigPrefix = "feature."
+
+const (
+	FlagReasonTenantOverride = "tenant_override"
+	FlagReasonTierOverride   = "tier_override"
+	FlagReasonRollout        = "rollout"
+	FlagReasonDefault        = "default"
+)
+
+// FlagEvaluation is the resolved state of a flag for one tenant.
+type FlagEvaluation struct {
+	Key     string `json:"key"`
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// UsageForecast projects the usage of a tenant's channel to the end of the current
+// calendar month of the tenant's timezone. Dates are calendar days in the form of
+// Usage.Date.
+type UsageForecast struct {
+	Channel             string            `json:"channel"`
+	DailyAverage        float64           `json:"daily_average"`
+	MonthToDate         int               `json:"month_to_date"`
+	ProjectedMonthTotal int               `json:"projected_month_total"`
+	Days                []ForecastDay     `json:"days"`
+	Quotas              []QuotaExhaustion `json:"quotas"`
+}
+
+// ForecastDay is the projected usage of one day. The projection of the current day
+// includes what has been used so far.
+type ForecastDay struct {
+	Date      time.Time `json:"date"`
+	Projected int       `json:"projected"`
+}
+
+// QuotaExhaustion is the day a quota window is projected to run out, or nil if it is not
+// projected to run out before the end of the month.
+type QuotaExhaustion struct {
+	Window      string     `json:"window"`
+	Hours       int        `json:"hours,omitempty"`
+	Limit       int        `json:"limit"`
+	Used        int        `json:"used"`
+	ExhaustedOn *time.Time `json:"exhausted_on"`
+}
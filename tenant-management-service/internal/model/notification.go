@@ -0,0 +1,63 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	NotificationStatusQueued  = "queued"
+	NotificationStatusSending = "sending"
+	NotificationStatusSent    = "sent"
+	NotificationStatusFailed  = "failed"
+)
+
+// NotificationTemplatePrefix prefixes the configuration keys holding a tenant's
+// notification templates, e.g. "templates.welcome". Their JSON value has a "subject" and a
+// "body", both Go text templates executed with the template data of a notification.
+const NotificationTemplatePrefix = "templates."
+
+// Notification is a message a tenant asked to send through a channel. Content given by a
+// template reference is rendered when the notification is accepted, so Subject and Body
+// always hold what is delivered. Queued notifications are picked up by the delivery
+// pipeline once NextAttemptAt has passed; a notification being sent is leased to one
+// worker until LockedUntil, after which it is picked up again.
+type Notification struct {
+	ID            string          `gorm:"primaryKey;size:36" json:"id"`
+	TenantID      string          `gorm:"size:255;not null;index" json:"tenant_id"`
+	Channel       string          `gorm:"size:50;not null" json:"channel"`
+	Recipient     string          `gorm:"size:255;not null" json:"recipient"`
+	Subject       string          `gorm:"size:255" json:"subject,omitempty"`
+	Body          string          `gorm:"type:text;not null" json:"body"`
+	Template      string          `gorm:"size:100" json:"template,omitempty"`
+	Metadata      json.RawMessage `gorm:"type:json" json:"metadata,omitempty"`
+	Status        string          `gorm:"size:20;not null;index:idx_notification_status_due" json:"status"`
+	Attempts      int             `gorm:"not null" json:"attempts"`
+	LastError     string          `gorm:"size:500" json:"last_error,omitempty"`
+	NextAttemptAt time.Time       `gorm:"not null;index:idx_notification_status_due" json:"-"`
+	LockedUntil   *time.Time      `json:"-"`
+	SentAt        *time.Time      `json:"sent_at,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+const (
+	NotificationAttemptSent    = "sent"
+	NotificationAttemptRetry   = "retry"
+	NotificationAttemptFailed  = "failed"
+	NotificationAttemptAborted = "aborted"
+)
+
+// NotificationAttempt records one delivery attempt of a notification. StatusCode is the
+// HTTP status the provider responded with, if it responded over HTTP with an error.
+type NotificationAttempt struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	NotificationID string    `gorm:"size:36;not null;index" json:"notification_id"`
+	Attempt        int       `gorm:"not null" json:"attempt"`
+	Provider       string    `gorm:"size:50" json:"provider,omitempty"`
+	Outcome        string    `gorm:"size:20;not null" json:"outcome"`
+	StatusCode     int       `json:"status_code,omitempty"`
+	Error          string    `gorm:"size:500" json:"error,omitempty"`
+	DurationMs     int64     `json:"duration_ms"`
+	AttemptedAt    time.Time `gorm:"not null" json:"attempted_at"`
+}
@@ -2,13 +2,95 @@ package model
 
 import "time"
 
+// Quota holds the limits of a tenant's channel. Windows are computed in the tenant's timezone.
+// Rate limits of zero are disabled; RateLimitBurst is the capacity of the per-second bucket
+// and defaults to RateLimitPerSecond. Thresholds raise alerts as usage of any window
+// approaches its limit. OveragePolicy decides what happens once a limit is reached; under
+// the billed policy usage may continue up to OverageCeilingPercent of every limit.
 type Quota struct {
-	ID           uint      `gorm:"primaryKey" json:"id"`
-	TenantID     string    `gorm:"size:255;not null" json:"tenant_id"`
-	Channel      string    `gorm:"size:50;not null" json:"channel"`
-	DailyLimit   int       `gorm:"default:10000" json:"daily_limit"`
-	MonthlyLimit int       `gorm:"default:300000" json:"monthly_limit"`
-	IsGlobal     bool      `gorm:"default:false" json:"is_global"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID                    uint             `gorm:"primaryKey" json:"id"`
+	TenantID              string           `gorm:"size:255;not null;uniqueIndex:idx_quota_tenant_channel" json:"tenant_id"`
+	Channel               string           `gorm:"size:50;not null;uniqueIndex:idx_quota_tenant_channel" json:"channel"`
+	DailyLimit            int              `gorm:"default:10000" json:"daily_limit"`
+	MonthlyLimit          int              `gorm:"default:300000" json:"monthly_limit"`
+	RateLimitPerSecond    int              `gorm:"default:0" json:"rate_limit_per_second"`
+	RateLimitPerMinute    int              `gorm:"default:0" json:"rate_limit_per_minute"`
+	RateLimitBurst        int              `gorm:"default:0" json:"rate_limit_burst"`
+	OveragePolicy         string           `gorm:"size:20;default:block" json:"overage_policy"`
+	OverageCeilingPercent int              `gorm:"default:0" json:"overage_ceiling_percent"`
+	Windows               []QuotaWindow    `gorm:"foreignKey:QuotaID;constraint:OnDelete:CASCADE" json:"windows"`
+	Thresholds            []QuotaThreshold `gorm:"foreignKey:QuotaID;constraint:OnDelete:CASCADE" json:"thresholds"`
+	IsGlobal              bool             `gorm:"default:false" json:"is_global"`
+	CreatedAt             time.Time        `json:"created_at"`
+	UpdatedAt             time.Time        `json:"updated_at"`
+}
+
+const (
+	// OveragePolicyBlock rejects usage beyond the limits.
+	OveragePolicyBlock = "block"
+	// OveragePolicyFlag allows usage beyond the limits and flags it.
+	OveragePolicyFlag = "flag"
+	// OveragePolicyBilled allows usage beyond the limits up to a ceiling and bills it.
+	OveragePolicyBilled = "billed"
+)
+
+// OveragePolicies lists the overage policies a quota can use.
+var OveragePolicies = []string{OveragePolicyBlock, OveragePolicyFlag, OveragePolicyBilled}
+
+// MaxOverageCeilingPercent bounds the ceiling of billed overage.
+const MaxOverageCeilingPercent = 1000
+
+const (
+	QuotaWindowHourly  = "hourly"
+	QuotaWindowDaily   = "daily"
+	QuotaWindowWeekly  = "weekly"
+	QuotaWindowMonthly = "monthly"
+	QuotaWindowRolling = "rolling"
+)
+
+// MaxRollingWindowHours bounds rolling windows to the retention of hourly usage buckets.
+const MaxRollingWindowHours = 168
+
+// QuotaWindow is a limit over an additional window besides the daily and monthly limits
+// of a quota. Hours is only used by rolling windows.
+type QuotaWindow struct {
+	ID      uint   `gorm:"primaryKey" json:"-"`
+	QuotaID uint   `gorm:"not null;index" json:"-"`
+	Window  string `gorm:"size:20;not null" json:"window"`
+	Hours   int    `gorm:"default:0" json:"hours,omitempty"`
+	Limit   int    `gorm:"column:window_limit;not null" json:"limit"`
+}
+
+// QuotaWindowStatus is the allowance left in one quota window. Remaining is measured against
+// the limit; Ceiling is the point beyond the limit up to which billed overage is allowed
+// and Overage the usage beyond the limit so far.
+type QuotaWindowStatus struct {
+	Window    string    `json:"window"`
+	StartsAt  time.Time `json:"starts_at"`
+	Hours     int       `json:"hours,omitempty"`
+	Limit     int       `json:"limit"`
+	Ceiling   int       `json:"ceiling,omitempty"`
+	Used      int       `json:"used"`
+	Overage   int       `json:"overage,omitempty"`
+	Reserved  int       `json:"reserved"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// QuotaStatus is the current state of every window of a tenant's channel quota.
+type QuotaStatus struct {
+	Channel       string              `json:"channel"`
+	OveragePolicy string              `json:"overage_policy"`
+	Windows       []QuotaWindowStatus `json:"windows"`
+}
+
+// QuotaConsumption is the outcome of a successful quota consumption. Overage is the part
+// of the consumption beyond the limits, which the quota's overage policy allowed.
+type QuotaConsumption struct {
+	TenantID      string              `json:"tenant_id"`
+	Channel       string              `json:"channel"`
+	Consumed      int                 `json:"consumed"`
+	Overage       int                 `json:"overage"`
+	OveragePolicy string              `json:"overage_policy"`
+	Windows       []QuotaWindowStatus `json:"windows"`
 }
@@ -0,0 +1,49 @@
+package model
+
+import "time"
+
+// MaxQuotaThresholdPercent bounds alert thresholds. Thresholds above 100% alert on usage
+// beyond the limits allowed by an overage policy.
+const MaxQuotaThresholdPercent = MaxOverageCeilingPercent
+
+const (
+	AlertSinkLog     = "log"
+	AlertSinkEmail   = "email"
+	AlertSinkWebhook = "webhook"
+)
+
+// Tenant configuration keys selecting how quota alerts are delivered. AlertSinksConfigKey
+// holds a comma-separated list of sinks and defaults to the log sink.
+const (
+	AlertSinksConfigKey      = "alerts.sinks"
+	AlertWebhookURLConfigKey = "alerts.webhook_url"
+)
+
+// QuotaThreshold is a percentage of a quota's window limits at which the tenant is alerted.
+type QuotaThreshold struct {
+	ID      uint `gorm:"primaryKey" json:"-"`
+	QuotaID uint `gorm:"not null;index" json:"-"`
+	Percent int  `gorm:"not null" json:"percent"`
+}
+
+// QuotaAlert records that usage of a quota window crossed a threshold. An alert is raised
+// at most once per threshold and window, which WindowStart identifies. Sinks lists the sinks
+// the alert was delivered through; DeliveryError describes the sinks that failed.
+type QuotaAlert struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	TenantID      string     `gorm:"size:255;not null;uniqueIndex:idx_quota_alert_window,priority:1" json:"tenant_id"`
+	Channel       string     `gorm:"size:50;not null;uniqueIndex:idx_quota_alert_window,priority:2" json:"channel"`
+	Window        string     `gorm:"size:20;not null;uniqueIndex:idx_quota_alert_window,priority:3" json:"window"`
+	Hours         int        `gorm:"not null;default:0;uniqueIndex:idx_quota_alert_window,priority:4" json:"hours,omitempty"`
+	WindowStart   time.Time  `gorm:"not null;uniqueIndex:idx_quota_alert_window,priority:5" json:"window_start"`
+	Threshold     int        `gorm:"not null;uniqueIndex:idx_quota_alert_window,priority:6" json:"threshold"`
+	WindowEnd     time.Time  `gorm:"not null" json:"window_end"`
+	Limit         int        `gorm:"column:alert_limit;not null" json:"limit"`
+	Used          int        `gorm:"not null" json:"used"`
+	Sinks         string     `gorm:"size:255" json:"sinks"`
+	DeliveryError string     `gorm:"size:1024" json:"delivery_error,omitempty"`
+	DeliveredAt   *time.Time `gorm:"index" json:"delivered_at,omitempty"`
+	LockedUntil   *time.Time `json:"-"`
+	CreatedAt     time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
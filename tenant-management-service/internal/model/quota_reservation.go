@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+const (
+	ReservationStatusActive    = "active"
+	ReservationStatusCommitted = "committed"
+	ReservationStatusReleased  = "released"
+	ReservationStatusExpired   = "expired"
+)
+
+// QuotaReservation holds quota capacity for a tenant's channel until it expires. Committed
+// counts are moved into usage; whatever is neither committed nor released stays held.
+type QuotaReservation struct {
+	ID        string    `gorm:"primaryKey;size:36" json:"id"`
+	TenantID  string    `gorm:"size:255;not null;index:idx_reservation_tenant_channel_status" json:"tenant_id"`
+	Channel   string    `gorm:"size:50;not null;index:idx_reservation_tenant_channel_status" json:"channel"`
+	Status    string    `gorm:"size:20;not null;index:idx_reservation_tenant_channel_status;index:idx_reservation_status_expires" json:"status"`
+	Count     int       `gorm:"not null" json:"count"`
+	Committed int       `gorm:"not null;default:0" json:"committed"`
+	ExpiresAt time.Time `gorm:"not null;index:idx_reservation_status_expires" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Held returns the capacity the reservation still holds, none once it is no longer active.
+func (r *QuotaReservation) Held() int {
+	if r.Status != ReservationStatusActive {
+		return 0
+	}
+	return r.Count - r.Committed
+}
@@ -0,0 +1,9 @@
+package model
+
+import "time"
+
+// SchemaMigration records a one-off data migration that has been applied to the database.
+type SchemaMigration struct {
+	Name      string    `gorm:"primaryKey;size:100"`
+	AppliedAt time.Time `gorm:"not null"`
+}
@@ -2,16 +2,37 @@ package model
 
 import "time"
 
+// BillingTiers lists the billing tiers a tenant can be assigned to.
+var BillingTiers = []string{"basic", "standard", "enterprise"}
+
 type Tenant struct {
-	ID              uint      `gorm:"primaryKey" json:"id"`
-	Name            string    `gorm:"size:255;not null" json:"name"`
-	ClientID        string    `gorm:"size:255;unique;not null" json:"client_id"`
-	ClientSecret    string    `gorm:"size:255;not null" json:"client_secret"`
-	Email           string    `gorm:"size:255;not null" json:"email"`
-	Phone           string    `gorm:"size:20" json:"phone"`
-	Status          string    `gorm:"size:50;default:active" json:"status"`
-	BillingTier     string    `gorm:"size:50;default:basic" json:"billing_tier"`
-	DefaultLanguage string    `gorm:"size:10;default:'en'" json:"default_language"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	Name               string    `gorm:"size:255;not null" json:"name"`
+	ClientID           string    `gorm:"size:255;unique;not null" json:"client_id"`
+	ClientSecret       string    `gorm:"size:255;not null" json:"client_secret"`
+	Email              string    `gorm:"size:255;not null" json:"email"`
+	Phone              string    `gorm:"size:20" json:"phone"`
+	Status             string    `gorm:"size:50;default:active" json:"status"`
+	BillingTier        string    `gorm:"size:50;default:basic" json:"billing_tier"`
+	DefaultLanguage    string    `gorm:"size:10;default:'en'" json:"default_language"`
+	Timezone           string    `gorm:"size:64;default:UTC" json:"timezone"`
+	BillingCycleAnchor int       `gorm:"default:1" json:"billing_cycle_anchor"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// MaxBillingCycleAnchor is the last day of the month a billing cycle can start on, so
+// that every month has the anchor day.
+const MaxBillingCycleAnchor = 28
+
+// Location returns the tenant's timezone, falling back to UTC if it is unset or unknown.
+func (t *Tenant) Location() *time.Location {
+	if t.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(t.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
 }
@@ -4,12 +4,183 @@ import (
 	"time"
 )
 
+// Usage counts notifications per tenant, channel and calendar day in the tenant's timezone,
+// broken down by delivery outcome. NotificationsSent counts notifications accepted for
+// delivery, which is what quotas limit. Delivered, undelivered, bounced and complained
+// notifications are outcomes of accepted ones; undelivered ones could not be handed to the
+// provider. Failed and suppressed notifications were never accepted.
+// OverageSent and FlaggedSent are the parts of NotificationsSent that went beyond the quota
+// limits under the billed and flag overage policies; only OverageSent is billable.
 type Usage struct {
+	ID                       uint      `gorm:"primaryKey" json:"id"`
+	TenantID                 string    `gorm:"size:255;not null;uniqueIndex:idx_usage_tenant_channel_date,priority:1" json:"tenant_id"`
+	Date                     time.Time `gorm:"not null;uniqueIndex:idx_usage_tenant_channel_date,priority:3" json:"date"`
+	Channel                  string    `gorm:"size:50;not null;uniqueIndex:idx_usage_tenant_channel_date,priority:2" json:"channel"`
+	NotificationsSent        int       `gorm:"default:0" json:"notifications_sent"`
+	NotificationsDelivered   int       `gorm:"default:0" json:"notifications_delivered"`
+	NotificationsFailed      int       `gorm:"default:0" json:"notifications_failed"`
+	NotificationsUndelivered int       `gorm:"default:0" json:"notifications_undelivered"`
+	NotificationsBounced     int       `gorm:"default:0" json:"notifications_bounced"`
+	NotificationsComplained  int       `gorm:"default:0" json:"notifications_complained"`
+	NotificationsSuppressed  int       `gorm:"default:0" json:"notifications_suppressed"`
+	OverageSent              int       `gorm:"default:0" json:"overage_sent"`
+	FlaggedSent              int       `gorm:"default:0" json:"flagged_sent"`
+	CreatedAt                time.Time `json:"created_at"`
+	UpdatedAt                time.Time `json:"updated_at"`
+}
+
+// UsageRollup holds the usage of a tenant's channel in a calendar month once its daily
+// Usage rows have been rolled up. Month is the first day of the month in the form of
+// Usage.Date.
+type UsageRollup struct {
+	ID                       uint      `gorm:"primaryKey" json:"id"`
+	TenantID                 string    `gorm:"size:255;not null;uniqueIndex:idx_rollup_tenant_channel_month,priority:1" json:"tenant_id"`
+	Channel                  string    `gorm:"size:50;not null;uniqueIndex:idx_rollup_tenant_channel_month,priority:2" json:"channel"`
+	Month                    time.Time `gorm:"not null;uniqueIndex:idx_rollup_tenant_channel_month,priority:3" json:"month"`
+	NotificationsSent        int       `gorm:"default:0" json:"notifications_sent"`
+	NotificationsDelivered   int       `gorm:"default:0" json:"notifications_delivered"`
+	NotificationsFailed      int       `gorm:"default:0" json:"notifications_failed"`
+	NotificationsUndelivered int       `gorm:"default:0" json:"notifications_undelivered"`
+	NotificationsBounced     int       `gorm:"default:0" json:"notifications_bounced"`
+	NotificationsComplained  int       `gorm:"default:0" json:"notifications_complained"`
+	NotificationsSuppressed  int       `gorm:"default:0" json:"notifications_suppressed"`
+	OverageSent              int       `gorm:"default:0" json:"overage_sent"`
+	FlaggedSent              int       `gorm:"default:0" json:"flagged_sent"`
+	CreatedAt                time.Time `json:"created_at"`
+	UpdatedAt                time.Time `json:"updated_at"`
+}
+
+const (
+	UsageOutcomeAccepted  = "accepted"
+	UsageOutcomeDelivered = "delivered"
+	UsageOutcomeFailed    = "failed"
+	// UsageOutcomeUndelivered counts accepted notifications whose delivery failed for good.
+	UsageOutcomeUndelivered = "undelivered"
+	UsageOutcomeBounced     = "bounced"
+	UsageOutcomeComplained  = "complained"
+	UsageOutcomeSuppressed  = "suppressed"
+	// UsageOutcomeSent is accepted by usage events as another name for UsageOutcomeAccepted.
+	UsageOutcomeSent = "sent"
+)
+
+// UsageOutcomes lists the delivery outcomes usage is broken down by.
+var UsageOutcomes = []string{
+	UsageOutcomeAccepted,
+	UsageOutcomeDelivered,
+	UsageOutcomeFailed,
+	UsageOutcomeUndelivered,
+	UsageOutcomeBounced,
+	UsageOutcomeComplained,
+	UsageOutcomeSuppressed,
+}
+
+// UsageRates relates delivery outcomes. Delivery, bounce and complaint rates are shares of
+// accepted notifications, delivered ones for complaints; the failure rate is the share of
+// attempted notifications, accepted or failed, that failed, went undelivered or bounced.
+// Undelivered and bounced notifications are part of the accepted ones, so each attempted
+// notification counts once. A rate is nil when there is nothing to relate it to.
+type UsageRates struct {
+	DeliveryRate  *float64 `json:"delivery_rate"`
+	FailureRate   *float64 `json:"failure_rate"`
+	BounceRate    *float64 `json:"bounce_rate"`
+	ComplaintRate *float64 `json:"complaint_rate"`
+}
+
+// NewUsageRates computes rates from counts keyed by outcome.
+func NewUsageRates(counts map[string]int) UsageRates {
+	accepted := counts[UsageOutcomeAccepted]
+	return UsageRates{
+		DeliveryRate: ratio(counts[UsageOutcomeDelivered], accepted),
+		FailureRate: ratio(counts[UsageOutcomeFailed]+counts[UsageOutcomeUndelivered]+counts[UsageOutcomeBounced],
+			accepted+counts[UsageOutcomeFailed]),
+		BounceRate:    ratio(counts[UsageOutcomeBounced], accepted),
+		ComplaintRate: ratio(counts[UsageOutcomeComplained], counts[UsageOutcomeDelivered]),
+	}
+}
+
+func ratio(part, whole int) *float64 {
+	if whole <= 0 {
+		return nil
+	}
+	r := float64(part) / float64(whole)
+	return &r
+}
+
+// UsageDelta is usage to add to a tenant's channel. Overage and Flagged are the parts of
+// Sent beyond the quota limits, as tracked by Usage.
+type UsageDelta struct {
+	Sent    int
+	Overage int
+	Flagged int
+}
+
+// UsageBucket counts notifications sent in one hour of the tenant's local time. BucketStart
+// is the start of that hour in UTC. Buckets back the hourly and rolling quota windows.
+type UsageBucket struct {
 	ID                uint      `gorm:"primaryKey" json:"id"`
-	TenantID          string    `gorm:"size:255;not null" json:"tenant_id"`
-	Date              time.Time `gorm:"not null" json:"date"`
-	Channel           string    `gorm:"size:50;not null" json:"channel"`
+	TenantID          string    `gorm:"size:255;not null;uniqueIndex:idx_bucket_tenant_channel_start,priority:1" json:"tenant_id"`
+	Channel           string    `gorm:"size:50;not null;uniqueIndex:idx_bucket_tenant_channel_start,priority:2" json:"channel"`
+	BucketStart       time.Time `gorm:"not null;uniqueIndex:idx_bucket_tenant_channel_start,priority:3" json:"bucket_start"`
 	NotificationsSent int       `gorm:"default:0" json:"notifications_sent"`
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
 }
+
+// UsageDate returns the calendar date of t in loc as midnight UTC, the form in which
+// Usage.Date is stored.
+func UsageDate(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// UsageHour returns the start of the hour of t in loc, converted to UTC.
+func UsageHour(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), 0, 0, 0, loc).UTC()
+}
+
+const (
+	UsageGranularityDay   = "day"
+	UsageGranularityWeek  = "week"
+	UsageGranularityMonth = "month"
+)
+
+// UsageGranularities lists the periods usage can be aggregated by.
+var UsageGranularities = []string{UsageGranularityDay, UsageGranularityWeek, UsageGranularityMonth}
+
+const (
+	UsageGroupByChannel = "channel"
+	UsageGroupByOutcome = "outcome"
+)
+
+// UsageGroupings lists the dimensions usage can be grouped by besides the period.
+var UsageGroupings = []string{UsageGroupByChannel, UsageGroupByOutcome}
+
+// UsageQuery selects and aggregates the usage of a tenant. From and To are inclusive
+// calendar dates in the form of Usage.Date and are unbounded when nil. Weeks start on
+// Monday and months on the first day of the calendar month. Rolled-up months cannot be
+// split: they count as a whole towards ranges they overlap and show up as a single point
+// dated the first of the month at any granularity.
+type UsageQuery struct {
+	TenantID       string
+	Channel        string
+	From           *time.Time
+	To             *time.Time
+	Granularity    string
+	GroupByChannel bool
+	GroupByOutcome bool
+	Limit          int
+	Offset         int
+}
+
+// UsagePoint is the usage of one period, channel and outcome. Channel and Outcome are only
+// set when usage is grouped by them. Without grouping by outcome, Count is the number of
+// accepted notifications and the delivery and failure rates of the point are included.
+type UsagePoint struct {
+	PeriodStart  time.Time `json:"period_start"`
+	Channel      string    `json:"channel,omitempty"`
+	Outcome      string    `json:"outcome,omitempty"`
+	Count        int       `json:"count"`
+	DeliveryRate *float64  `json:"delivery_rate,omitempty"`
+	FailureRate  *float64  `json:"failure_rate,omitempty"`
+}
@@ -0,0 +1,66 @@
+package model
+
+import "testing"
+
+func TestNewUsageRates(t *testing.T) {
+	rate := func(r float64) *float64 { return &r }
+	tests := []struct {
+		name   string
+		counts map[string]int
+		want   UsageRates
+	}{
+		{
+			name:   "nothing to relate",
+			counts: map[string]int{},
+			want:   UsageRates{},
+		},
+		{
+			name: "every outcome",
+			counts: map[string]int{
+				UsageOutcomeAccepted:    80,
+				UsageOutcomeDelivered:   60,
+				UsageOutcomeFailed:      20,
+				UsageOutcomeUndelivered: 10,
+				UsageOutcomeBounced:     10,
+				UsageOutcomeComplained:  3,
+			},
+			want: UsageRates{
+				DeliveryRate:  rate(0.75),
+				FailureRate:   rate(0.4),
+				BounceRate:    rate(0.125),
+				ComplaintRate: rate(0.05),
+			},
+		},
+		{
+			name:   "undelivered notifications count once",
+			counts: map[string]int{UsageOutcomeAccepted: 10, UsageOutcomeUndelivered: 10},
+			want:   UsageRates{DeliveryRate: rate(0), FailureRate: rate(1), BounceRate: rate(0)},
+		},
+		{
+			name:   "only failures",
+			counts: map[string]int{UsageOutcomeFailed: 4},
+			want:   UsageRates{FailureRate: rate(1)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewUsageRates(tt.counts)
+			check := func(name string, got, want *float64) {
+				if (got == nil) != (want == nil) || got != nil && *got != *want {
+					t.Errorf("%s = %v, want %v", name, rateValue(got), rateValue(want))
+				}
+			}
+			check("DeliveryRate", got.DeliveryRate, tt.want.DeliveryRate)
+			check("FailureRate", got.FailureRate, tt.want.FailureRate)
+			check("BounceRate", got.BounceRate, tt.want.BounceRate)
+			check("ComplaintRate", got.ComplaintRate, tt.want.ComplaintRate)
+		})
+	}
+}
+
+func rateValue(r *float64) interface{} {
+	if r == nil {
+		return nil
+	}
+	return *r
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// WebhookEndpoint is an HTTP endpoint a tenant registered to receive notifications of the
+// webhook channel. Notifications address an endpoint by its name as their recipient. Each
+// payload is signed with the endpoint's secret, which is only returned when it is created
+// or rotated.
+type WebhookEndpoint struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TenantID  string    `gorm:"size:255;not null;uniqueIndex:idx_webhook_tenant_name" json:"tenant_id"`
+	Name      string    `gorm:"size:100;not null;uniqueIndex:idx_webhook_tenant_name" json:"name"`
+	URL       string    `gorm:"size:2048;not null" json:"url"`
+	Secret    string    `gorm:"size:255;not null" json:"secret,omitempty"`
+	Enabled   bool      `gorm:"not null" json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+const (
+	// WebhookSignatureHeader carries the signature of a webhook payload in the form
+	// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<payload>" keyed with the secret>".
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	// WebhookIDHeader carries the ID of the notification a webhook delivers, which stays
+	// the same across attempts.
+	WebhookIDHeader = "X-Webhook-Id"
+)
@@ -0,0 +1,220 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"tenant-management-service/internal/model"
+	"time"
+)
+
+const (
+	// APNsEnvironmentProduction sends to apps from the App Store and TestFlight.
+	APNsEnvironmentProduction = "production"
+	// APNsEnvironmentSandbox sends to development builds of apps.
+	APNsEnvironmentSandbox = "sandbox"
+
+	// apnsTokenLifetime is how long provider tokens are reused. APNs accepts tokens for an
+	// hour and rejects refreshing them more often than every 20 minutes.
+	apnsTokenLifetime = 50 * time.Minute
+)
+
+// apnsInvalidTokenReasons are APNs error reasons reporting a token that will never work
+// again for the app.
+var apnsInvalidTokenReasons = map[string]bool{
+	"BadDeviceToken":         true,
+	"Unregistered":           true,
+	"DeviceTokenNotForTopic": true,
+}
+
+// APNsSender pushes to Apple devices through the APNs HTTP/2 API with token-based
+// authentication. The production and sandbox URLs are set by the service so that local
+// fakes can stand in for Apple; credentials are settings of the push channel:
+//
+//	apns_key_id       ID of the signing key
+//	apns_team_id      developer team the key belongs to
+//	apns_private_key  signing key, the contents of its .p8 file (secret)
+//	apns_topic        bundle ID of the app
+//	apns_environment  production (default) or sandbox
+//
+// The signing key is set as the plain value of its configuration, with the PEM text of
+// the .p8 file, line breaks included, as one string.
+type APNsSender struct {
+	baseURL        string
+	sandboxBaseURL string
+	client         *http.Client
+	tokens         *pushTokenCache
+}
+
+func NewAPNsSender(baseURL, sandboxBaseURL string) *APNsSender {
+	return &APNsSender{
+		baseURL:        strings.TrimSuffix(baseURL, "/"),
+		sandboxBaseURL: strings.TrimSuffix(sandboxBaseURL, "/"),
+		// The default transport negotiates HTTP/2 with TLS servers, as APNs requires
+		client: &http.Client{},
+		tokens: newPushTokenCache(),
+	}
+}
+
+func (s *APNsSender) configured(settings Settings) bool {
+	return settings["apns_private_key"] != ""
+}
+
+// apnsCredentials are the settings APNs requests are authorized with.
+type apnsCredentials struct {
+	keyID      string
+	teamID     string
+	privateKey string
+	topic      string
+	baseURL    string
+}
+
+// send posts an alert to one device. Unregistered and malformed tokens are invalid; APNs
+// errors are retried for 429 and 5xx responses and expired provider tokens.
+func (s *APNsSender) send(ctx context.Context, settings Settings, device *model.DeviceToken, notification *model.Notification) error {
+	credentials, err := s.credentials(settings)
+	if err != nil {
+		return err
+	}
+	providerToken, err := s.providerToken(credentials)
+	if err != nil {
+		return err
+	}
+	metadata, err := pushMetadata(notification)
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{}
+	for key, value := range metadata {
+		payload[key] = value
+	}
+	payload["aps"] = map[string]interface{}{
+		"alert": map[string]string{"title": notification.Subject, "body": notification.Body},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	endpoint := fmt.Sprintf("%s/3/device/%s", credentials.baseURL, url.PathEscape(device.Token))
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(err)
+	}
+	request.Header.Set("Authorization", "bearer "+providerToken)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("apns-topic", credentials.topic)
+	request.Header.Set("apns-push-type", "alert")
+	request.Header.Set("apns-priority", "10")
+	request.Header.Set("apns-id", notification.ID)
+
+	resp, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return s.apnsError(credentials, resp.StatusCode, respBody)
+}
+
+// check signs a provider token with the credentials. APNs has no endpoint to verify one
+// without sending a push.
+func (s *APNsSender) check(_ context.Context, settings Settings) error {
+	credentials, err := s.credentials(settings)
+	if err != nil {
+		return err
+	}
+	_, err = s.providerToken(credentials)
+	return err
+}
+
+// credentials reads the APNs credentials of the settings.
+func (s *APNsSender) credentials(settings Settings) (*apnsCredentials, error) {
+	credentials := &apnsCredentials{}
+	for key, value := range map[string]*string{
+		"apns_key_id":      &credentials.keyID,
+		"apns_team_id":     &credentials.teamID,
+		"apns_private_key": &credentials.privateKey,
+		"apns_topic":       &credentials.topic,
+	} {
+		var err error
+		if *value, err = settings.Require(key); err != nil {
+			return nil, err
+		}
+	}
+
+	switch settings["apns_environment"] {
+	case "", APNsEnvironmentProduction:
+		credentials.baseURL = s.baseURL
+	case APNsEnvironmentSandbox:
+		credentials.baseURL = s.sandboxBaseURL
+	default:
+		return nil, Permanent(fmt.Errorf("setting \"apns_environment\" must be %s or %s", APNsEnvironmentProduction, APNsEnvironmentSandbox))
+	}
+	return credentials, nil
+}
+
+func apnsTokenKey(credentials *apnsCredentials) string {
+	return credentialKey("apns", credentials.teamID, credentials.keyID, credentials.privateKey)
+}
+
+// providerToken returns the provider token of the credentials, signing a new one when the
+// cached one is due for renewal.
+func (s *APNsSender) providerToken(credentials *apnsCredentials) (string, error) {
+	return s.tokens.get(apnsTokenKey(credentials), func() (string, time.Time, error) {
+		key, err := parsePrivateKey("apns_private_key", credentials.privateKey)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		if _, ok := key.(*ecdsa.PrivateKey); !ok {
+			return "", time.Time{}, Permanent(errors.New("setting \"apns_private_key\" must hold an ECDSA key"))
+		}
+		now := time.Now()
+		token, err := signJWT(key, map[string]interface{}{"kid": credentials.keyID}, map[string]interface{}{
+			"iss": credentials.teamID,
+			"iat": now.Unix(),
+		})
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		return token, now.Add(apnsTokenLifetime), nil
+	})
+}
+
+// apnsError converts an APNs error response, classifying it by its reason.
+func (s *APNsSender) apnsError(credentials *apnsCredentials, status int, body []byte) error {
+	var apiErr struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.Unmarshal(body, &apiErr)
+	err := &StatusError{StatusCode: status, Err: fmt.Errorf("apns responded with status %d: %s", status, apiErr.Reason)}
+
+	switch {
+	case status == http.StatusGone, apnsInvalidTokenReasons[apiErr.Reason]:
+		return fmt.Errorf("%w: %w", errInvalidToken, err)
+	case apiErr.Reason == "ExpiredProviderToken":
+		s.tokens.drop(apnsTokenKey(credentials))
+		return err
+	case apiErr.Reason == "InvalidProviderToken":
+		s.tokens.drop(apnsTokenKey(credentials))
+		return Permanent(err)
+	case status == http.StatusTooManyRequests, status >= 500:
+		return err
+	default:
+		return Permanent(err)
+	}
+}
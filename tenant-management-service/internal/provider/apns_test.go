@@ -0,0 +1,168 @@
+package provider
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"tenant-management-service/internal/model"
+	"testing"
+)
+
+// apnsSettings returns push settings holding a freshly generated signing key.
+func apnsSettings(t *testing.T) Settings {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Settings{
+		"apns_key_id":      "KEY123",
+		"apns_team_id":     "TEAM123",
+		"apns_private_key": pkcs8PEM(t, key),
+		"apns_topic":       "com.example.app",
+	}
+}
+
+func pkcs8PEM(t *testing.T, key interface{}) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+// fakeAPNs answers pushes with the given status and body and records the provider tokens
+// of the requests.
+func fakeAPNs(t *testing.T, status int, body string, tokens *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/3/device/device-token" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if topic := r.Header.Get("apns-topic"); topic != "com.example.app" {
+			t.Errorf("apns-topic = %q", topic)
+		}
+		*tokens = append(*tokens, r.Header.Get("Authorization"))
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+}
+
+func apnsDevice() *model.DeviceToken {
+	return &model.DeviceToken{Platform: model.DevicePlatformAPNs, Token: "device-token"}
+}
+
+func TestAPNsSenderSend(t *testing.T) {
+	var production, sandbox []string
+	productionServer := fakeAPNs(t, http.StatusOK, "", &production)
+	defer productionServer.Close()
+	sandboxServer := fakeAPNs(t, http.StatusOK, "", &sandbox)
+	defer sandboxServer.Close()
+
+	sender := NewAPNsSender(productionServer.URL, sandboxServer.URL)
+	settings := apnsSettings(t)
+	notification := &model.Notification{ID: "n1", Channel: "push", Subject: "Hello", Body: "World"}
+	for i := 0; i < 2; i++ {
+		if err := sender.send(context.Background(), settings, apnsDevice(), notification); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+	if len(production) != 2 || production[0] != production[1] {
+		t.Errorf("provider tokens = %q, want one token reused", production)
+	}
+
+	settings["apns_environment"] = APNsEnvironmentSandbox
+	if err := sender.send(context.Background(), settings, apnsDevice(), notification); err != nil {
+		t.Fatalf("send to sandbox: %v", err)
+	}
+	if len(sandbox) != 1 || len(production) != 2 {
+		t.Errorf("requests to production %d, sandbox %d, want 2 and 1", len(production), len(sandbox))
+	}
+}
+
+func TestAPNsSenderRequiresECDSAKey(t *testing.T) {
+	var tokens []string
+	server := fakeAPNs(t, http.StatusOK, "", &tokens)
+	defer server.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings := apnsSettings(t)
+	settings["apns_private_key"] = pkcs8PEM(t, key)
+
+	sender := NewAPNsSender(server.URL, server.URL)
+	err = sender.send(context.Background(), settings, apnsDevice(), &model.Notification{Channel: "push", Body: "Hi"})
+	if !IsPermanent(err) {
+		t.Errorf("send with an RSA key = %v, want a permanent error", err)
+	}
+	if err := sender.check(context.Background(), settings); !IsPermanent(err) {
+		t.Errorf("check with an RSA key = %v, want a permanent error", err)
+	}
+	if len(tokens) != 0 {
+		t.Errorf("%d requests were sent with an RSA key", len(tokens))
+	}
+}
+
+func TestAPNsSenderErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		invalidToken bool
+		permanent    bool
+		newToken     bool
+	}{
+		{"bad device token", http.StatusBadRequest, `{"reason": "BadDeviceToken"}`, true, false, false},
+		{"device token not for topic", http.StatusBadRequest, `{"reason": "DeviceTokenNotForTopic"}`, true, false, false},
+		{"unregistered", http.StatusGone, `{"reason": "Unregistered", "timestamp": 1700000000000}`, true, false, false},
+		{"gone without reason", http.StatusGone, ``, true, false, false},
+		{"expired provider token", http.StatusForbidden, `{"reason": "ExpiredProviderToken"}`, false, false, true},
+		{"invalid provider token", http.StatusForbidden, `{"reason": "InvalidProviderToken"}`, false, true, true},
+		{"too many requests", http.StatusTooManyRequests, `{"reason": "TooManyRequests"}`, false, false, false},
+		{"internal server error", http.StatusInternalServerError, `{"reason": "InternalServerError"}`, false, false, false},
+		{"payload too large", http.StatusRequestEntityTooLarge, `{"reason": "PayloadTooLarge"}`, false, true, false},
+	}
+	settings := apnsSettings(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var tokens []string
+			server := fakeAPNs(t, tt.status, tt.body, &tokens)
+			defer server.Close()
+
+			sender := NewAPNsSender(server.URL, server.URL)
+			notification := &model.Notification{Channel: "push", Body: "Hi"}
+			err := sender.send(context.Background(), settings, apnsDevice(), notification)
+			if err == nil {
+				t.Fatal("send succeeded")
+			}
+			if got := errors.Is(err, errInvalidToken); got != tt.invalidToken {
+				t.Errorf("invalid token = %v, want %v (%v)", got, tt.invalidToken, err)
+			}
+			if got := IsPermanent(err); got != tt.permanent {
+				t.Errorf("permanent = %v, want %v (%v)", got, tt.permanent, err)
+			}
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.status {
+				t.Errorf("status error = %v, want status %d", statusErr, tt.status)
+			}
+
+			// Rejected provider tokens are dropped, so the next send signs a new one
+			_ = sender.send(context.Background(), settings, apnsDevice(), notification)
+			if len(tokens) != 2 {
+				t.Fatalf("%d requests, want 2", len(tokens))
+			}
+			if renewed := tokens[0] != tokens[1]; renewed != tt.newToken {
+				t.Errorf("provider token renewed = %v, want %v", renewed, tt.newToken)
+			}
+		})
+	}
+}
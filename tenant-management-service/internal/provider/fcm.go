@@ -0,0 +1,235 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"tenant-management-service/internal/model"
+	"time"
+)
+
+// fcmScope is the OAuth 2.0 scope of the FCM HTTP v1 API.
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// fcmInvalidTokenCode is the FCM error code reporting a token that will never work again.
+// SENDER_ID_MISMATCH is not among them: it means the token belongs to another Firebase
+// project than the credentials, which is fixed by correcting the credentials.
+const fcmInvalidTokenCode = "UNREGISTERED"
+
+// fcmServiceAccount holds the fields of a Google service account key used to authorize
+// with FCM.
+type fcmServiceAccount struct {
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+}
+
+// FCMSender pushes to Android and web devices through the FCM HTTP v1 API. The API and
+// OAuth token URLs are set by the service so that local fakes can stand in for Google;
+// credentials are settings of the push channel:
+//
+//	fcm_service_account  service account key JSON, as downloaded from Google Cloud (secret)
+//	fcm_project_id       Firebase project, the project of the service account by default
+//
+// The service account key is set as the plain value of its configuration, e.g.
+// "channels.push.fcm_service_account", with the contents of the key file as one string.
+type FCMSender struct {
+	baseURL  string
+	tokenURL string
+	client   *http.Client
+	tokens   *pushTokenCache
+}
+
+func NewFCMSender(baseURL, tokenURL string) *FCMSender {
+	return &FCMSender{
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		tokenURL: tokenURL,
+		client:   &http.Client{},
+		tokens:   newPushTokenCache(),
+	}
+}
+
+func (s *FCMSender) configured(settings Settings) bool {
+	return settings["fcm_service_account"] != ""
+}
+
+// send posts a message to one device. Unregistered tokens are invalid; FCM errors are
+// retried for 429 and 5xx responses and after rejected access tokens, other errors fail
+// permanently.
+func (s *FCMSender) send(ctx context.Context, settings Settings, device *model.DeviceToken, notification *model.Notification) error {
+	account, err := s.serviceAccount(settings)
+	if err != nil {
+		return err
+	}
+	accessToken, err := s.accessToken(ctx, account)
+	if err != nil {
+		return err
+	}
+	metadata, err := pushMetadata(notification)
+	if err != nil {
+		return err
+	}
+
+	message := map[string]interface{}{
+		"token":        device.Token,
+		"notification": map[string]string{"title": notification.Subject, "body": notification.Body},
+	}
+	if len(metadata) > 0 {
+		message["data"] = metadata
+	}
+	body, err := json.Marshal(map[string]interface{}{"message": message})
+	if err != nil {
+		return Permanent(err)
+	}
+
+	projectID := settings["fcm_project_id"]
+	if projectID == "" {
+		projectID = account.ProjectID
+	}
+	endpoint := fmt.Sprintf("%s/v1/projects/%s/messages:send", s.baseURL, url.PathEscape(projectID))
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Permanent(err)
+	}
+	request.Header.Set("Authorization", "Bearer "+accessToken)
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		s.tokens.drop(fcmTokenKey(account))
+	}
+	return fcmError(resp.StatusCode, respBody)
+}
+
+// check obtains an access token with the service account.
+func (s *FCMSender) check(ctx context.Context, settings Settings) error {
+	account, err := s.serviceAccount(settings)
+	if err != nil {
+		return err
+	}
+	_, err = s.accessToken(ctx, account)
+	return err
+}
+
+// serviceAccount parses the service account key of the settings.
+func (s *FCMSender) serviceAccount(settings Settings) (*fcmServiceAccount, error) {
+	value, err := settings.Require("fcm_service_account")
+	if err != nil {
+		return nil, err
+	}
+	var account fcmServiceAccount
+	if err := json.Unmarshal([]byte(value), &account); err != nil {
+		return nil, Permanent(fmt.Errorf("setting \"fcm_service_account\" must hold a service account key JSON: %w", err))
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, Permanent(fmt.Errorf("setting \"fcm_service_account\" lacks client_email or private_key"))
+	}
+	if account.ProjectID == "" && settings["fcm_project_id"] == "" {
+		return nil, Permanent(fmt.Errorf("setting \"fcm_project_id\" is not configured"))
+	}
+	return &account, nil
+}
+
+func fcmTokenKey(account *fcmServiceAccount) string {
+	return credentialKey("fcm", account.ClientEmail, account.PrivateKey)
+}
+
+// accessToken returns an OAuth 2.0 access token of the service account, exchanging a
+// signed JWT assertion for a new one when no cached token is left.
+func (s *FCMSender) accessToken(ctx context.Context, account *fcmServiceAccount) (string, error) {
+	return s.tokens.get(fcmTokenKey(account), func() (string, time.Time, error) {
+		key, err := parsePrivateKey("fcm_service_account", account.PrivateKey)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		now := time.Now()
+		header := map[string]interface{}{}
+		if account.PrivateKeyID != "" {
+			header["kid"] = account.PrivateKeyID
+		}
+		assertion, err := signJWT(key, header, map[string]interface{}{
+			"iss":   account.ClientEmail,
+			"scope": fcmScope,
+			"aud":   s.tokenURL,
+			"iat":   now.Unix(),
+			"exp":   now.Add(time.Hour).Unix(),
+		})
+		if err != nil {
+			return "", time.Time{}, err
+		}
+
+		form := url.Values{"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"}, "assertion": {assertion}}
+		request, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+		if err != nil {
+			return "", time.Time{}, Permanent(err)
+		}
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := s.client.Do(request)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		defer resp.Body.Close()
+
+		var token struct {
+			AccessToken      string `json:"access_token"`
+			ExpiresIn        int    `json:"expires_in"`
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&token)
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+			return "", time.Time{}, fmt.Errorf("token endpoint responded with status %d", resp.StatusCode)
+		case resp.StatusCode != http.StatusOK || token.AccessToken == "":
+			return "", time.Time{}, Permanent(fmt.Errorf("service account rejected: %s %s", token.Error, token.ErrorDescription))
+		}
+		return token.AccessToken, now.Add(time.Duration(token.ExpiresIn) * time.Second), nil
+	})
+}
+
+// fcmError converts an FCM error response, classifying it by its FCM error code.
+func fcmError(status int, body []byte) error {
+	var apiErr struct {
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	_ = json.Unmarshal(body, &apiErr)
+	code := apiErr.Error.Status
+	for _, detail := range apiErr.Error.Details {
+		if detail.ErrorCode != "" {
+			code = detail.ErrorCode
+		}
+	}
+	err := &StatusError{StatusCode: status, Err: fmt.Errorf("fcm responded with status %d: %s %s", status, code, apiErr.Error.Message)}
+
+	switch {
+	case code == fcmInvalidTokenCode:
+		return fmt.Errorf("%w: %w", errInvalidToken, err)
+	case status == http.StatusUnauthorized, status == http.StatusTooManyRequests, status >= 500:
+		return err
+	default:
+		return Permanent(err)
+	}
+}
@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"tenant-management-service/internal/model"
+	"testing"
+)
+
+// fcmSettings returns push settings holding a freshly generated service account key.
+func fcmSettings(t *testing.T) Settings {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	account, err := json.Marshal(fcmServiceAccount{
+		ProjectID:   "test-project",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		ClientEmail: "push@test-project.iam.gserviceaccount.com",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Settings{"fcm_service_account": string(account)}
+}
+
+// fakeFCM serves the OAuth token and message endpoints, answering sends with the given
+// status and body, and counts the access tokens it handed out.
+func fakeFCM(t *testing.T, status int, body string, tokens *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			if r.FormValue("assertion") == "" {
+				t.Error("token request without assertion")
+			}
+			*tokens++
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token": "access", "expires_in": 3600}`))
+		case "/v1/projects/test-project/messages:send":
+			if got := r.Header.Get("Authorization"); got != "Bearer access" {
+				t.Errorf("authorization = %q", got)
+			}
+			w.WriteHeader(status)
+			w.Write([]byte(body))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func fcmErrorBody(status, code string) string {
+	return `{"error": {"status": "` + status + `", "message": "failed", "details": [` +
+		`{"@type": "type.googleapis.com/google.firebase.fcm.v1.FcmError", "errorCode": "` + code + `"}]}}`
+}
+
+func TestFCMSenderSend(t *testing.T) {
+	tokens := 0
+	server := fakeFCM(t, http.StatusOK, `{"name": "projects/test-project/messages/1"}`, &tokens)
+	defer server.Close()
+
+	sender := NewFCMSender(server.URL, server.URL+"/token")
+	settings := fcmSettings(t)
+	device := &model.DeviceToken{Platform: model.DevicePlatformFCM, Token: "device-token"}
+	notification := &model.Notification{Channel: "push", Subject: "Hello", Body: "World"}
+	for i := 0; i < 2; i++ {
+		if err := sender.send(context.Background(), settings, device, notification); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+	if tokens != 1 {
+		t.Errorf("access tokens obtained = %d, want 1", tokens)
+	}
+}
+
+func TestFCMSenderErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		invalidToken bool
+		permanent    bool
+	}{
+		{"unregistered", http.StatusNotFound, fcmErrorBody("NOT_FOUND", "UNREGISTERED"), true, false},
+		{"sender id mismatch", http.StatusForbidden, fcmErrorBody("PERMISSION_DENIED", "SENDER_ID_MISMATCH"), false, true},
+		{"invalid argument", http.StatusBadRequest, fcmErrorBody("INVALID_ARGUMENT", "INVALID_ARGUMENT"), false, true},
+		{"quota exceeded", http.StatusTooManyRequests, fcmErrorBody("RESOURCE_EXHAUSTED", "QUOTA_EXCEEDED"), false, false},
+		{"unavailable", http.StatusServiceUnavailable, fcmErrorBody("UNAVAILABLE", "UNAVAILABLE"), false, false},
+		{"access token rejected", http.StatusUnauthorized, `{"error": {"status": "UNAUTHENTICATED", "message": "expired"}}`, false, false},
+		{"not json", http.StatusBadGateway, `<html>Bad Gateway</html>`, false, false},
+	}
+	settings := fcmSettings(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := 0
+			server := fakeFCM(t, tt.status, tt.body, &tokens)
+			defer server.Close()
+
+			sender := NewFCMSender(server.URL, server.URL+"/token")
+			device := &model.DeviceToken{Platform: model.DevicePlatformFCM, Token: "device-token"}
+			err := sender.send(context.Background(), settings, device, &model.Notification{Channel: "push", Body: "Hi"})
+			if err == nil {
+				t.Fatal("send succeeded")
+			}
+			if got := errors.Is(err, errInvalidToken); got != tt.invalidToken {
+				t.Errorf("invalid token = %v, want %v (%v)", got, tt.invalidToken, err)
+			}
+			if got := IsPermanent(err); got != tt.permanent {
+				t.Errorf("permanent = %v, want %v (%v)", got, tt.permanent, err)
+			}
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.status {
+				t.Errorf("status error = %v, want status %d", statusErr, tt.status)
+			}
+
+			// Rejected access tokens are dropped, so the next send obtains a new one
+			_ = sender.send(context.Background(), settings, device, &model.Notification{Channel: "push", Body: "Hi"})
+			wantTokens := 1
+			if tt.status == http.StatusUnauthorized {
+				wantTokens = 2
+			}
+			if tokens != wantTokens {
+				t.Errorf("access tokens obtained = %d, want %d", tokens, wantTokens)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"tenant-management-service/internal/model"
+	"time"
+)
+
+// SettingProvider is the channel setting selecting the provider of a channel, e.g.
+// "channels.email.provider". Channels without it use their default provider.
+const SettingProvider = "provider"
+
+// Provider delivers notifications of a channel through an external service. Providers are
+// shared by all tenants; a tenant's credentials and options are passed with every call as
+// the settings of the channel.
+type Provider interface {
+	// Name identifies the provider in channel settings.
+	Name() string
+	// Capabilities describes what the provider can deliver.
+	Capabilities() Capabilities
+	// Send delivers a notification. Errors are retried unless marked permanent.
+	Send(ctx context.Context, settings Settings, notification *model.Notification) error
+	// Health checks that the provider can be reached with the given settings.
+	Health(ctx context.Context, settings Settings) error
+}
+
+// Capabilities describes what a provider can deliver. MaxBodyLength is in characters;
+// zero means unlimited.
+type Capabilities struct {
+	Subject       bool `json:"subject"`
+	MaxBodyLength int  `json:"max_body_length,omitempty"`
+}
+
+// HealthReport is the outcome of a provider health check for a tenant's channel.
+type HealthReport struct {
+	Channel      string       `json:"channel"`
+	Provider     string       `json:"provider"`
+	Capabilities Capabilities `json:"capabilities"`
+	Healthy      bool         `json:"healthy"`
+	Error        string       `json:"error,omitempty"`
+	CheckedAt    time.Time    `json:"checked_at"`
+}
+
+// Settings are a tenant's settings of one channel, keyed by the part of the configuration
+// key after "channels.<code>.", e.g. "host" for "channels.email.host".
+type Settings map[string]string
+
+// NewSettings collects the settings of a channel from a tenant's configurations. Settings
+// with a structured value, such as key files too large for a plain value, hold the JSON
+// text of the value, or the string itself if the value is a JSON string.
+func NewSettings(configs []model.Configuration, channel string) Settings {
+	prefix := model.ChannelConfigPrefix + channel + "."
+	settings := Settings{}
+	for _, config := range configs {
+		key, found := strings.CutPrefix(config.ConfigKey, prefix)
+		if !found {
+			continue
+		}
+		value := config.ConfigValue
+		if value == "" && len(config.JSONValue) > 0 {
+			if json.Unmarshal(config.JSONValue, &value) != nil {
+				value = string(config.JSONValue)
+			}
+		}
+		settings[key] = strings.TrimSpace(value)
+	}
+	return settings
+}
+
+// Require returns the value of a setting, failing permanently if it is not set.
+func (s Settings) Require(key string) (string, error) {
+	if s[key] == "" {
+		return "", Permanent(fmt.Errorf("setting %q is not configured", key))
+	}
+	return s[key], nil
+}
+
+// Int returns the value of a numeric setting, or def if it is not set.
+func (s Settings) Int(key string, def int) (int, error) {
+	if s[key] == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(s[key])
+	if err != nil {
+		return 0, Permanent(fmt.Errorf("setting %q must be a number", key))
+	}
+	return n, nil
+}
+
+// PermanentError marks a delivery error that retrying cannot fix, such as a rejected
+// recipient or missing credentials.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent marks err as permanent.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent reports whether err is marked permanent.
+func IsPermanent(err error) bool {
+	var permanentErr *PermanentError
+	return errors.As(err, &permanentErr)
+}
+
+// StatusError is an error response of a provider reached over HTTP.
+type StatusError struct {
+	StatusCode int
+	Err        error
+}
+
+func (e *StatusError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// Registry holds the providers of each channel. The first provider registered for a
+// channel is its default.
+type Registry struct {
+	providers map[string][]Provider
+}
+
+func NewRegistry() *Registry {
+	return &Registry{providers: make(map[string][]Provider)}
+}
+
+// Register adds a provider for a channel.
+func (r *Registry) Register(channel string, provider Provider) {
+	r.providers[channel] = append(r.providers[channel], provider)
+}
+
+// Select returns the provider a tenant selected for a channel with the provider setting,
+// or the channel's default provider. Channels without a matching provider fail permanently.
+func (r *Registry) Select(channel string, settings Settings) (Provider, error) {
+	providers := r.providers[channel]
+	if len(providers) == 0 {
+		return nil, Permanent(fmt.Errorf("no provider for channel %q", channel))
+	}
+	name := strings.ToLower(settings[SettingProvider])
+	if name == "" {
+		return providers[0], nil
+	}
+	for _, provider := range providers {
+		if provider.Name() == name {
+			return provider, nil
+		}
+	}
+	return nil, Permanent(fmt.Errorf("unknown provider %q for channel %q", name, channel))
+}
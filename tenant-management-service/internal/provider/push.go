@@ -0,0 +1,255 @@
+package provider
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+	"tenant-management-service/internal/model"
+	"tenant-management-service/internal/repository"
+	"tenant-management-service/pkg/logger"
+	"time"
+)
+
+// errInvalidToken marks push errors caused by a device token the push service no longer
+// accepts. Such tokens are pruned.
+var errInvalidToken = errors.New("device token is invalid")
+
+// pushSender delivers push notifications to the devices of one platform.
+type pushSender interface {
+	// configured reports whether the settings hold credentials for the platform.
+	configured(settings Settings) bool
+	// send pushes a notification to a device, wrapping errInvalidToken if the token is invalid.
+	send(ctx context.Context, settings Settings, device *model.DeviceToken, notification *model.Notification) error
+	// check verifies the credentials of the settings.
+	check(ctx context.Context, settings Settings) error
+}
+
+// PushProvider delivers notifications of the push channel to every device registered for
+// the recipient, through FCM or APNs depending on the platform of the device. Tokens the
+// push service reports as invalid are removed.
+type PushProvider struct {
+	devices *repository.DeviceRepository
+	senders map[string]pushSender
+}
+
+func NewPushProvider(devices *repository.DeviceRepository, fcm *FCMSender, apns *APNsSender) *PushProvider {
+	return &PushProvider{
+		devices: devices,
+		senders: map[string]pushSender{
+			model.DevicePlatformFCM:  fcm,
+			model.DevicePlatformAPNs: apns,
+		},
+	}
+}
+
+func (p *PushProvider) Name() string {
+	return "push"
+}
+
+func (p *PushProvider) Capabilities() Capabilities {
+	return Capabilities{Subject: true, MaxBodyLength: 2000}
+}
+
+// Send pushes a notification to the recipient's devices. It succeeds if any device was
+// reached; otherwise it is retried if any device failed for a reason that may clear up.
+func (p *PushProvider) Send(ctx context.Context, settings Settings, notification *model.Notification) error {
+	devices, err := p.devices.FindByRecipient(notification.TenantID, notification.Recipient)
+	if err != nil {
+		return err
+	}
+	if len(devices) == 0 {
+		return Permanent(fmt.Errorf("no devices are registered for recipient %q", notification.Recipient))
+	}
+
+	var delivered, invalid []uint
+	var retryErr, permanentErr error
+	for i := range devices {
+		device := &devices[i]
+		err := p.senders[device.Platform].send(ctx, settings, device, notification)
+		switch {
+		case err == nil:
+			delivered = append(delivered, device.ID)
+		case errors.Is(err, errInvalidToken):
+			invalid = append(invalid, device.ID)
+			permanentErr = err
+		case IsPermanent(err):
+			permanentErr = err
+		default:
+			retryErr = err
+		}
+		if err != nil {
+			logger.Info("Push to device failed", zap.String("notification_id", notification.ID), zap.Uint("device_id", device.ID),
+				zap.String("platform", device.Platform), zap.Error(err))
+		}
+	}
+
+	if err := p.devices.DeleteByIDs(invalid); err != nil {
+		logger.Error("Error pruning invalid device tokens", zap.Error(err))
+	}
+	if err := p.devices.MarkUsed(delivered, time.Now()); err != nil {
+		logger.Error("Error recording device use", zap.Error(err))
+	}
+
+	switch {
+	case len(delivered) > 0:
+		return nil
+	case retryErr != nil:
+		return retryErr
+	default:
+		return Permanent(permanentErr)
+	}
+}
+
+// Health checks the credentials of every platform configured in the settings.
+func (p *PushProvider) Health(ctx context.Context, settings Settings) error {
+	checked := false
+	for _, platform := range model.DevicePlatforms {
+		sender := p.senders[platform]
+		if !sender.configured(settings) {
+			continue
+		}
+		checked = true
+		if err := sender.check(ctx, settings); err != nil {
+			return fmt.Errorf("%s: %w", platform, err)
+		}
+	}
+	if !checked {
+		return Permanent(errors.New("no push credentials are configured"))
+	}
+	return nil
+}
+
+// pushTokenCache caches the access tokens of push services. Tokens are keyed by a hash of
+// the credentials they were obtained with, so a tenant can only reuse tokens of its own
+// credentials.
+type pushTokenCache struct {
+	mu     sync.Mutex
+	tokens map[string]cachedPushToken
+}
+
+type cachedPushToken struct {
+	value     string
+	expiresAt time.Time
+}
+
+func newPushTokenCache() *pushTokenCache {
+	return &pushTokenCache{tokens: make(map[string]cachedPushToken)}
+}
+
+// get returns the token cached for key, obtaining a new one with fetch if there is none or
+// it expires within a minute.
+func (c *pushTokenCache) get(key string, fetch func() (string, time.Time, error)) (string, error) {
+	c.mu.Lock()
+	token, ok := c.tokens[key]
+	c.mu.Unlock()
+	if ok && time.Until(token.expiresAt) > time.Minute {
+		return token.value, nil
+	}
+
+	value, expiresAt, err := fetch()
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.tokens[key] = cachedPushToken{value: value, expiresAt: expiresAt}
+	c.mu.Unlock()
+	return value, nil
+}
+
+// drop removes the token cached for key, e.g. after the push service rejected it.
+func (c *pushTokenCache) drop(key string) {
+	c.mu.Lock()
+	delete(c.tokens, key)
+	c.mu.Unlock()
+}
+
+// credentialKey derives a cache key from credentials.
+func credentialKey(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// parsePrivateKey parses a PEM encoded PKCS #8 private key.
+func parsePrivateKey(setting, keyPEM string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(keyPEM))
+	if block == nil {
+		return nil, Permanent(fmt.Errorf("setting %q must hold a PEM encoded private key", setting))
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, Permanent(fmt.Errorf("setting %q: %w", setting, err))
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, Permanent(fmt.Errorf("setting %q holds an unsupported key type", setting))
+	}
+	return signer, nil
+}
+
+// signJWT encodes and signs a JSON Web Token with an RSA (RS256) or P-256 (ES256) key.
+func signJWT(key crypto.Signer, header, claims map[string]interface{}) (string, error) {
+	switch key.(type) {
+	case *rsa.PrivateKey:
+		header["alg"] = "RS256"
+	case *ecdsa.PrivateKey:
+		header["alg"] = "ES256"
+	default:
+		return "", Permanent(errors.New("unsupported signing key type"))
+	}
+	header["typ"] = "JWT"
+
+	var parts []string
+	for _, part := range []map[string]interface{}{header, claims} {
+		encoded, err := json.Marshal(part)
+		if err != nil {
+			return "", Permanent(err)
+		}
+		parts = append(parts, base64.RawURLEncoding.EncodeToString(encoded))
+	}
+	signingInput := strings.Join(parts, ".")
+	digest := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:]); err != nil {
+			return "", Permanent(err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		if err != nil {
+			return "", Permanent(err)
+		}
+		// JWS encodes ECDSA signatures as the fixed-size concatenation of r and s
+		size := (k.Curve.Params().BitSize + 7) / 8
+		signature = make([]byte, 2*size)
+		r.FillBytes(signature[:size])
+		s.FillBytes(signature[size:])
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// pushMetadata decodes the metadata of a notification.
+func pushMetadata(notification *model.Notification) (map[string]string, error) {
+	metadata := map[string]string{}
+	if len(notification.Metadata) > 0 {
+		if err := json.Unmarshal(notification.Metadata, &metadata); err != nil {
+			return nil, Permanent(fmt.Errorf("invalid metadata: %w", err))
+		}
+	}
+	return metadata, nil
+}
@@ -0,0 +1,225 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"tenant-management-service/internal/model"
+	"time"
+)
+
+const (
+	// SMTPTLSStartTLS upgrades the connection with STARTTLS and fails if the server does
+	// not offer it, rather than sending credentials and messages unencrypted.
+	SMTPTLSStartTLS = "starttls"
+	// SMTPTLSImplicit connects over TLS, usually on port 465.
+	SMTPTLSImplicit = "tls"
+	// SMTPTLSNone never encrypts the connection, e.g. for a local SMTP server.
+	SMTPTLSNone = "none"
+)
+
+// SMTPProvider sends email through the SMTP server configured by the tenant with the
+// settings of the email channel:
+//
+//	host          SMTP server host name (required)
+//	port          SMTP server port, 587 by default
+//	username      user name for PLAIN authentication, none by default
+//	password      password for PLAIN authentication
+//	from_address  sender address (required)
+//	from_name     sender display name
+//	tls           starttls (default), tls or none
+type SMTPProvider struct{}
+
+func NewSMTPProvider() *SMTPProvider {
+	return &SMTPProvider{}
+}
+
+func (p *SMTPProvider) Name() string {
+	return "smtp"
+}
+
+func (p *SMTPProvider) Capabilities() Capabilities {
+	return Capabilities{Subject: true}
+}
+
+// Send mails a notification as a plain text message. Replies of the server with 5xx codes
+// fail permanently; 4xx replies and connection problems are retried.
+func (p *SMTPProvider) Send(ctx context.Context, settings Settings, notification *model.Notification) error {
+	from, err := p.sender(settings)
+	if err != nil {
+		return err
+	}
+	to, err := mail.ParseAddress(notification.Recipient)
+	if err != nil {
+		return Permanent(fmt.Errorf("invalid recipient address: %w", err))
+	}
+
+	client, err := p.connect(ctx, settings)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	message, err := smtpMessage(from, to, notification, client.host)
+	if err != nil {
+		return Permanent(err)
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return smtpError(err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return smtpError(err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return smtpError(err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return smtpError(err)
+	}
+	if err := w.Close(); err != nil {
+		return smtpError(err)
+	}
+	return smtpError(client.Quit())
+}
+
+// Health connects and authenticates to the SMTP server and checks that it responds.
+func (p *SMTPProvider) Health(ctx context.Context, settings Settings) error {
+	if _, err := p.sender(settings); err != nil {
+		return err
+	}
+	client, err := p.connect(ctx, settings)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if err := client.Noop(); err != nil {
+		return smtpError(err)
+	}
+	return smtpError(client.Quit())
+}
+
+// sender parses the sender address of the settings.
+func (p *SMTPProvider) sender(settings Settings) (*mail.Address, error) {
+	address, err := settings.Require("from_address")
+	if err != nil {
+		return nil, err
+	}
+	from, err := mail.ParseAddress(address)
+	if err != nil {
+		return nil, Permanent(fmt.Errorf("invalid setting \"from_address\": %w", err))
+	}
+	if name := settings["from_name"]; name != "" {
+		from.Name = name
+	}
+	return from, nil
+}
+
+// smtpClient is an SMTP client along with the host it is connected to.
+type smtpClient struct {
+	*smtp.Client
+	host string
+}
+
+// connect opens an SMTP session with the server of the settings, secured as configured and
+// authenticated if credentials are set. The connection is bound to the deadline of ctx.
+func (p *SMTPProvider) connect(ctx context.Context, settings Settings) (*smtpClient, error) {
+	host, err := settings.Require("host")
+	if err != nil {
+		return nil, err
+	}
+	port, err := settings.Int("port", 587)
+	if err != nil {
+		return nil, err
+	}
+	mode := strings.ToLower(settings["tls"])
+	if mode == "" {
+		mode = SMTPTLSStartTLS
+	}
+	if mode != SMTPTLSStartTLS && mode != SMTPTLSImplicit && mode != SMTPTLSNone {
+		return nil, Permanent(fmt.Errorf("setting \"tls\" must be %s, %s or %s", SMTPTLSStartTLS, SMTPTLSImplicit, SMTPTLSNone))
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	tlsConfig := &tls.Config{ServerName: host}
+	if mode == SMTPTLSImplicit {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return nil, smtpError(err)
+	}
+	if mode == SMTPTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, Permanent(fmt.Errorf("SMTP server does not offer STARTTLS, set \"tls\" to %s to send unencrypted", SMTPTLSNone))
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, smtpError(err)
+		}
+	}
+	if username := settings["username"]; username != "" {
+		if err := client.Auth(smtp.PlainAuth("", username, settings["password"], host)); err != nil {
+			client.Close()
+			return nil, smtpError(err)
+		}
+	}
+	return &smtpClient{Client: client, host: host}, nil
+}
+
+// smtpMessage formats a notification as a MIME message with a quoted-printable text body.
+func smtpMessage(from, to *mail.Address, notification *model.Notification, host string) ([]byte, error) {
+	var message bytes.Buffer
+	header := func(name, value string) {
+		message.WriteString(name + ": " + value + "\r\n")
+	}
+	header("From", from.String())
+	header("To", to.String())
+	if notification.Subject != "" {
+		header("Subject", mime.QEncoding.Encode("utf-8", notification.Subject))
+	}
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", "<"+notification.ID+"@"+host+">")
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=UTF-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	message.WriteString("\r\n")
+
+	body := quotedprintable.NewWriter(&message)
+	if _, err := body.Write([]byte(notification.Body)); err != nil {
+		return nil, err
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return message.Bytes(), nil
+}
+
+// smtpError marks errors of permanent SMTP replies, those with 5xx codes, as permanent.
+func smtpError(err error) error {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) && protoErr.Code >= 500 {
+		return Permanent(err)
+	}
+	return err
+}
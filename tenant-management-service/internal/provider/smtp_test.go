@@ -0,0 +1,257 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"tenant-management-service/internal/model"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer is an SMTP server accepting one session at a time that records what it
+// receives. Replies overrides the reply to commands by verb, e.g. "RCPT".
+type fakeSMTPServer struct {
+	listener   net.Listener
+	extensions []string
+	replies    map[string]string
+
+	mu   sync.Mutex
+	auth string
+	from string
+	to   []string
+	data string
+}
+
+func newFakeSMTPServer(t *testing.T, extensions ...string) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	server := &fakeSMTPServer{listener: listener, extensions: extensions, replies: map[string]string{}}
+	t.Cleanup(func() { listener.Close() })
+	go server.serve()
+	return server
+}
+
+func (s *fakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.session(textproto.NewConn(conn))
+	}
+}
+
+func (s *fakeSMTPServer) session(conn *textproto.Conn) {
+	defer conn.Close()
+	reply := func(verb, def string) {
+		if override, ok := s.replies[verb]; ok {
+			def = override
+		}
+		conn.PrintfLine("%s", def)
+	}
+
+	conn.PrintfLine("220 fake.test ESMTP")
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			for _, extension := range s.extensions {
+				conn.PrintfLine("250-%s", extension)
+			}
+			conn.PrintfLine("250 fake.test")
+		case "AUTH":
+			s.mu.Lock()
+			s.auth = arg
+			s.mu.Unlock()
+			reply("AUTH", "235 2.7.0 Authentication successful")
+		case "MAIL":
+			s.mu.Lock()
+			s.from = arg
+			s.mu.Unlock()
+			reply("MAIL", "250 2.1.0 OK")
+		case "RCPT":
+			s.mu.Lock()
+			s.to = append(s.to, arg)
+			s.mu.Unlock()
+			reply("RCPT", "250 2.1.5 OK")
+		case "DATA":
+			if override, ok := s.replies["DATA"]; ok {
+				conn.PrintfLine("%s", override)
+				continue
+			}
+			conn.PrintfLine("354 Go ahead")
+			data, err := io.ReadAll(conn.DotReader())
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.data = string(data)
+			s.mu.Unlock()
+			reply("DOT", "250 2.0.0 Queued")
+		case "NOOP", "RSET":
+			conn.PrintfLine("250 2.0.0 OK")
+		case "QUIT":
+			conn.PrintfLine("221 2.0.0 Bye")
+			return
+		default:
+			conn.PrintfLine("502 5.5.2 Command not recognized")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) settings(tls string) Settings {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return Settings{
+		"host":         "127.0.0.1",
+		"port":         port,
+		"from_address": "alerts@example.com",
+		"from_name":    "Alerts",
+		"tls":          tls,
+	}
+}
+
+func testEmail() *model.Notification {
+	return &model.Notification{
+		ID:        "0b7d3c2e-1f00-4a55-9c3e-6f1d2a3b4c5d",
+		Channel:   "email",
+		Recipient: "Jane Doe <jane@example.com>",
+		Subject:   "Grüße",
+		Body:      "Hello Jane,\nyour report is ready: 100% done.",
+	}
+}
+
+func TestSMTPProviderSend(t *testing.T) {
+	server := newFakeSMTPServer(t, "AUTH PLAIN")
+	settings := server.settings(SMTPTLSNone)
+	settings["username"] = "user"
+	settings["password"] = "secret"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := NewSMTPProvider().Send(ctx, settings, testEmail()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	wantAuth := "PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00user\x00secret"))
+	if server.auth != wantAuth {
+		t.Errorf("AUTH %q, want %q", server.auth, wantAuth)
+	}
+	if server.from != "FROM:<alerts@example.com>" {
+		t.Errorf("MAIL %q", server.from)
+	}
+	if len(server.to) != 1 || server.to[0] != "TO:<jane@example.com>" {
+		t.Errorf("RCPT %q", server.to)
+	}
+
+	message, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(server.data)))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	headers := map[string]string{
+		"From":                      `"Alerts" <alerts@example.com>`,
+		"To":                        `"Jane Doe" <jane@example.com>`,
+		"Subject":                   "=?utf-8?q?Gr=C3=BC=C3=9Fe?=",
+		"Message-Id":                "<0b7d3c2e-1f00-4a55-9c3e-6f1d2a3b4c5d@127.0.0.1>",
+		"Mime-Version":              "1.0",
+		"Content-Type":              "text/plain; charset=UTF-8",
+		"Content-Transfer-Encoding": "quoted-printable",
+	}
+	for name, want := range headers {
+		if got := message.Header.Get(name); got != want {
+			t.Errorf("header %s = %q, want %q", name, got, want)
+		}
+	}
+	if _, err := message.Header.Date(); err != nil {
+		t.Errorf("header Date: %v", err)
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(message.Body))
+	if err != nil {
+		t.Fatalf("reading body: %v", err)
+	}
+	// The data reader of the server turns line endings into "\n"
+	if want := "Hello Jane,\nyour report is ready: 100% done."; strings.TrimSuffix(string(body), "\n") != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}
+
+func TestSMTPProviderErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		tls       string
+		replies   map[string]string
+		settings  Settings
+		permanent bool
+	}{
+		{name: "transient recipient rejection", tls: SMTPTLSNone, replies: map[string]string{"RCPT": "450 4.2.1 Mailbox busy"}},
+		{name: "permanent recipient rejection", tls: SMTPTLSNone, replies: map[string]string{"RCPT": "550 5.1.1 No such user"}, permanent: true},
+		{name: "transient sender rejection", tls: SMTPTLSNone, replies: map[string]string{"MAIL": "421 4.3.2 Try again later"}},
+		{name: "permanent message rejection", tls: SMTPTLSNone, replies: map[string]string{"DOT": "554 5.7.1 Message rejected"}, permanent: true},
+		{name: "transient message rejection", tls: SMTPTLSNone, replies: map[string]string{"DOT": "452 4.3.1 Insufficient storage"}},
+		{name: "authentication failure", tls: SMTPTLSNone, replies: map[string]string{"AUTH": "535 5.7.8 Bad credentials"},
+			settings: Settings{"username": "user", "password": "wrong"}, permanent: true},
+		{name: "STARTTLS not offered", tls: SMTPTLSStartTLS, permanent: true},
+		{name: "unknown TLS mode", tls: "ssl", permanent: true},
+		{name: "missing sender", tls: SMTPTLSNone, settings: Settings{"from_address": ""}, permanent: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSMTPServer(t, "AUTH PLAIN")
+			server.replies = tt.replies
+			settings := server.settings(tt.tls)
+			for key, value := range tt.settings {
+				settings[key] = value
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			err := NewSMTPProvider().Send(ctx, settings, testEmail())
+			if err == nil {
+				t.Fatal("Send succeeded, want an error")
+			}
+			if IsPermanent(err) != tt.permanent {
+				t.Errorf("IsPermanent(%v) = %v, want %v", err, IsPermanent(err), tt.permanent)
+			}
+		})
+	}
+}
+
+func TestSMTPProviderRejectsInvalidRecipient(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	notification := testEmail()
+	notification.Recipient = "not an address"
+	err := NewSMTPProvider().Send(context.Background(), server.settings(SMTPTLSNone), notification)
+	if !IsPermanent(err) {
+		t.Errorf("Send = %v, want a permanent error", err)
+	}
+}
+
+func TestSMTPProviderHealth(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	if err := NewSMTPProvider().Health(context.Background(), server.settings(SMTPTLSNone)); err != nil {
+		t.Errorf("Health: %v", err)
+	}
+
+	closed := server.settings(SMTPTLSNone)
+	server.listener.Close()
+	err := NewSMTPProvider().Health(context.Background(), closed)
+	if err == nil || IsPermanent(err) {
+		t.Errorf("Health of an unreachable server = %v, want a transient error", err)
+	}
+}
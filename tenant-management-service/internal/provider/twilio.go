@@ -0,0 +1,227 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"tenant-management-service/internal/model"
+)
+
+// MetadataSenderID is the notification metadata entry selecting one of the tenant's
+// alphanumeric sender IDs for an SMS.
+const MetadataSenderID = "sender_id"
+
+var (
+	// e164Number matches phone numbers in E.164 format.
+	e164Number = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+	// alphanumericSenderID matches sender IDs carriers accept in place of a number.
+	alphanumericSenderID = regexp.MustCompile(`^[A-Za-z0-9 ]{1,11}$`)
+)
+
+// twilioPermanentCodes are error codes of the Twilio API that fail a message for good,
+// such as invalid or unsubscribed recipients, invalid senders and rejected credentials.
+var twilioPermanentCodes = map[int]bool{
+	20003: true, // authentication failed
+	20404: true, // account or resource not found
+	21211: true, // invalid To number
+	21212: true, // invalid From number
+	21408: true, // region not enabled for the account
+	21602: true, // message body is required
+	21606: true, // From number cannot send messages
+	21610: true, // recipient unsubscribed
+	21612: true, // recipient unreachable from the From number
+	21614: true, // To number is not a mobile number
+	21617: true, // body exceeds the length limit
+	21659: true, // From number is not a Twilio number
+}
+
+// twilioRetryableCodes are error codes of the Twilio API that clear up on their own, such
+// as rate limiting, full queues and internal errors.
+var twilioRetryableCodes = map[int]bool{
+	20429: true, // too many requests
+	20500: true, // internal server error
+	20503: true, // service unavailable
+	30001: true, // queue overflow
+	30008: true, // unknown error
+}
+
+// TwilioProvider sends SMS through a Twilio-compatible REST API. The base URL is set by the
+// service so that a local fake can stand in for the API; credentials and senders are
+// settings of the sms channel:
+//
+//	account_sid            account to send with (required)
+//	auth_token             auth token of the account (required)
+//	from_number            sender phone number in E.164 format
+//	messaging_service_sid  messaging service choosing the sender instead of from_number
+//	sender_ids             comma-separated alphanumeric sender IDs, the first being the default
+//
+// The sender is the alphanumeric sender ID requested in the notification metadata, else the
+// default one, else the messaging service, else the phone number. Alphanumeric sender IDs
+// are skipped for North American recipients, where carriers do not support them.
+type TwilioProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewTwilioProvider(baseURL string) *TwilioProvider {
+	return &TwilioProvider{baseURL: strings.TrimSuffix(baseURL, "/"), client: &http.Client{}}
+}
+
+func (p *TwilioProvider) Name() string {
+	return "twilio"
+}
+
+func (p *TwilioProvider) Capabilities() Capabilities {
+	return Capabilities{MaxBodyLength: 1600}
+}
+
+// Send creates a message through the API. Errors are classified by their Twilio error
+// code, falling back to the HTTP status: 429 and 5xx responses are retried.
+func (p *TwilioProvider) Send(ctx context.Context, settings Settings, notification *model.Notification) error {
+	accountSID, authToken, err := twilioCredentials(settings)
+	if err != nil {
+		return err
+	}
+	if !e164Number.MatchString(notification.Recipient) {
+		return Permanent(errors.New("recipient must be a phone number in E.164 format"))
+	}
+
+	form := url.Values{"To": {notification.Recipient}, "Body": {notification.Body}}
+	senderKey, sender, err := twilioSender(settings, notification)
+	if err != nil {
+		return err
+	}
+	form.Set(senderKey, sender)
+
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s/Messages.json", p.baseURL, url.PathEscape(accountSID))
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Permanent(err)
+	}
+	request.SetBasicAuth(accountSID, authToken)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return p.do(request)
+}
+
+// Health fetches the account to check that the API is reachable and accepts the credentials.
+func (p *TwilioProvider) Health(ctx context.Context, settings Settings) error {
+	accountSID, authToken, err := twilioCredentials(settings)
+	if err != nil {
+		return err
+	}
+	endpoint := fmt.Sprintf("%s/2010-04-01/Accounts/%s.json", p.baseURL, url.PathEscape(accountSID))
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Permanent(err)
+	}
+	request.SetBasicAuth(accountSID, authToken)
+	return p.do(request)
+}
+
+// do sends an API request and converts error responses.
+func (p *TwilioProvider) do(request *http.Request) error {
+	request.Header.Set("Accept", "application/json")
+	resp, err := p.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return twilioError(resp.StatusCode, body)
+}
+
+// twilioCredentials returns the account credentials of the settings.
+func twilioCredentials(settings Settings) (string, string, error) {
+	accountSID, err := settings.Require("account_sid")
+	if err != nil {
+		return "", "", err
+	}
+	authToken, err := settings.Require("auth_token")
+	if err != nil {
+		return "", "", err
+	}
+	return accountSID, authToken, nil
+}
+
+// twilioSender selects the sender of an SMS and returns the form field it is passed in.
+func twilioSender(settings Settings, notification *model.Notification) (string, string, error) {
+	var senderIDs []string
+	for _, senderID := range strings.Split(settings["sender_ids"], ",") {
+		if senderID = strings.TrimSpace(senderID); senderID != "" {
+			if !alphanumericSenderID.MatchString(senderID) {
+				return "", "", Permanent(fmt.Errorf("sender ID %q must be 1 to 11 letters, digits or spaces", senderID))
+			}
+			senderIDs = append(senderIDs, senderID)
+		}
+	}
+
+	var metadata map[string]string
+	if len(notification.Metadata) > 0 {
+		if err := json.Unmarshal(notification.Metadata, &metadata); err != nil {
+			return "", "", Permanent(fmt.Errorf("invalid metadata: %w", err))
+		}
+	}
+	requested := metadata[MetadataSenderID]
+	if requested != "" {
+		found := false
+		for _, senderID := range senderIDs {
+			found = found || senderID == requested
+		}
+		if !found {
+			return "", "", Permanent(fmt.Errorf("sender ID %q is not configured", requested))
+		}
+	} else if len(senderIDs) > 0 {
+		requested = senderIDs[0]
+	}
+
+	northAmerican := strings.HasPrefix(notification.Recipient, "+1")
+	switch {
+	case requested != "" && !northAmerican:
+		return "From", requested, nil
+	case settings["messaging_service_sid"] != "":
+		return "MessagingServiceSid", settings["messaging_service_sid"], nil
+	case settings["from_number"] != "":
+		if !e164Number.MatchString(settings["from_number"]) {
+			return "", "", Permanent(errors.New("setting \"from_number\" must be a phone number in E.164 format"))
+		}
+		return "From", settings["from_number"], nil
+	default:
+		return "", "", Permanent(errors.New("no sender is configured for the recipient"))
+	}
+}
+
+// twilioError converts an error response, classifying it by error code and HTTP status.
+func twilioError(status int, body []byte) error {
+	var apiErr struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(body, &apiErr)
+	var err error = &StatusError{StatusCode: status, Err: fmt.Errorf("twilio responded with status %d", status)}
+	if apiErr.Code != 0 {
+		err = &StatusError{StatusCode: status, Err: fmt.Errorf("twilio error %d: %s", apiErr.Code, apiErr.Message)}
+	}
+
+	switch {
+	case twilioPermanentCodes[apiErr.Code]:
+		return Permanent(err)
+	case twilioRetryableCodes[apiErr.Code]:
+		return err
+	case status == http.StatusTooManyRequests, status >= 500:
+		return err
+	default:
+		return Permanent(err)
+	}
+}
@@ -0,0 +1,128 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"tenant-management-service/internal/model"
+	"testing"
+)
+
+func twilioSettings() Settings {
+	return Settings{"account_sid": "AC123", "auth_token": "token", "from_number": "+15005550006"}
+}
+
+func TestTwilioProviderSend(t *testing.T) {
+	var form map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2010-04-01/Accounts/AC123/Messages.json" {
+			t.Errorf("path = %q", r.URL.Path)
+		}
+		if user, password, ok := r.BasicAuth(); !ok || user != "AC123" || password != "token" {
+			t.Errorf("basic auth = %q, %q", user, password)
+		}
+		r.ParseForm()
+		form = map[string]string{"To": r.PostForm.Get("To"), "From": r.PostForm.Get("From"), "Body": r.PostForm.Get("Body")}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"sid": "SM123", "status": "queued"}`))
+	}))
+	defer server.Close()
+
+	notification := &model.Notification{Channel: "sms", Recipient: "+14155550100", Body: "Your code is 1234"}
+	if err := NewTwilioProvider(server.URL+"/").Send(context.Background(), twilioSettings(), notification); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	want := map[string]string{"To": "+14155550100", "From": "+15005550006", "Body": "Your code is 1234"}
+	for key, value := range want {
+		if form[key] != value {
+			t.Errorf("form %s = %q, want %q", key, form[key], value)
+		}
+	}
+}
+
+func TestTwilioProviderErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		permanent bool
+	}{
+		{"invalid recipient", http.StatusBadRequest, `{"code": 21211, "message": "Invalid 'To' Phone Number"}`, true},
+		{"unsubscribed recipient", http.StatusBadRequest, `{"code": 21610, "message": "Attempt to send to unsubscribed recipient"}`, true},
+		{"authentication failed", http.StatusUnauthorized, `{"code": 20003, "message": "Authenticate"}`, true},
+		{"rate limited by code", http.StatusBadRequest, `{"code": 20429, "message": "Too Many Requests"}`, false},
+		{"queue overflow", http.StatusBadRequest, `{"code": 30001, "message": "Queue overflow"}`, false},
+		{"rate limited by status", http.StatusTooManyRequests, ``, false},
+		{"server error", http.StatusBadGateway, `<html>Bad Gateway</html>`, false},
+		{"unknown client error", http.StatusBadRequest, `{"code": 99999, "message": "Something"}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			notification := &model.Notification{Channel: "sms", Recipient: "+14155550100", Body: "Hello"}
+			err := NewTwilioProvider(server.URL).Send(context.Background(), twilioSettings(), notification)
+			if err == nil {
+				t.Fatal("Send succeeded, want an error")
+			}
+			if IsPermanent(err) != tt.permanent {
+				t.Errorf("IsPermanent(%v) = %v, want %v", err, IsPermanent(err), tt.permanent)
+			}
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.status {
+				t.Errorf("error %v does not carry status %d", err, tt.status)
+			}
+		})
+	}
+}
+
+func TestTwilioSender(t *testing.T) {
+	tests := []struct {
+		name      string
+		settings  Settings
+		recipient string
+		metadata  string
+		key       string
+		sender    string
+		permanent bool
+	}{
+		{name: "phone number", settings: Settings{"from_number": "+15005550006"}, recipient: "+447700900123",
+			key: "From", sender: "+15005550006"},
+		{name: "messaging service before number", settings: Settings{"from_number": "+15005550006", "messaging_service_sid": "MG1"},
+			recipient: "+447700900123", key: "MessagingServiceSid", sender: "MG1"},
+		{name: "default sender ID", settings: Settings{"sender_ids": "ACME, Shop", "from_number": "+15005550006"},
+			recipient: "+447700900123", key: "From", sender: "ACME"},
+		{name: "requested sender ID", settings: Settings{"sender_ids": "ACME, Shop"}, recipient: "+447700900123",
+			metadata: `{"sender_id": "Shop"}`, key: "From", sender: "Shop"},
+		{name: "no sender IDs in North America", settings: Settings{"sender_ids": "ACME", "from_number": "+15005550006"},
+			recipient: "+14155550100", key: "From", sender: "+15005550006"},
+		{name: "unknown sender ID", settings: Settings{"sender_ids": "ACME"}, recipient: "+447700900123",
+			metadata: `{"sender_id": "Other"}`, permanent: true},
+		{name: "invalid sender ID", settings: Settings{"sender_ids": "Way too long ID"}, recipient: "+447700900123", permanent: true},
+		{name: "no sender", settings: Settings{"sender_ids": "ACME"}, recipient: "+14155550100", permanent: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notification := &model.Notification{Recipient: tt.recipient}
+			if tt.metadata != "" {
+				notification.Metadata = []byte(tt.metadata)
+			}
+			key, sender, err := twilioSender(tt.settings, notification)
+			if tt.permanent {
+				if !IsPermanent(err) {
+					t.Errorf("twilioSender = %q, %q, %v, want a permanent error", key, sender, err)
+				}
+				return
+			}
+			if err != nil || key != tt.key || sender != tt.sender {
+				t.Errorf("twilioSender = %q, %q, %v, want %q, %q", key, sender, err, tt.key, tt.sender)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"tenant-management-service/internal/model"
+	"tenant-management-service/internal/repository"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/safehttp"
+	"time"
+)
+
+// webhookPayload is the JSON body posted to webhook endpoints.
+type webhookPayload struct {
+	ID        string          `json:"id"`
+	Event     string          `json:"event"`
+	TenantID  string          `json:"tenant_id"`
+	Endpoint  string          `json:"endpoint"`
+	Subject   string          `json:"subject,omitempty"`
+	Body      string          `json:"body"`
+	Template  string          `json:"template,omitempty"`
+	Metadata  json.RawMessage `json:"metadata,omitempty"`
+	Attempt   int             `json:"attempt"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// WebhookProvider posts notifications as signed JSON to the webhook endpoint a tenant
+// registered under the name given as recipient. Requests time out after the configured
+// timeout, or earlier when the deadline of the delivery runs out. Endpoints are only
+// reached at public addresses and redirects are not followed.
+type WebhookProvider struct {
+	endpoints *repository.WebhookRepository
+	client    *http.Client
+}
+
+func NewWebhookProvider(endpoints *repository.WebhookRepository, timeout time.Duration) *WebhookProvider {
+	return &WebhookProvider{endpoints: endpoints, client: safehttp.NewClient(timeout)}
+}
+
+func (p *WebhookProvider) Name() string {
+	return "http"
+}
+
+func (p *WebhookProvider) Capabilities() Capabilities {
+	return Capabilities{Subject: true}
+}
+
+// Send posts a notification to its endpoint. Timeouts, connection errors, 408, 429 and
+// 5xx responses are retried; other responses outside 2xx, redirects included, fail
+// permanently, as do unknown and disabled endpoints and endpoints at addresses that are
+// not public.
+func (p *WebhookProvider) Send(ctx context.Context, _ Settings, notification *model.Notification) error {
+	endpoint, err := p.endpoints.FindByName(notification.TenantID, notification.Recipient)
+	if err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			return Permanent(fmt.Errorf("no webhook endpoint %q is registered", notification.Recipient))
+		}
+		return err
+	}
+	if !endpoint.Enabled {
+		return Permanent(fmt.Errorf("webhook endpoint %q is disabled", endpoint.Name))
+	}
+
+	payload, err := json.Marshal(webhookPayload{
+		ID:        notification.ID,
+		Event:     "notification",
+		TenantID:  notification.TenantID,
+		Endpoint:  endpoint.Name,
+		Subject:   notification.Subject,
+		Body:      notification.Body,
+		Template:  notification.Template,
+		Metadata:  notification.Metadata,
+		Attempt:   notification.Attempts,
+		CreatedAt: notification.CreatedAt,
+	})
+	if err != nil {
+		return Permanent(err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(payload))
+	if err != nil {
+		return Permanent(err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "tenant-management-service-webhooks")
+	request.Header.Set(model.WebhookIDHeader, notification.ID)
+	request.Header.Set(model.WebhookSignatureHeader, SignWebhook(endpoint.Secret, time.Now(), payload))
+
+	resp, err := p.client.Do(request)
+	if err != nil {
+		if errors.Is(err, safehttp.ErrForbiddenAddress) {
+			return Permanent(err)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	err = &StatusError{StatusCode: resp.StatusCode, Err: fmt.Errorf("webhook endpoint responded with status %d", resp.StatusCode)}
+	switch {
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return err
+	default:
+		return Permanent(err)
+	}
+}
+
+// Health reports no problems: endpoints are registered per recipient, so there is nothing
+// to check for the channel as a whole.
+func (p *WebhookProvider) Health(context.Context, Settings) error {
+	return nil
+}
+
+// SignWebhook computes the signature header value of a payload sent at t.
+func SignWebhook(secret string, t time.Time, payload []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
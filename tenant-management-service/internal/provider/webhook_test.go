@@ -0,0 +1,22 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSignWebhook(t *testing.T) {
+	// Computed independently as HMAC-SHA256 over "1700000000." followed by the payload
+	want := "t=1700000000,v1=177d5152af96b8954bde97dff8530d28a679f8f60c5bdd46d77cf9e28de6b46e"
+	got := SignWebhook("whsec_test", time.Unix(1700000000, 0), []byte(`{"id":"n1"}`))
+	if got != want {
+		t.Errorf("SignWebhook = %q, want %q", got, want)
+	}
+
+	if other := SignWebhook("whsec_other", time.Unix(1700000000, 0), []byte(`{"id":"n1"}`)); other == want {
+		t.Error("SignWebhook gave the same signature for a different secret")
+	}
+	if later := SignWebhook("whsec_test", time.Unix(1700000001, 0), []byte(`{"id":"n1"}`)); later == want {
+		t.Error("SignWebhook gave the same signature for a different timestamp")
+	}
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"tenant-management-service/internal/model"
+	"time"
+)
+
+type AlertRepository struct {
+	db *gorm.DB
+}
+
+func NewAlertRepository(db *gorm.DB) *AlertRepository {
+	return &AlertRepository{db: db}
+}
+
+// CreateIfAbsent inserts alerts that have not been raised for their window and threshold
+// yet and returns the inserted ones.
+func (r *AlertRepository) CreateIfAbsent(alerts []model.QuotaAlert) ([]model.QuotaAlert, error) {
+	var created []model.QuotaAlert
+	for i := range alerts {
+		result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&alerts[i])
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			created = append(created, alerts[i])
+		}
+	}
+	return created, nil
+}
+
+// FindPending retrieves the ids of up to limit alerts awaiting delivery that no worker holds.
+func (r *AlertRepository) FindPending(now time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&model.QuotaAlert{}).
+		Where("delivered_at IS NULL AND (locked_until IS NULL OR locked_until < ?)", now).
+		Order("id ASC").Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Claim leases an alert awaiting delivery to the caller until now plus lease. It returns
+// nil if the alert is not pending, for instance because another worker claimed it first.
+func (r *AlertRepository) Claim(id uint, now time.Time, lease time.Duration) (*model.QuotaAlert, error) {
+	result := r.db.Model(&model.QuotaAlert{}).
+		Where("id = ? AND delivered_at IS NULL AND (locked_until IS NULL OR locked_until < ?)", id, now).
+		Update("locked_until", now.Add(lease))
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+
+	var alert model.QuotaAlert
+	if err := r.db.First(&alert, id).Error; err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// UpdateDelivery records the outcome of delivering an alert.
+func (r *AlertRepository) UpdateDelivery(id uint, sinks, deliveryError string, at time.Time) error {
+	return r.db.Model(&model.QuotaAlert{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"sinks":          sinks,
+			"delivery_error": deliveryError,
+			"delivered_at":   at,
+			"locked_until":   nil,
+		}).Error
+}
+
+// FindByTenantID retrieves a page of a tenant's alerts, newest first, optionally restricted
+// to a channel, along with the total number of matching alerts.
+func (r *AlertRepository) FindByTenantID(tenantID, channel string, limit, offset int) ([]model.QuotaAlert, int64, error) {
+	query := r.db.Model(&model.QuotaAlert{}).Where("tenant_id = ?", tenantID)
+	if channel != "" {
+		query = query.Where("channel = ?", channel)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var alerts []model.QuotaAlert
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&alerts).Error; err != nil {
+		return nil, 0, err
+	}
+	return alerts, total, nil
+}
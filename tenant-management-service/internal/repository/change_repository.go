@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"tenant-management-service/internal/model"
+)
+
+type ChangeRepository struct {
+	db *gorm.DB
+}
+
+func NewChangeRepository(db *gorm.DB) *ChangeRepository {
+	return &ChangeRepository{db: db}
+}
+
+// Append numbers change events from the change sequence and stores them in a single
+// batch. It must run in the transaction making the changes: the sequence stays locked
+// until that transaction ends, so concurrent writers commit their revisions in order.
+func (r *ChangeRepository) Append(events []model.ChangeEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	var sequence model.ChangeSequence
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&sequence, model.ChangeSequenceID).Error; err != nil {
+		return err
+	}
+	for i := range events {
+		sequence.Revision++
+		events[i].ID = sequence.Revision
+	}
+	if err := r.db.Model(&sequence).Update("revision", sequence.Revision).Error; err != nil {
+		return err
+	}
+	return r.db.Create(&events).Error
+}
+
+// FindSince retrieves up to limit events newer than the given revision. An empty
+// tenantID matches events of every tenant.
+func (r *ChangeRepository) FindSince(tenantID string, since uint64, limit int) ([]model.ChangeEvent, error) {
+	var events []model.ChangeEvent
+	query := r.db.Where("id > ?", since)
+
+	// Filter by tenant if provided
+	if tenantID != "" {
+		query = query.Where("tenant_id = ?", tenantID)
+	}
+
+	if err := query.Order("id ASC").Limit(limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"tenant-management-service/internal/model"
+)
+
+type ChannelRepository struct {
+	db *gorm.DB
+}
+
+func NewChannelRepository(db *gorm.DB) *ChannelRepository {
+	return &ChannelRepository{db: db}
+}
+
+// Upsert creates or updates a channel identified by its code and replaces its tier overrides.
+func (r *ChannelRepository) Upsert(channel *model.Channel) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing model.Channel
+		err := tx.Where("code = ?", channel.Code).First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			channel.ID = existing.ID
+			channel.CreatedAt = existing.CreatedAt
+			if err := tx.Where("channel_id = ?", channel.ID).Delete(&model.ChannelTierOverride{}).Error; err != nil {
+				return err
+			}
+		}
+
+		// Save the channel together with its new tier overrides
+		return tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(channel).Error
+	})
+}
+
+// FindAll retrieves the whole catalog with tier overrides.
+func (r *ChannelRepository) FindAll() ([]model.Channel, error) {
+	var channels []model.Channel
+	if err := r.db.Preload("TierOverrides").Order("code ASC").Find(&channels).Error; err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"tenant-management-service/internal/model"
+	pkgerr "tenant-management-service/pkg/error"
+)
+
+type ConfigPresetRepository struct {
+	db *gorm.DB
+}
+
+func NewConfigPresetRepository(db *gorm.DB) *ConfigPresetRepository {
+	return &ConfigPresetRepository{db: db}
+}
+
+// Upsert creates or updates a preset identified by its name and replaces its entries.
+func (r *ConfigPresetRepository) Upsert(preset *model.ConfigPreset) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing model.ConfigPreset
+		err := tx.Where("name = ?", preset.Name).First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			preset.ID = existing.ID
+			preset.CreatedAt = existing.CreatedAt
+			if err := tx.Where("preset_id = ?", preset.ID).Delete(&model.ConfigPresetEntry{}).Error; err != nil {
+				return err
+			}
+		}
+
+		// Save the preset together with its new entries
+		return tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(preset).Error
+	})
+}
+
+// FindAll retrieves every preset with its entries.
+func (r *ConfigPresetRepository) FindAll() ([]model.ConfigPreset, error) {
+	var presets []model.ConfigPreset
+	if err := r.db.Preload("Entries").Order("name ASC").Find(&presets).Error; err != nil {
+		return nil, err
+	}
+	return presets, nil
+}
+
+// FindByName retrieves a preset with its entries by name.
+func (r *ConfigPresetRepository) FindByName(name string) (*model.ConfigPreset, error) {
+	var preset model.ConfigPreset
+	err := r.db.Preload("Entries").Where("name = ?", name).First(&preset).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgerr.ErrNotFound
+		}
+		return nil, err
+	}
+	return &preset, nil
+}
+
+// DeleteByName removes a preset and its entries.
+func (r *ConfigPresetRepository) DeleteByName(name string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var preset model.ConfigPreset
+		if err := tx.Where("name = ?", name).First(&preset).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkgerr.ErrNotFound
+			}
+			return err
+		}
+		if err := tx.Where("preset_id = ?", preset.ID).Delete(&model.ConfigPresetEntry{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&preset).Error
+	})
+}
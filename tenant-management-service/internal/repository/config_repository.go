@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"tenant-management-service/internal/model"
+	pkgerr "tenant-management-service/pkg/error"
 )
 
 type ConfigRepository struct {
@@ -13,15 +16,64 @@ func NewConfigRepository(db *gorm.DB) *ConfigRepository {
 	return &ConfigRepository{db: db}
 }
 
-// Upsert inserts or updates configurations in the database.
+// Upsert inserts or updates configurations in the database, matching existing rows by
+// tenant and key. Structured JSON values of existing rows are left untouched. Secret
+// configurations stay secret, and keep their value when written back masked, as clients
+// read them.
 func (r *ConfigRepository) Upsert(configs []model.Configuration) error {
-	for _, config := range configs {
-		// Use GORM's Save method to upsert (create or update)
-		if err := r.db.Save(&config).Error; err != nil {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range configs {
+			existing, err := findConfigForUpdate(tx, configs[i].TenantID, configs[i].ConfigKey)
+			if err != nil {
+				return err
+			}
+
+			// Create the configuration if the key is new for the tenant
+			if existing == nil {
+				if err := tx.Create(&configs[i]).Error; err != nil {
+					return err
+				}
+				continue
+			}
+
+			existing.IsSecret = existing.IsSecret || configs[i].IsSecret
+			if !existing.IsSecret || configs[i].ConfigValue != model.SecretMask {
+				existing.ConfigValue = configs[i].ConfigValue
+			}
+			existing.IsGlobal = configs[i].IsGlobal
+			if err := tx.Save(existing).Error; err != nil {
+				return err
+			}
+			configs[i] = *existing
+		}
+		return nil
+	})
+}
+
+// UpdateJSON locks the configuration of a tenant's key, lets update modify it and saves
+// the result. If the key does not exist update receives a new, unsaved configuration.
+// Any error returned by update aborts the transaction and is passed through.
+func (r *ConfigRepository) UpdateJSON(tenantID, configKey string, update func(config *model.Configuration) error) (*model.Configuration, error) {
+	var config *model.Configuration
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		existing, err := findConfigForUpdate(tx, tenantID, configKey)
+		if err != nil {
 			return err
 		}
+		if existing == nil {
+			existing = &model.Configuration{TenantID: tenantID, ConfigKey: configKey}
+		}
+
+		if err := update(existing); err != nil {
+			return err
+		}
+		config = existing
+		return tx.Save(config).Error
+	})
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return config, nil
 }
 
 // FindByTenantId retrieves all configurations for a specific tenant.
@@ -32,3 +84,31 @@ func (r *ConfigRepository) FindByTenantId(tenantID string) ([]model.Configuratio
 	}
 	return configs, nil
 }
+
+// FindByTenantIdAndKey retrieves a single configuration of a tenant by its key.
+func (r *ConfigRepository) FindByTenantIdAndKey(tenantID, configKey string) (*model.Configuration, error) {
+	var config model.Configuration
+	err := r.db.Where("tenant_id = ? AND config_key = ?", tenantID, configKey).First(&config).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgerr.ErrNotFound
+		}
+		return nil, err
+	}
+	return &config, nil
+}
+
+// findConfigForUpdate loads and row-locks a configuration, returning nil if it does not exist.
+func findConfigForUpdate(tx *gorm.DB, tenantID, configKey string) (*model.Configuration, error) {
+	var config model.Configuration
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("tenant_id = ? AND config_key = ?", tenantID, configKey).
+		First(&config).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &config, nil
+}
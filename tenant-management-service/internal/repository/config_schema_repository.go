@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"tenant-management-service/internal/model"
+	pkgerr "tenant-management-service/pkg/error"
+)
+
+type ConfigSchemaRepository struct {
+	db *gorm.DB
+}
+
+func NewConfigSchemaRepository(db *gorm.DB) *ConfigSchemaRepository {
+	return &ConfigSchemaRepository{db: db}
+}
+
+// Upsert creates or replaces the schema of a configuration key.
+func (r *ConfigSchemaRepository) Upsert(schema *model.ConfigSchema) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "config_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"schema", "updated_at"}),
+	}).Create(schema).Error
+}
+
+// FindByKey retrieves the schema of a configuration key.
+func (r *ConfigSchemaRepository) FindByKey(configKey string) (*model.ConfigSchema, error) {
+	var schema model.ConfigSchema
+	err := r.db.Where("config_key = ?", configKey).First(&schema).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgerr.ErrNotFound
+		}
+		return nil, err
+	}
+	return &schema, nil
+}
+
+// FindAll retrieves every registered schema.
+func (r *ConfigSchemaRepository) FindAll() ([]model.ConfigSchema, error) {
+	var schemas []model.ConfigSchema
+	if err := r.db.Order("config_key ASC").Find(&schemas).Error; err != nil {
+		return nil, err
+	}
+	return schemas, nil
+}
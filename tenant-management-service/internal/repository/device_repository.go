@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"tenant-management-service/internal/model"
+	pkgerr "tenant-management-service/pkg/error"
+	"time"
+)
+
+type DeviceRepository struct {
+	db *gorm.DB
+}
+
+func NewDeviceRepository(db *gorm.DB) *DeviceRepository {
+	return &DeviceRepository{db: db}
+}
+
+// Upsert registers a device token of a tenant, moving it to the given recipient if the
+// token is registered already, e.g. after another user signed in on the device.
+func (r *DeviceRepository) Upsert(device *model.DeviceToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing model.DeviceToken
+		err := tx.Where("tenant_id = ? AND platform = ? AND token = ?", device.TenantID, device.Platform, device.Token).
+			First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			device.ID = existing.ID
+			device.LastUsedAt = existing.LastUsedAt
+			device.CreatedAt = existing.CreatedAt
+		}
+		return tx.Save(device).Error
+	})
+}
+
+// FindByRecipient retrieves the device tokens of a recipient of a tenant.
+func (r *DeviceRepository) FindByRecipient(tenantID, recipient string) ([]model.DeviceToken, error) {
+	var devices []model.DeviceToken
+	err := r.db.Where("tenant_id = ? AND recipient = ?", tenantID, recipient).Order("id ASC").Find(&devices).Error
+	if err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// MarkUsed records that pushes were delivered to devices.
+func (r *DeviceRepository) MarkUsed(ids []uint, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&model.DeviceToken{}).Where("id IN ?", ids).Update("last_used_at", at).Error
+}
+
+// Delete removes a device token of a tenant.
+func (r *DeviceRepository) Delete(tenantID string, id uint) error {
+	result := r.db.Where("tenant_id = ? AND id = ?", tenantID, id).Delete(&model.DeviceToken{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return pkgerr.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteByIDs removes device tokens, such as ones the push service reported as invalid.
+func (r *DeviceRepository) DeleteByIDs(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Where("id IN ?", ids).Delete(&model.DeviceToken{}).Error
+}
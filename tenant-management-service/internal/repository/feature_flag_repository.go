@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"tenant-management-service/internal/model"
+	pkgerr "tenant-management-service/pkg/error"
+)
+
+type FeatureFlagRepository struct {
+	db *gorm.DB
+}
+
+func NewFeatureFlagRepository(db *gorm.DB) *FeatureFlagRepository {
+	return &FeatureFlagRepository{db: db}
+}
+
+// Upsert creates or updates a flag identified by its key and replaces its tier overrides.
+func (r *FeatureFlagRepository) Upsert(flag *model.FeatureFlag) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing model.FeatureFlag
+		err := tx.Where("`key` = ?", flag.Key).First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			flag.ID = existing.ID
+			flag.CreatedAt = existing.CreatedAt
+			if err := tx.Where("flag_id = ?", flag.ID).Delete(&model.FeatureFlagTierOverride{}).Error; err != nil {
+				return err
+			}
+		}
+
+		// Save the flag together with its new tier overrides
+		return tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(flag).Error
+	})
+}
+
+// FindAll retrieves every flag with its tier overrides.
+func (r *FeatureFlagRepository) FindAll() ([]model.FeatureFlag, error) {
+	var flags []model.FeatureFlag
+	if err := r.db.Preload("TierOverrides").Order("`key` ASC").Find(&flags).Error; err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+
+// DeleteByKey removes a flag and its tier overrides.
+func (r *FeatureFlagRepository) DeleteByKey(key string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var flag model.FeatureFlag
+		if err := tx.Where("`key` = ?", key).First(&flag).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return pkgerr.ErrNotFound
+			}
+			return err
+		}
+		if err := tx.Where("flag_id = ?", flag.ID).Delete(&model.FeatureFlagTierOverride{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&flag).Error
+	})
+}
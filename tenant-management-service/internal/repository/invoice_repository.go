@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"tenant-management-service/internal/model"
+	pkgerr "tenant-management-service/pkg/error"
+	"time"
+)
+
+type InvoiceRepository struct {
+	db *gorm.DB
+}
+
+func NewInvoiceRepository(db *gorm.DB) *InvoiceRepository {
+	return &InvoiceRepository{db: db}
+}
+
+// CreateIfAbsent inserts an invoice with its line items unless the tenant already has one
+// for the period, and reports whether it was inserted.
+func (r *InvoiceRepository) CreateIfAbsent(invoice *model.Invoice) (bool, error) {
+	created := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Omit("LineItems").Clauses(clause.OnConflict{DoNothing: true}).Create(invoice)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		created = true
+
+		for i := range invoice.LineItems {
+			invoice.LineItems[i].InvoiceID = invoice.ID
+		}
+		if len(invoice.LineItems) == 0 {
+			return nil
+		}
+		return tx.Create(&invoice.LineItems).Error
+	})
+	return created, err
+}
+
+// FindByTenantID retrieves a page of a tenant's invoices without line items, newest period
+// first, along with the total number of invoices.
+func (r *InvoiceRepository) FindByTenantID(tenantID string, limit, offset int) ([]model.Invoice, int64, error) {
+	query := r.db.Model(&model.Invoice{}).Where("tenant_id = ?", tenantID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var invoices []model.Invoice
+	if err := query.Order("period_start DESC").Limit(limit).Offset(offset).Find(&invoices).Error; err != nil {
+		return nil, 0, err
+	}
+	return invoices, total, nil
+}
+
+// FindByPeriod retrieves a tenant's invoice of the billing period starting at periodStart,
+// without line items.
+func (r *InvoiceRepository) FindByPeriod(tenantID string, periodStart time.Time) (*model.Invoice, error) {
+	var invoice model.Invoice
+	if err := r.db.Where("tenant_id = ? AND period_start = ?", tenantID, periodStart).First(&invoice).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgerr.ErrNotFound
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// FindLatest retrieves a tenant's invoice of the latest billing period, without line items.
+func (r *InvoiceRepository) FindLatest(tenantID string) (*model.Invoice, error) {
+	var invoice model.Invoice
+	if err := r.db.Where("tenant_id = ?", tenantID).Order("period_start DESC").First(&invoice).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgerr.ErrNotFound
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// FindByID retrieves an invoice of a tenant with its line items.
+func (r *InvoiceRepository) FindByID(tenantID string, id uint) (*model.Invoice, error) {
+	var invoice model.Invoice
+	err := r.db.Preload("LineItems", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Where("tenant_id = ?", tenantID).First(&invoice, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgerr.ErrNotFound
+		}
+		return nil, err
+	}
+	return &invoice, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"tenant-management-service/internal/model"
+	pkgerr "tenant-management-service/pkg/error"
+	"time"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) Create(notification *model.Notification) error {
+	return r.db.Create(notification).Error
+}
+
+// FindByID retrieves a notification of a tenant.
+func (r *NotificationRepository) FindByID(tenantID, id string) (*model.Notification, error) {
+	var notification model.Notification
+	err := r.db.Where("id = ? AND tenant_id = ?", id, tenantID).First(&notification).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgerr.ErrNotFound
+		}
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// FindDue retrieves the ids of up to limit notifications ready for a delivery attempt:
+// queued ones whose next attempt is due and ones whose sending lease has run out.
+func (r *NotificationRepository) FindDue(now time.Time, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.Model(&model.Notification{}).
+		Where("(status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_until < ?)",
+			model.NotificationStatusQueued, now, model.NotificationStatusSending, now).
+		Order("next_attempt_at ASC").Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Claim leases a notification ready for a delivery attempt to the caller until now plus
+// lease and counts the attempt. It returns nil if the notification is not ready, for
+// instance because another worker claimed it first.
+func (r *NotificationRepository) Claim(id string, now time.Time, lease time.Duration) (*model.Notification, error) {
+	result := r.db.Model(&model.Notification{}).
+		Where("id = ? AND ((status = ? AND next_attempt_at <= ?) OR (status = ? AND locked_until < ?))",
+			id, model.NotificationStatusQueued, now, model.NotificationStatusSending, now).
+		Updates(map[string]interface{}{
+			"status":       model.NotificationStatusSending,
+			"locked_until": now.Add(lease),
+			"attempts":     gorm.Expr("attempts + 1"),
+			"updated_at":   now,
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+
+	var notification model.Notification
+	if err := r.db.First(&notification, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// MarkSent records the successful delivery of a claimed notification.
+func (r *NotificationRepository) MarkSent(id string, at time.Time) error {
+	return r.finish(id, map[string]interface{}{
+		"status":       model.NotificationStatusSent,
+		"sent_at":      at,
+		"last_error":   "",
+		"locked_until": nil,
+	})
+}
+
+// MarkFailed records that a claimed notification will not be delivered.
+func (r *NotificationRepository) MarkFailed(id, lastError string) error {
+	return r.finish(id, map[string]interface{}{
+		"status":       model.NotificationStatusFailed,
+		"last_error":   lastError,
+		"locked_until": nil,
+	})
+}
+
+// Reschedule queues a claimed notification for another attempt at next.
+func (r *NotificationRepository) Reschedule(id, lastError string, next time.Time) error {
+	return r.finish(id, map[string]interface{}{
+		"status":          model.NotificationStatusQueued,
+		"last_error":      lastError,
+		"next_attempt_at": next,
+		"locked_until":    nil,
+	})
+}
+
+// finish updates a notification that is being sent.
+func (r *NotificationRepository) finish(id string, updates map[string]interface{}) error {
+	updates["updated_at"] = time.Now()
+	return r.db.Model(&model.Notification{}).
+		Where("id = ? AND status = ?", id, model.NotificationStatusSending).
+		Updates(updates).Error
+}
+
+// CreateAttempt records a delivery attempt.
+func (r *NotificationRepository) CreateAttempt(attempt *model.NotificationAttempt) error {
+	return r.db.Create(attempt).Error
+}
+
+// FindAttempts retrieves the delivery attempts of a notification in order.
+func (r *NotificationRepository) FindAttempts(notificationID string) ([]model.NotificationAttempt, error) {
+	var attempts []model.NotificationAttempt
+	if err := r.db.Where("notification_id = ?", notificationID).Order("id ASC").Find(&attempts).Error; err != nil {
+		return nil, err
+	}
+	return attempts, nil
+}
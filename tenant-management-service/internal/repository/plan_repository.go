@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"tenant-management-service/internal/model"
+	pkgerr "tenant-management-service/pkg/error"
+)
+
+type PlanRepository struct {
+	db *gorm.DB
+}
+
+func NewPlanRepository(db *gorm.DB) *PlanRepository {
+	return &PlanRepository{db: db}
+}
+
+// Upsert creates or updates the plan of a billing tier and replaces its channel prices.
+func (r *PlanRepository) Upsert(plan *model.Plan) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing model.Plan
+		err := tx.Where("billing_tier = ?", plan.BillingTier).First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			plan.ID = existing.ID
+			plan.CreatedAt = existing.CreatedAt
+			// Overage tiers go with their channel prices
+			if err := tx.Where("plan_id = ?", plan.ID).Delete(&model.PlanChannelPrice{}).Error; err != nil {
+				return err
+			}
+		}
+
+		// Save the plan together with its new channel prices and overage tiers
+		return tx.Session(&gorm.Session{FullSaveAssociations: true}).Save(plan).Error
+	})
+}
+
+// FindAll retrieves every plan with its channel prices.
+func (r *PlanRepository) FindAll() ([]model.Plan, error) {
+	var plans []model.Plan
+	if err := r.preloaded().Order("billing_tier ASC").Find(&plans).Error; err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+// FindByBillingTier retrieves the plan of a billing tier with its channel prices.
+func (r *PlanRepository) FindByBillingTier(billingTier string) (*model.Plan, error) {
+	var plan model.Plan
+	if err := r.preloaded().Where("billing_tier = ?", billingTier).First(&plan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgerr.ErrNotFound
+		}
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (r *PlanRepository) preloaded() *gorm.DB {
+	return r.db.
+		Preload("ChannelPrices", func(db *gorm.DB) *gorm.DB { return db.Order("channel ASC") }).
+		Preload("ChannelPrices.OverageTiers", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") })
+}
@@ -1,8 +1,11 @@
 package repository
 
 import (
+	"errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"tenant-management-service/internal/model"
+	pkgerr "tenant-management-service/pkg/error"
 )
 
 type QuotaRepository struct {
@@ -13,22 +16,74 @@ func NewQuotaRepository(db *gorm.DB) *QuotaRepository {
 	return &QuotaRepository{db: db}
 }
 
-// Upsert inserts or updates quotas in the database.
+// Upsert inserts or updates quotas in the database, matching existing rows by tenant and channel.
 func (r *QuotaRepository) Upsert(quotas []model.Quota) error {
-	for _, quota := range quotas {
-		// Use GORM's Save method to upsert (create or update)
-		if err := r.db.Save(&quota).Error; err != nil {
-			return err
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range quotas {
+			var existing model.Quota
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("tenant_id = ? AND channel = ?", quotas[i].TenantID, quotas[i].Channel).
+				First(&existing).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			// Keep the identity of an existing quota so the row is updated in place,
+			// replacing its windows and thresholds
+			if err == nil {
+				quotas[i].ID = existing.ID
+				quotas[i].CreatedAt = existing.CreatedAt
+				if err := tx.Where("quota_id = ?", existing.ID).Delete(&model.QuotaWindow{}).Error; err != nil {
+					return err
+				}
+				if err := tx.Where("quota_id = ?", existing.ID).Delete(&model.QuotaThreshold{}).Error; err != nil {
+					return err
+				}
+			}
+			if err := tx.Save(&quotas[i]).Error; err != nil {
+				return err
+			}
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
 // FindByTenantID retrieves all quotas for a specific tenant.
 func (r *QuotaRepository) FindByTenantID(tenantID string) ([]model.Quota, error) {
 	var quotas []model.Quota
-	if err := r.db.Where("tenant_id = ?", tenantID).Find(&quotas).Error; err != nil {
+	if err := r.db.Preload("Windows").Preload("Thresholds").Where("tenant_id = ?", tenantID).Find(&quotas).Error; err != nil {
 		return nil, err
 	}
 	return quotas, nil
 }
+
+// FindByTenantIDAndChannel retrieves the quota of a tenant's channel.
+func (r *QuotaRepository) FindByTenantIDAndChannel(tenantID, channel string) (*model.Quota, error) {
+	var quota model.Quota
+	err := r.db.Where("tenant_id = ? AND channel = ?", tenantID, channel).First(&quota).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgerr.ErrNotFound
+		}
+		return nil, err
+	}
+	return &quota, nil
+}
+
+// FindForUpdate retrieves and row-locks the quota of a tenant's channel until the
+// surrounding transaction ends, serializing concurrent consumers of the same quota.
+func (r *QuotaRepository) FindForUpdate(tenantID, channel string) (*model.Quota, error) {
+	var quota model.Quota
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Windows").
+		Preload("Thresholds").
+		Where("tenant_id = ? AND channel = ?", tenantID, channel).
+		First(&quota).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgerr.ErrNotFound
+		}
+		return nil, err
+	}
+	return &quota, nil
+}
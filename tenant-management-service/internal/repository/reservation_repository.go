@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"tenant-management-service/internal/model"
+	pkgerr "tenant-management-service/pkg/error"
+	"time"
+)
+
+type ReservationRepository struct {
+	db *gorm.DB
+}
+
+func NewReservationRepository(db *gorm.DB) *ReservationRepository {
+	return &ReservationRepository{db: db}
+}
+
+func (r *ReservationRepository) Create(reservation *model.QuotaReservation) error {
+	return r.db.Create(reservation).Error
+}
+
+func (r *ReservationRepository) Update(reservation *model.QuotaReservation) error {
+	return r.db.Save(reservation).Error
+}
+
+// FindByID retrieves a reservation of a tenant.
+func (r *ReservationRepository) FindByID(tenantID, id string) (*model.QuotaReservation, error) {
+	var reservation model.QuotaReservation
+	err := r.db.Where("id = ? AND tenant_id = ?", id, tenantID).First(&reservation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgerr.ErrNotFound
+		}
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// FindForUpdate retrieves and row-locks a reservation of a tenant.
+func (r *ReservationRepository) FindForUpdate(tenantID, id string) (*model.QuotaReservation, error) {
+	var reservation model.QuotaReservation
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND tenant_id = ?", id, tenantID).
+		First(&reservation).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgerr.ErrNotFound
+		}
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// SumHeld returns the capacity held by unexpired active reservations of a tenant's channel.
+func (r *ReservationRepository) SumHeld(tenantID, channel string, now time.Time) (int, error) {
+	var total int
+	err := r.db.Model(&model.QuotaReservation{}).
+		Select("COALESCE(SUM(count - committed), 0)").
+		Where("tenant_id = ? AND channel = ? AND status = ? AND expires_at > ?",
+			tenantID, channel, model.ReservationStatusActive, now).
+		Scan(&total).Error
+	return total, err
+}
+
+// ExpireBefore marks active reservations that expired before now as expired and returns
+// how many were reclaimed.
+func (r *ReservationRepository) ExpireBefore(now time.Time) (int64, error) {
+	result := r.db.Model(&model.QuotaReservation{}).
+		Where("status = ? AND expires_at <= ?", model.ReservationStatusActive, now).
+		Updates(map[string]interface{}{"status": model.ReservationStatusExpired, "updated_at": now})
+	return result.RowsAffected, result.Error
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"errors"
 	"gorm.io/gorm"
+	"strconv"
 	"tenant-management-service/internal/model"
 	pkgerr "tenant-management-service/pkg/error"
 )
@@ -25,6 +26,23 @@ func (r *TenantRepository) FindById(id uint) (*model.Tenant, error) {
 	return &tenant, err
 }
 
+// FindByTenantID retrieves a tenant by the string form of its ID used in tenant-scoped
+// tables and routes.
+func (r *TenantRepository) FindByTenantID(tenantID string) (*model.Tenant, error) {
+	id, err := strconv.ParseUint(tenantID, 10, 64)
+	if err != nil {
+		return nil, pkgerr.ErrNotFound
+	}
+	var tenant model.Tenant
+	if err := r.db.First(&tenant, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgerr.ErrNotFound
+		}
+		return nil, err
+	}
+	return &tenant, nil
+}
+
 func (r *TenantRepository) Update(tenant *model.Tenant) error {
 	return r.db.Save(tenant).Error
 }
@@ -33,6 +51,24 @@ func (r *TenantRepository) Delete(id uint) error {
 	return r.db.Delete(&model.Tenant{}, id).Error
 }
 
+// FindAll retrieves all tenants.
+func (r *TenantRepository) FindAll() ([]model.Tenant, error) {
+	var tenants []model.Tenant
+	if err := r.db.Order("id ASC").Find(&tenants).Error; err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
+
+// FindByBillingTier retrieves all tenants on a billing tier.
+func (r *TenantRepository) FindByBillingTier(billingTier string) ([]model.Tenant, error) {
+	var tenants []model.Tenant
+	if err := r.db.Where("billing_tier = ?", billingTier).Order("id ASC").Find(&tenants).Error; err != nil {
+		return nil, err
+	}
+	return tenants, nil
+}
+
 // FindByClientId retrieves a tenant by its client_id.
 func (r *TenantRepository) FindByClientId(clientId string) (*model.Tenant, error) {
 	var tenant model.Tenant
@@ -0,0 +1,41 @@
+package repository
+
+import "gorm.io/gorm"
+
+// Tx groups repositories bound to a single database transaction.
+type Tx struct {
+	Tenants       *TenantRepository
+	Configs       *ConfigRepository
+	Quotas        *QuotaRepository
+	Usage         *UsageRepository
+	Reservations  *ReservationRepository
+	Alerts        *AlertRepository
+	Notifications *NotificationRepository
+	Changes       *ChangeRepository
+}
+
+// Transactor runs units of work spanning several repositories atomically.
+type Transactor struct {
+	db *gorm.DB
+}
+
+func NewTransactor(db *gorm.DB) *Transactor {
+	return &Transactor{db: db}
+}
+
+// Transaction runs fn in a database transaction, committing if it returns nil and
+// rolling back otherwise.
+func (t *Transactor) Transaction(fn func(tx *Tx) error) error {
+	return t.db.Transaction(func(db *gorm.DB) error {
+		return fn(&Tx{
+			Tenants:       NewTenantRepository(db),
+			Configs:       NewConfigRepository(db),
+			Quotas:        NewQuotaRepository(db),
+			Usage:         NewUsageRepository(db),
+			Reservations:  NewReservationRepository(db),
+			Alerts:        NewAlertRepository(db),
+			Notifications: NewNotificationRepository(db),
+			Changes:       NewChangeRepository(db),
+		})
+	})
+}
@@ -1,8 +1,12 @@
 package repository
 
 import (
+	"fmt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"strings"
 	"tenant-management-service/internal/model"
+	"time"
 )
 
 type UsageRepository struct {
@@ -13,18 +17,407 @@ func NewUsageRepository(db *gorm.DB) *UsageRepository {
 	return &UsageRepository{db: db}
 }
 
-// FindByTenantIDAndChannel retrieves usage data for a tenant, optionally filtered by channel.
-func (r *UsageRepository) FindByTenantIDAndChannel(tenantID string, channel string) ([]model.Usage, error) {
-	var usage []model.Usage
-	query := r.db.Where("tenant_id = ?", tenantID)
+// usageOutcomeColumns maps usage outcomes to the columns counting them.
+var usageOutcomeColumns = []struct {
+	outcome string
+	column  string
+}{
+	{model.UsageOutcomeAccepted, "notifications_sent"},
+	{model.UsageOutcomeDelivered, "notifications_delivered"},
+	{model.UsageOutcomeFailed, "notifications_failed"},
+	{model.UsageOutcomeUndelivered, "notifications_undelivered"},
+	{model.UsageOutcomeBounced, "notifications_bounced"},
+	{model.UsageOutcomeComplained, "notifications_complained"},
+	{model.UsageOutcomeSuppressed, "notifications_suppressed"},
+}
+
+// usagePointRow is an aggregated usage row. Without grouping by outcome it also carries
+// the outcome counts the delivery and failure rates of the point are computed from.
+type usagePointRow struct {
+	model.UsagePoint
+	Delivered   int
+	Failed      int
+	Undelivered int
+	Bounced     int
+}
+
+// point returns the usage point of the row, with its rates unless grouped by outcome.
+func (row usagePointRow) point(query model.UsageQuery) model.UsagePoint {
+	point := row.UsagePoint
+	if !query.GroupByOutcome {
+		rates := model.NewUsageRates(map[string]int{
+			model.UsageOutcomeAccepted:    row.Count,
+			model.UsageOutcomeDelivered:   row.Delivered,
+			model.UsageOutcomeFailed:      row.Failed,
+			model.UsageOutcomeUndelivered: row.Undelivered,
+			model.UsageOutcomeBounced:     row.Bounced,
+		})
+		point.DeliveryRate, point.FailureRate = rates.DeliveryRate, rates.FailureRate
+	}
+	return point
+}
+
+// usagePeriods maps granularities to expressions truncating Usage.Date to the period start.
+var usagePeriods = map[string]string{
+	model.UsageGranularityDay:   "date",
+	model.UsageGranularityWeek:  "DATE_SUB(date, INTERVAL WEEKDAY(date) DAY)",
+	model.UsageGranularityMonth: "DATE_SUB(date, INTERVAL DAYOFMONTH(date) - 1 DAY)",
+}
+
+// Aggregate retrieves a page of usage aggregated as described by query, ordered by period,
+// channel and outcome, along with the number of aggregated rows across all pages.
+func (r *UsageRepository) Aggregate(query model.UsageQuery) ([]model.UsagePoint, int64, error) {
+	aggregated := r.aggregated(query)
+
+	var total int64
+	if err := r.db.Table("(?) AS u", aggregated).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []usagePointRow
+	err := r.db.Table("(?) AS u", aggregated).
+		Order("period_start, channel, outcome").
+		Limit(query.Limit).Offset(query.Offset).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	points := make([]model.UsagePoint, len(rows))
+	for i, row := range rows {
+		points[i] = row.point(query)
+	}
+	return points, total, nil
+}
+
+// StreamAggregate passes the usage aggregated as described by query to fn one point at a
+// time, in the order of Aggregate and ignoring pagination. Rows are read from the database
+// as they are consumed, so exports of any length use constant memory. Streaming stops at
+// the first error returned by fn.
+func (r *UsageRepository) StreamAggregate(query model.UsageQuery, fn func(point model.UsagePoint) error) error {
+	rows, err := r.db.Table("(?) AS u", r.aggregated(query)).Order("period_start, channel, outcome").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row usagePointRow
+		if err := r.db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row.point(query)); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
 
-	// Filter by channel if provided
+// Totals sums the usage selected by query per outcome, over both daily rows and rollups.
+func (r *UsageRepository) Totals(query model.UsageQuery) (map[string]int, error) {
+	selects := make([]string, len(usageOutcomeColumns))
+	counts := make([]int, len(usageOutcomeColumns))
+	dest := make([]interface{}, len(usageOutcomeColumns))
+	for i, oc := range usageOutcomeColumns {
+		selects[i] = fmt.Sprintf("COALESCE(SUM(%s), 0)", oc.column)
+		dest[i] = &counts[i]
+	}
+
+	if err := r.combined(query).Select(strings.Join(selects, ", ")).Row().Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]int, len(usageOutcomeColumns))
+	for i, oc := range usageOutcomeColumns {
+		totals[oc.outcome] = counts[i]
+	}
+	return totals, nil
+}
+
+// aggregated builds the query aggregating usage per period and the requested groupings
+// over both daily rows and rollups.
+// Grouping by outcome turns every outcome column into rows of its own; otherwise rows count
+// accepted notifications and carry the sums needed for their rates.
+func (r *UsageRepository) aggregated(query model.UsageQuery) *gorm.DB {
+	channel := "''"
+	groups := []string{"period_start"}
+	if query.GroupByChannel {
+		channel = "channel"
+		groups = append(groups, "channel")
+	}
+
+	columns := usageOutcomeColumns[:1]
+	if query.GroupByOutcome {
+		columns = usageOutcomeColumns
+	}
+
+	rateSums := "SUM(notifications_delivered) AS delivered, SUM(notifications_failed) AS failed, " +
+		"SUM(notifications_undelivered) AS undelivered, SUM(notifications_bounced) AS bounced"
+	if query.GroupByOutcome {
+		rateSums = "0 AS delivered, 0 AS failed, 0 AS undelivered, 0 AS bounced"
+	}
+
+	parts := make([]interface{}, len(columns))
+	placeholders := make([]string, len(columns))
+	for i, oc := range columns {
+		outcome := ""
+		if query.GroupByOutcome {
+			outcome = oc.outcome
+		}
+		parts[i] = r.combined(query).
+			Select(fmt.Sprintf("period_start, %s AS channel, ? AS outcome, SUM(%s) AS count, %s", channel, oc.column, rateSums), outcome).
+			Group(strings.Join(groups, ", "))
+		placeholders[i] = "(?)"
+	}
+	return r.db.Raw(strings.Join(placeholders, " UNION ALL "), parts...)
+}
+
+// combined selects the outcome counts of the daily rows and rollups matching the filters
+// of query, along with their channel and the start of their period. Rollups count as a
+// whole towards ranges they overlap, as documented on UsageQuery.
+func (r *UsageRepository) combined(query model.UsageQuery) *gorm.DB {
+	columns := make([]string, len(usageOutcomeColumns))
+	for i, oc := range usageOutcomeColumns {
+		columns[i] = oc.column
+	}
+	counts := strings.Join(columns, ", ")
+
+	daily := r.filtered(query).Select(fmt.Sprintf("channel, %s AS period_start, %s", usagePeriods[query.Granularity], counts))
+	rollups := r.db.Model(&model.UsageRollup{}).Where("tenant_id = ?", query.TenantID)
+	if query.Channel != "" {
+		rollups = rollups.Where("channel = ?", query.Channel)
+	}
+	firstMonth, lastMonth := rollupMonths(query.From, query.To)
+	if firstMonth != nil {
+		rollups = rollups.Where("month >= ?", *firstMonth)
+	}
+	if lastMonth != nil {
+		rollups = rollups.Where("month <= ?", *lastMonth)
+	}
+	rollups = rollups.Select(fmt.Sprintf("channel, month AS period_start, %s", counts))
+
+	return r.db.Table("((?) UNION ALL (?)) AS combined", daily, rollups)
+}
+
+// rollupMonths returns the first and last month, dated the first day, of the rollups
+// overlapping the dates from and to, inclusive. Unbounded ends stay nil.
+func rollupMonths(from, to *time.Time) (*time.Time, *time.Time) {
+	var first, last *time.Time
+	if from != nil {
+		month := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+		first = &month
+	}
+	if to != nil {
+		month := time.Date(to.Year(), to.Month(), 1, 0, 0, 0, 0, time.UTC)
+		last = &month
+	}
+	return first, last
+}
+
+// filtered selects the daily usage rows matching the filters of query.
+func (r *UsageRepository) filtered(query model.UsageQuery) *gorm.DB {
+	db := r.db.Model(&model.Usage{}).Where("tenant_id = ?", query.TenantID)
+	if query.Channel != "" {
+		db = db.Where("channel = ?", query.Channel)
+	}
+	if query.From != nil {
+		db = db.Where("date >= ?", *query.From)
+	}
+	if query.To != nil {
+		db = db.Where("date <= ?", *query.To)
+	}
+	return db
+}
+
+// SumSent returns the notifications sent on a tenant's channel between two dates, inclusive.
+func (r *UsageRepository) SumSent(tenantID, channel string, from, to time.Time) (int, error) {
+	var total int
+	err := r.db.Model(&model.Usage{}).
+		Select("COALESCE(SUM(notifications_sent), 0)").
+		Where("tenant_id = ? AND channel = ? AND date >= ? AND date <= ?", tenantID, channel, from, to).
+		Scan(&total).Error
+	return total, err
+}
+
+// SumByChannel returns the usage of a tenant between two dates, inclusive, summed per
+// channel into one row each. It reads daily rows only, which are kept until the days are
+// invoiced.
+func (r *UsageRepository) SumByChannel(tenantID string, from, to time.Time) ([]model.Usage, error) {
+	var usages []model.Usage
+	err := r.db.Model(&model.Usage{}).
+		Select("channel, SUM(notifications_sent) AS notifications_sent, SUM(overage_sent) AS overage_sent").
+		Where("tenant_id = ? AND date >= ? AND date <= ?", tenantID, from, to).
+		Group("channel").Order("channel ASC").
+		Scan(&usages).Error
+	return usages, err
+}
+
+// DailySent retrieves the notifications sent per day on a tenant's channels between two
+// dates, inclusive, optionally restricted to one channel. Days without usage have no row.
+func (r *UsageRepository) DailySent(tenantID, channel string, from, to time.Time) ([]model.Usage, error) {
+	query := r.db.Model(&model.Usage{}).
+		Select("channel, date, notifications_sent").
+		Where("tenant_id = ? AND date >= ? AND date <= ?", tenantID, from, to)
 	if channel != "" {
 		query = query.Where("channel = ?", channel)
 	}
 
-	if err := query.Find(&usage).Error; err != nil {
+	var usages []model.Usage
+	err := query.Order("channel ASC, date ASC").Scan(&usages).Error
+	return usages, err
+}
+
+// SumBuckets returns the notifications sent on a tenant's channel in the hourly buckets
+// starting within [from, to).
+func (r *UsageRepository) SumBuckets(tenantID, channel string, from, to time.Time) (int, error) {
+	var total int
+	err := r.db.Model(&model.UsageBucket{}).
+		Select("COALESCE(SUM(notifications_sent), 0)").
+		Where("tenant_id = ? AND channel = ? AND bucket_start >= ? AND bucket_start < ?", tenantID, channel, from, to).
+		Scan(&total).Error
+	return total, err
+}
+
+// Increment atomically adds delta to the usage of a tenant's channel at a point in time,
+// both to the daily usage row and to the hourly bucket of the tenant's timezone, creating
+// them if they do not exist yet.
+func (r *UsageRepository) Increment(tenantID, channel string, at time.Time, loc *time.Location, delta model.UsageDelta) error {
+	now := time.Now()
+	usage := model.Usage{
+		TenantID:          tenantID,
+		Channel:           channel,
+		Date:              model.UsageDate(at, loc),
+		NotificationsSent: delta.Sent,
+		OverageSent:       delta.Overage,
+		FlaggedSent:       delta.Flagged,
+	}
+	err := r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "tenant_id"}, {Name: "channel"}, {Name: "date"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"notifications_sent": gorm.Expr("notifications_sent + ?", delta.Sent),
+			"overage_sent":       gorm.Expr("overage_sent + ?", delta.Overage),
+			"flagged_sent":       gorm.Expr("flagged_sent + ?", delta.Flagged),
+			"updated_at":         now,
+		}),
+	}).Create(&usage).Error
+	if err != nil {
+		return err
+	}
+
+	bucket := model.UsageBucket{
+		TenantID:          tenantID,
+		Channel:           channel,
+		BucketStart:       model.UsageHour(at, loc),
+		NotificationsSent: delta.Sent,
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "tenant_id"}, {Name: "channel"}, {Name: "bucket_start"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"notifications_sent": gorm.Expr("notifications_sent + ?", delta.Sent),
+			"updated_at":         now,
+		}),
+	}).Create(&bucket).Error
+}
+
+// usageBatchSize bounds the rows written by a single statement of AddCounts.
+const usageBatchSize = 500
+
+// AddCounts adds the counts of usage rows and hourly buckets to the stored ones in batched
+// upserts, creating rows that do not exist yet. Callers should sort rows by key so that
+// concurrent writers lock them in the same order.
+func (r *UsageRepository) AddCounts(usages []model.Usage, buckets []model.UsageBucket) error {
+	now := time.Now()
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if len(usages) > 0 {
+			err := tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "tenant_id"}, {Name: "channel"}, {Name: "date"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"notifications_sent":        gorm.Expr("notifications_sent + VALUES(notifications_sent)"),
+					"notifications_delivered":   gorm.Expr("notifications_delivered + VALUES(notifications_delivered)"),
+					"notifications_failed":      gorm.Expr("notifications_failed + VALUES(notifications_failed)"),
+					"notifications_undelivered": gorm.Expr("notifications_undelivered + VALUES(notifications_undelivered)"),
+					"notifications_bounced":     gorm.Expr("notifications_bounced + VALUES(notifications_bounced)"),
+					"notifications_complained":  gorm.Expr("notifications_complained + VALUES(notifications_complained)"),
+					"notifications_suppressed":  gorm.Expr("notifications_suppressed + VALUES(notifications_suppressed)"),
+					"updated_at":                now,
+				}),
+			}).CreateInBatches(&usages, usageBatchSize).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(buckets) > 0 {
+			return tx.Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "tenant_id"}, {Name: "channel"}, {Name: "bucket_start"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"notifications_sent": gorm.Expr("notifications_sent + VALUES(notifications_sent)"),
+					"updated_at":         now,
+				}),
+			}).CreateInBatches(&buckets, usageBatchSize).Error
+		}
+		return nil
+	})
+}
+
+// rollupReady selects the daily usage rows no invoice may still be issued for, which are
+// the only ones that can be rolled up: invoices sum daily rows of billing periods, which
+// do not line up with the calendar months of rollups. Those are the rows before the end of
+// the tenant's latest invoice and, for tenants without invoices, the rows of tenants whose
+// billing tier has no plan to invoice them with.
+const rollupReady = `(usages.date < (SELECT MAX(invoices.period_end) FROM invoices WHERE invoices.tenant_id = usages.tenant_id)
+	OR (NOT EXISTS (SELECT 1 FROM invoices WHERE invoices.tenant_id = usages.tenant_id)
+		AND NOT EXISTS (SELECT 1 FROM tenants JOIN plans ON plans.billing_tier = tenants.billing_tier WHERE tenants.id = usages.tenant_id)))`
+
+// OldestDateBefore returns the date of the oldest daily usage row before cutoff that can be
+// rolled up, or nil if there is none.
+func (r *UsageRepository) OldestDateBefore(cutoff time.Time) (*time.Time, error) {
+	var usages []model.Usage
+	err := r.db.Select("date").Where("date < ?", cutoff).Where(rollupReady).Order("date ASC").Limit(1).Find(&usages).Error
+	if err != nil || len(usages) == 0 {
 		return nil, err
 	}
-	return usage, nil
+	return &usages[0].Date, nil
+}
+
+// RollupMonth adds the daily usage rows of a calendar month that can be rolled up to the
+// monthly rollups and deletes them, returning the number of rows rolled up. Reading the rows for the rollup
+// locks them, so rows written concurrently are either rolled up or kept.
+func (r *UsageRepository) RollupMonth(month time.Time) (int64, error) {
+	columns := []string{
+		"notifications_sent", "notifications_delivered", "notifications_failed", "notifications_undelivered",
+		"notifications_bounced", "notifications_complained", "notifications_suppressed", "overage_sent", "flagged_sent",
+	}
+	sums := make([]string, len(columns))
+	updates := make([]string, len(columns))
+	for i, column := range columns {
+		sums[i] = fmt.Sprintf("SUM(%s)", column)
+		updates[i] = fmt.Sprintf("%s = %s + VALUES(%s)", column, column, column)
+	}
+
+	end := month.AddDate(0, 1, 0)
+	var rolledUp int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Exec(fmt.Sprintf(
+			"INSERT INTO usage_rollups (tenant_id, channel, month, %s, created_at, updated_at) "+
+				"SELECT tenant_id, channel, ?, %s, ?, ? FROM usages WHERE date >= ? AND date < ? AND "+rollupReady+" GROUP BY tenant_id, channel "+
+				"ON DUPLICATE KEY UPDATE %s, updated_at = VALUES(updated_at)",
+			strings.Join(columns, ", "), strings.Join(sums, ", "), strings.Join(updates, ", ")),
+			month, now, now, month, end).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Where("date >= ? AND date < ?", month, end).Where(rollupReady).Delete(&model.Usage{})
+		rolledUp = result.RowsAffected
+		return result.Error
+	})
+	return rolledUp, err
+}
+
+// DeleteBucketsBefore deletes the hourly buckets starting before cutoff and returns how
+// many were deleted.
+func (r *UsageRepository) DeleteBucketsBefore(cutoff time.Time) (int64, error) {
+	result := r.db.Where("bucket_start < ?", cutoff).Delete(&model.UsageBucket{})
+	return result.RowsAffected, result.Error
 }
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRollupMonths(t *testing.T) {
+	date := func(year int, month time.Month, day int) *time.Time {
+		d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+	tests := []struct {
+		name      string
+		from, to  *time.Time
+		wantFirst *time.Time
+		wantLast  *time.Time
+	}{
+		{"unbounded", nil, nil, nil, nil},
+		{"month boundaries", date(2024, 3, 1), date(2024, 5, 31), date(2024, 3, 1), date(2024, 5, 1)},
+		// Rolled-up months overlapping the range count as a whole, days before From included
+		{"mid-month from", date(2024, 3, 15), nil, date(2024, 3, 1), nil},
+		{"mid-month to", nil, date(2024, 5, 10), nil, date(2024, 5, 1)},
+		{"within one month", date(2024, 2, 10), date(2024, 2, 20), date(2024, 2, 1), date(2024, 2, 1)},
+		{"across years", date(2023, 12, 31), date(2024, 1, 1), date(2023, 12, 1), date(2024, 1, 1)},
+	}
+	equal := func(a, b *time.Time) bool {
+		return (a == nil && b == nil) || (a != nil && b != nil && a.Equal(*b))
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first, last := rollupMonths(tt.from, tt.to)
+			if !equal(first, tt.wantFirst) || !equal(last, tt.wantLast) {
+				t.Errorf("rollupMonths = %v, %v, want %v, %v", first, last, tt.wantFirst, tt.wantLast)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"errors"
+	"gorm.io/gorm"
+	"tenant-management-service/internal/model"
+	pkgerr "tenant-management-service/pkg/error"
+)
+
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// Upsert creates or updates the endpoint of a tenant identified by its name. Existing
+// endpoints keep their secret. It reports whether the endpoint was created.
+func (r *WebhookRepository) Upsert(endpoint *model.WebhookEndpoint) (bool, error) {
+	created := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var existing model.WebhookEndpoint
+		err := tx.Where("tenant_id = ? AND name = ?", endpoint.TenantID, endpoint.Name).First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			endpoint.ID = existing.ID
+			endpoint.Secret = existing.Secret
+			endpoint.CreatedAt = existing.CreatedAt
+		}
+		created = endpoint.ID == 0
+		return tx.Save(endpoint).Error
+	})
+	return created, err
+}
+
+// FindByTenantID retrieves the endpoints of a tenant ordered by name.
+func (r *WebhookRepository) FindByTenantID(tenantID string) ([]model.WebhookEndpoint, error) {
+	var endpoints []model.WebhookEndpoint
+	if err := r.db.Where("tenant_id = ?", tenantID).Order("name ASC").Find(&endpoints).Error; err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// FindByName retrieves an endpoint of a tenant.
+func (r *WebhookRepository) FindByName(tenantID, name string) (*model.WebhookEndpoint, error) {
+	var endpoint model.WebhookEndpoint
+	err := r.db.Where("tenant_id = ? AND name = ?", tenantID, name).First(&endpoint).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, pkgerr.ErrNotFound
+		}
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// UpdateSecret replaces the secret of an endpoint of a tenant.
+func (r *WebhookRepository) UpdateSecret(tenantID, name, secret string) (*model.WebhookEndpoint, error) {
+	result := r.db.Model(&model.WebhookEndpoint{}).
+		Where("tenant_id = ? AND name = ?", tenantID, name).
+		Update("secret", secret)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, pkgerr.ErrNotFound
+	}
+	return r.FindByName(tenantID, name)
+}
+
+// DeleteByName removes an endpoint of a tenant.
+func (r *WebhookRepository) DeleteByName(tenantID, name string) error {
+	result := r.db.Where("tenant_id = ? AND name = ?", tenantID, name).Delete(&model.WebhookEndpoint{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return pkgerr.ErrNotFound
+	}
+	return nil
+}
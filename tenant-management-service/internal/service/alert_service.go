@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"strings"
+	"tenant-management-service/internal/config"
+	"tenant-management-service/internal/model"
+	"tenant-management-service/internal/repository"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/safehttp"
+	"tenant-management-service/pkg/utils"
+	"time"
+)
+
+// AlertService delivers quota alerts through the sinks each tenant selects. Alerts are
+// stored pending by the services raising them and delivered in the background by Run.
+// Queued alerts are only a hint to deliver them soon: the sweeper picks up whatever the
+// in-memory queue loses to a full queue or a restart.
+type AlertService struct {
+	repo       *repository.AlertRepository
+	tenantRepo *repository.TenantRepository
+	configRepo *repository.ConfigRepository
+	transactor *repository.Transactor
+	sinks      map[string]alertSink
+	queue      chan uint
+	config     config.AlertConfig
+}
+
+func NewAlertService(repo *repository.AlertRepository, tenantRepo *repository.TenantRepository, configRepo *repository.ConfigRepository,
+	transactor *repository.Transactor, alertConfig config.AlertConfig) *AlertService {
+	return &AlertService{
+		repo:       repo,
+		tenantRepo: tenantRepo,
+		configRepo: configRepo,
+		transactor: transactor,
+		sinks: map[string]alertSink{
+			model.AlertSinkLog:     logAlertSink{},
+			model.AlertSinkEmail:   newEmailAlertSink(alertConfig.SMTP),
+			model.AlertSinkWebhook: webhookAlertSink{client: safehttp.NewClient(alertConfig.WebhookTimeout)},
+		},
+		queue:  make(chan uint, alertConfig.QueueSize),
+		config: alertConfig,
+	}
+}
+
+// Enqueue hands stored alerts to the delivery worker without blocking. Alerts that do not
+// fit in the queue are left to the sweeper.
+func (s *AlertService) Enqueue(alerts []model.QuotaAlert) {
+	for _, alert := range alerts {
+		select {
+		case s.queue <- alert.ID:
+		default:
+			return
+		}
+	}
+}
+
+// Run delivers queued alerts until ctx is cancelled. Alerts still queued in memory then
+// are delivered after a restart.
+func (s *AlertService) Run(ctx context.Context) {
+	for {
+		select {
+		case id := <-s.queue:
+			s.deliver(ctx, id)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Sweep queues pending alerts that are not in the queue: alerts that did not fit in the
+// queue and ones whose delivery stopped with the service.
+func (s *AlertService) Sweep(ctx context.Context) {
+	ids, err := s.repo.FindPending(time.Now(), cap(s.queue))
+	if err != nil {
+		logger.Error("Error fetching pending quota alerts", zap.Error(err))
+		return
+	}
+	for _, id := range ids {
+		select {
+		case s.queue <- id:
+		default:
+			return
+		}
+	}
+}
+
+// GetAlerts retrieves a page of a tenant's alert history, optionally filtered by channel.
+func (s *AlertService) GetAlerts(tenantID, channel string, limit, offset int) ([]model.QuotaAlert, int64, error) {
+	// Validation
+	if err := utils.ValidateNonEmptyString(tenantID, "TenantID"); err != nil {
+		return nil, 0, err
+	}
+
+	alerts, total, err := s.repo.FindByTenantID(tenantID, channel, limit, offset)
+	if err != nil {
+		logger.Error("Error fetching quota alerts", zap.Error(err))
+		return nil, 0, errors.New("failed to fetch quota alerts")
+	}
+	return alerts, total, nil
+}
+
+// deliver claims a pending alert, sends it through every sink the tenant selected and
+// records the outcome. Alerts are delivered once; sinks that fail are not retried.
+func (s *AlertService) deliver(ctx context.Context, id uint) {
+	alert, err := s.repo.Claim(id, time.Now(), 2*s.config.SendTimeout)
+	if err != nil {
+		logger.Error("Error claiming quota alert", zap.Uint("alert_id", id), zap.Error(err))
+		return
+	}
+	if alert == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.SendTimeout)
+	defer cancel()
+
+	var delivered, failures []string
+	target, sinkNames, err := s.loadTarget(alert.TenantID)
+	if err != nil {
+		logger.Error("Error loading alert settings", zap.String("tenant_id", alert.TenantID), zap.Error(err))
+		failures = append(failures, "failed to load alert settings")
+	}
+
+	for _, name := range sinkNames {
+		sink, ok := s.sinks[name]
+		if !ok {
+			failures = append(failures, fmt.Sprintf("%s: unknown sink", name))
+			continue
+		}
+		if err := sink.send(ctx, target, alert); err != nil {
+			logger.Warn("Error delivering quota alert", zap.String("tenant_id", alert.TenantID), zap.String("sink", name), zap.Error(err))
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		delivered = append(delivered, name)
+	}
+
+	deliveryError := truncate(strings.Join(failures, "; "), 1024)
+	if err := s.repo.UpdateDelivery(alert.ID, strings.Join(delivered, ","), deliveryError, time.Now()); err != nil {
+		logger.Error("Error recording alert delivery", zap.Error(err))
+	}
+}
+
+// loadTarget loads the tenant of an alert and the sinks it selected, defaulting to the log sink.
+func (s *AlertService) loadTarget(tenantID string) (alertTarget, []string, error) {
+	tenant, err := s.tenantRepo.FindByTenantID(tenantID)
+	if err != nil {
+		return alertTarget{}, nil, err
+	}
+	target := alertTarget{tenant: tenant}
+
+	sinkNames := []string{model.AlertSinkLog}
+	sinksConfig, err := s.configRepo.FindByTenantIdAndKey(tenantID, model.AlertSinksConfigKey)
+	switch {
+	case err == nil:
+		sinkNames = parseAlertSinks(sinksConfig.ConfigValue)
+	case !errors.Is(err, pkgerr.ErrNotFound):
+		return target, nil, err
+	}
+
+	webhookConfig, err := s.configRepo.FindByTenantIdAndKey(tenantID, model.AlertWebhookURLConfigKey)
+	switch {
+	case err == nil:
+		target.webhookURL = strings.TrimSpace(webhookConfig.ConfigValue)
+	case !errors.Is(err, pkgerr.ErrNotFound):
+		return target, nil, err
+	}
+
+	return target, sinkNames, nil
+}
+
+// parseAlertSinks splits a comma-separated list of sink names, dropping blanks and duplicates.
+func parseAlertSinks(value string) []string {
+	var sinks []string
+	seen := map[string]bool{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		sinks = append(sinks, name)
+	}
+	return sinks
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"strings"
+	"tenant-management-service/internal/config"
+	"tenant-management-service/internal/model"
+	"tenant-management-service/internal/provider"
+	"tenant-management-service/pkg/logger"
+	"time"
+)
+
+// alertTarget is the tenant an alert is delivered to along with its alert settings.
+type alertTarget struct {
+	tenant     *model.Tenant
+	webhookURL string
+}
+
+// alertSink delivers quota alerts to one kind of destination.
+type alertSink interface {
+	send(ctx context.Context, target alertTarget, alert *model.QuotaAlert) error
+}
+
+// logAlertSink writes alerts to the service log.
+type logAlertSink struct{}
+
+func (logAlertSink) send(_ context.Context, target alertTarget, alert *model.QuotaAlert) error {
+	logger.Warn("Quota threshold crossed",
+		zap.String("tenant_id", alert.TenantID),
+		zap.String("channel", alert.Channel),
+		zap.String("window", alert.Window),
+		zap.Int("threshold", alert.Threshold),
+		zap.Int("used", alert.Used),
+		zap.Int("limit", alert.Limit))
+	return nil
+}
+
+// webhookAlertSink posts alerts as JSON to the webhook URL configured by the tenant, which
+// is only reached at a public address and without following redirects.
+type webhookAlertSink struct {
+	client *http.Client
+}
+
+func (s webhookAlertSink) send(ctx context.Context, target alertTarget, alert *model.QuotaAlert) error {
+	if target.webhookURL == "" {
+		return fmt.Errorf("no %s configured", model.AlertWebhookURLConfigKey)
+	}
+	if !strings.HasPrefix(target.webhookURL, "https://") && !strings.HasPrefix(target.webhookURL, "http://") {
+		return fmt.Errorf("%s must be an http or https URL", model.AlertWebhookURLConfigKey)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"event": "quota.threshold_crossed", "alert": alert})
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, target.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// emailAlertSink mails alerts to the tenant's email address through the SMTP provider,
+// using the SMTP server of the service rather than one configured by the tenant.
+type emailAlertSink struct {
+	provider *provider.SMTPProvider
+	settings provider.Settings
+}
+
+func newEmailAlertSink(smtpConfig config.SMTPConfig) emailAlertSink {
+	return emailAlertSink{
+		provider: provider.NewSMTPProvider(),
+		settings: provider.Settings{
+			"host":         smtpConfig.Host,
+			"port":         strconv.Itoa(smtpConfig.Port),
+			"username":     smtpConfig.Username,
+			"password":     smtpConfig.Password,
+			"from_address": smtpConfig.From,
+			"tls":          smtpConfig.TLS,
+		},
+	}
+}
+
+func (s emailAlertSink) send(ctx context.Context, target alertTarget, alert *model.QuotaAlert) error {
+	if s.settings["host"] == "" || s.settings["from_address"] == "" {
+		return errors.New("SMTP is not configured")
+	}
+	if target.tenant.Email == "" {
+		return errors.New("tenant has no email address")
+	}
+
+	return s.provider.Send(ctx, s.settings, &model.Notification{
+		ID:        fmt.Sprintf("quota-alert-%d", alert.ID),
+		TenantID:  alert.TenantID,
+		Channel:   "email",
+		Recipient: target.tenant.Email,
+		Subject:   fmt.Sprintf("%s quota for %s reached %d%%", alert.Window, alert.Channel, alert.Threshold),
+		Body: fmt.Sprintf("Usage of your %s %s quota reached %d of %d (%d%%).\nThe window resets at %s.\n",
+			alert.Channel, alert.Window, alert.Used, alert.Limit, alert.Threshold, alert.WindowEnd.UTC().Format(time.RFC1123)),
+	})
+}
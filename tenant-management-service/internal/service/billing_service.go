@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"regexp"
+	"strconv"
+	"strings"
+	"tenant-management-service/internal/config"
+	"tenant-management-service/internal/model"
+	"tenant-management-service/internal/model/dto"
+	"tenant-management-service/internal/repository"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/utils"
+	"time"
+)
+
+// currencyCode matches ISO 4217 currency codes.
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// BillingService prices plans and issues invoices from recorded usage.
+type BillingService struct {
+	planRepo    *repository.PlanRepository
+	invoiceRepo *repository.InvoiceRepository
+	usageRepo   *repository.UsageRepository
+	tenantRepo  *repository.TenantRepository
+	channels    *ChannelService
+	config      config.BillingConfig
+}
+
+func NewBillingService(planRepo *repository.PlanRepository, invoiceRepo *repository.InvoiceRepository, usageRepo *repository.UsageRepository,
+	tenantRepo *repository.TenantRepository, channels *ChannelService, billingConfig config.BillingConfig) *BillingService {
+	return &BillingService{
+		planRepo:    planRepo,
+		invoiceRepo: invoiceRepo,
+		usageRepo:   usageRepo,
+		tenantRepo:  tenantRepo,
+		channels:    channels,
+		config:      billingConfig,
+	}
+}
+
+// UpsertPlan creates or updates the plan of a billing tier. Invoices already issued keep
+// the prices they were issued with.
+func (s *BillingService) UpsertPlan(planDTO dto.PlanDTO) (*model.Plan, error) {
+	// Validation
+	if err := utils.ValidateAllowedValues(planDTO.BillingTier, "BillingTier", model.BillingTiers); err != nil {
+		return nil, err
+	}
+	currency := strings.ToUpper(planDTO.Currency)
+	if !currencyCode.MatchString(currency) {
+		return nil, &utils.ValidationError{Field: "Currency", Message: "Field must be a three-letter ISO 4217 code"}
+	}
+
+	// Convert DTO to model
+	plan := &model.Plan{
+		BillingTier:   planDTO.BillingTier,
+		Currency:      currency,
+		BasePrice:     planDTO.BasePrice,
+		ChannelPrices: []model.PlanChannelPrice{},
+	}
+	seen := make(map[string]bool)
+	for i, priceDTO := range planDTO.ChannelPrices {
+		field := fmt.Sprintf("ChannelPrices[%d]", i)
+		channel, err := s.channels.Normalize(priceDTO.Channel)
+		if err != nil {
+			return nil, withFieldPrefix(err, field)
+		}
+		if seen[channel] {
+			return nil, &utils.ValidationError{Field: field + ".Channel", Message: fmt.Sprintf("Duplicate channel %q", channel)}
+		}
+		seen[channel] = true
+
+		tiers, err := planOverageTierModels(priceDTO.OverageTiers)
+		if err != nil {
+			return nil, withFieldPrefix(err, field)
+		}
+		plan.ChannelPrices = append(plan.ChannelPrices, model.PlanChannelPrice{
+			Channel:          channel,
+			PricePerThousand: priceDTO.PricePerThousand,
+			OverageTiers:     tiers,
+		})
+	}
+
+	// Call repository to upsert the plan
+	if err := s.planRepo.Upsert(plan); err != nil {
+		logger.Error("Error upserting plan", zap.Error(err))
+		return nil, errors.New("failed to upsert plan")
+	}
+
+	return plan, nil
+}
+
+// planOverageTierModels converts overage tiers, which must have ascending bounds with
+// only the last one left unbounded.
+func planOverageTierModels(tierDTOs []dto.PlanOverageTierDTO) ([]model.PlanOverageTier, error) {
+	tiers := make([]model.PlanOverageTier, 0, len(tierDTOs))
+	previous := 0
+	for i, tierDTO := range tierDTOs {
+		field := fmt.Sprintf("OverageTiers[%d].UpTo", i)
+		last := i == len(tierDTOs)-1
+		if tierDTO.UpTo == 0 && !last {
+			return nil, &utils.ValidationError{Field: field, Message: "Only the last tier may be unbounded"}
+		}
+		if tierDTO.UpTo != 0 && tierDTO.UpTo <= previous {
+			return nil, &utils.ValidationError{Field: field, Message: fmt.Sprintf("Field must be greater than %d", previous)}
+		}
+		previous = tierDTO.UpTo
+		tiers = append(tiers, model.PlanOverageTier{UpTo: tierDTO.UpTo, PricePerThousand: tierDTO.PricePerThousand})
+	}
+	return tiers, nil
+}
+
+// GetPlans retrieves all plans.
+func (s *BillingService) GetPlans() ([]model.Plan, error) {
+	plans, err := s.planRepo.FindAll()
+	if err != nil {
+		logger.Error("Error fetching plans", zap.Error(err))
+		return nil, errors.New("failed to fetch plans")
+	}
+	return plans, nil
+}
+
+// GetInvoices retrieves a page of a tenant's invoices, newest period first, without line items.
+func (s *BillingService) GetInvoices(tenantID string, limit, offset int) ([]model.Invoice, int64, error) {
+	// Validation
+	if err := utils.ValidateNonEmptyString(tenantID, "TenantID"); err != nil {
+		return nil, 0, err
+	}
+
+	invoices, total, err := s.invoiceRepo.FindByTenantID(tenantID, limit, offset)
+	if err != nil {
+		logger.Error("Error fetching invoices", zap.Error(err))
+		return nil, 0, errors.New("failed to fetch invoices")
+	}
+	return invoices, total, nil
+}
+
+// GetInvoice retrieves an invoice of a tenant with its line items.
+func (s *BillingService) GetInvoice(tenantID string, id uint) (*model.Invoice, error) {
+	invoice, err := s.invoiceRepo.FindByID(tenantID, id)
+	if err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			return nil, fmt.Errorf("%w: invoice not found", pkgerr.ErrNotFound)
+		}
+		logger.Error("Error fetching invoice", zap.Error(err))
+		return nil, errors.New("failed to fetch invoice")
+	}
+	return invoice, nil
+}
+
+// IssueDueInvoices issues the invoices of every tenant's billing periods that ended at
+// least the configured delay ago and have no invoice yet, catching up on all periods since
+// the tenant's latest invoice, or just the last period for tenants without invoices. The
+// delay lets late usage reach the usage table before a period is billed.
+func (s *BillingService) IssueDueInvoices(ctx context.Context) {
+	tenants, err := s.tenantRepo.FindAll()
+	if err != nil {
+		logger.Error("Error fetching tenants for invoicing", zap.Error(err))
+		return
+	}
+
+	issued := 0
+	cutoff := time.Now().Add(-s.config.InvoiceDelay)
+	for i := range tenants {
+		if ctx.Err() != nil {
+			return
+		}
+		created, err := s.issueDueInvoices(&tenants[i], cutoff)
+		if err != nil {
+			logger.Error("Error issuing invoice", zap.Uint("tenant_id", tenants[i].ID), zap.Error(err))
+		}
+		issued += created
+	}
+	if issued > 0 {
+		logger.Info("Invoices issued", zap.Int("count", issued))
+	}
+}
+
+// issueDueInvoices issues the due invoices of a tenant in the order of their periods and
+// returns how many it issued. It stops at the first error, so that no period is skipped
+// by catching up from a later invoice.
+func (s *BillingService) issueDueInvoices(tenant *model.Tenant, cutoff time.Time) (int, error) {
+	loc := tenant.Location()
+	lastEnd := billingPeriodStart(cutoff.In(loc), tenant.BillingCycleAnchor)
+	start := lastEnd.AddDate(0, -1, 0)
+
+	latest, err := s.invoiceRepo.FindLatest(strconv.FormatUint(uint64(tenant.ID), 10))
+	switch {
+	case err == nil:
+		start = time.Date(latest.PeriodEnd.Year(), latest.PeriodEnd.Month(), latest.PeriodEnd.Day(), 0, 0, 0, 0, loc)
+	case !errors.Is(err, pkgerr.ErrNotFound):
+		return 0, err
+	}
+
+	issued := 0
+	for _, period := range dueBillingPeriods(start, lastEnd, tenant.BillingCycleAnchor) {
+		if !tenant.CreatedAt.Before(period[1]) {
+			continue
+		}
+		created, err := s.issueInvoice(tenant, model.UsageDate(period[0], loc), model.UsageDate(period[1], loc))
+		if err != nil {
+			return issued, err
+		}
+		if created {
+			issued++
+		}
+	}
+	return issued, nil
+}
+
+// dueBillingPeriods splits the time from start up to end into billing periods ending on
+// the anchor day. The first period is shorter if start is not on the anchor day, for
+// instance because the anchor changed since the previous invoice.
+func dueBillingPeriods(start, end time.Time, anchor int) [][2]time.Time {
+	var periods [][2]time.Time
+	for start.Before(end) {
+		periodEnd := billingPeriodStart(start.AddDate(0, 1, 0), anchor)
+		periods = append(periods, [2]time.Time{start, periodEnd})
+		start = periodEnd
+	}
+	return periods
+}
+
+// issueInvoice prices a tenant's usage in a billing period, given as calendar days like
+// Usage.Date, with the plan of its billing tier and stores the invoice, unless the period
+// has been invoiced already. It reports whether an invoice was issued.
+func (s *BillingService) issueInvoice(tenant *model.Tenant, periodStart, periodEnd time.Time) (bool, error) {
+	tenantID := strconv.FormatUint(uint64(tenant.ID), 10)
+	if _, err := s.invoiceRepo.FindByPeriod(tenantID, periodStart); err == nil {
+		return false, nil
+	} else if !errors.Is(err, pkgerr.ErrNotFound) {
+		return false, err
+	}
+
+	plan, err := s.planRepo.FindByBillingTier(tenant.BillingTier)
+	if err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			logger.Warn("No plan for billing tier, invoice skipped",
+				zap.String("tenant_id", tenantID), zap.String("billing_tier", tenant.BillingTier))
+			return false, nil
+		}
+		return false, err
+	}
+
+	usages, err := s.usageRepo.SumByChannel(tenantID, periodStart, periodEnd.AddDate(0, 0, -1))
+	if err != nil {
+		return false, err
+	}
+
+	invoice := &model.Invoice{
+		Number:      fmt.Sprintf("INV-%s-%s", tenantID, periodStart.Format("20060102")),
+		TenantID:    tenantID,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		BillingTier: plan.BillingTier,
+		Currency:    plan.Currency,
+		LineItems:   invoiceLineItems(plan, usages),
+		IssuedAt:    time.Now(),
+	}
+	for _, item := range invoice.LineItems {
+		invoice.Total += item.Amount
+	}
+
+	return s.invoiceRepo.CreateIfAbsent(invoice)
+}
+
+// invoiceLineItems prices usage summed per channel with a plan: the base price, then per
+// channel the notifications within quota at the unit price and billable overage through
+// the overage tiers. Channels without a price on the plan are listed free of charge.
+func invoiceLineItems(plan *model.Plan, usages []model.Usage) []model.InvoiceLineItem {
+	items := []model.InvoiceLineItem{{
+		Kind:        model.InvoiceLineBase,
+		Description: fmt.Sprintf("Base fee, %s plan", plan.BillingTier),
+		Quantity:    1,
+		Amount:      plan.BasePrice,
+	}}
+
+	prices := make(map[string]model.PlanChannelPrice, len(plan.ChannelPrices))
+	for _, price := range plan.ChannelPrices {
+		prices[price.Channel] = price
+	}
+
+	for _, usage := range usages {
+		price := prices[usage.Channel]
+		if included := usage.NotificationsSent - usage.OverageSent; included > 0 {
+			items = append(items, model.InvoiceLineItem{
+				Kind:             model.InvoiceLineUsage,
+				Channel:          usage.Channel,
+				Description:      fmt.Sprintf("%s notifications", usage.Channel),
+				Quantity:         included,
+				PricePerThousand: price.PricePerThousand,
+				Amount:           model.PriceUnits(included, price.PricePerThousand),
+			})
+		}
+		items = append(items, overageLineItems(usage.Channel, price, usage.OverageSent)...)
+	}
+	return items
+}
+
+// overageLineItems prices billable overage of a channel, one line per overage tier used.
+func overageLineItems(channel string, price model.PlanChannelPrice, overage int) []model.InvoiceLineItem {
+	tiers := price.OverageTiers
+	if len(tiers) == 0 {
+		tiers = []model.PlanOverageTier{{PricePerThousand: price.PricePerThousand}}
+	}
+
+	var items []model.InvoiceLineItem
+	floor := 0
+	for i, tier := range tiers {
+		if overage <= floor {
+			break
+		}
+		quantity := overage - floor
+		description := fmt.Sprintf("%s overage above %d", channel, floor)
+		if tier.UpTo != 0 && i < len(tiers)-1 {
+			quantity = min(quantity, tier.UpTo-floor)
+			description = fmt.Sprintf("%s overage %d-%d", channel, floor+1, tier.UpTo)
+		}
+		items = append(items, model.InvoiceLineItem{
+			Kind:             model.InvoiceLineOverage,
+			Channel:          channel,
+			Description:      description,
+			Quantity:         quantity,
+			PricePerThousand: tier.PricePerThousand,
+			Amount:           model.PriceUnits(quantity, tier.PricePerThousand),
+		})
+		floor += quantity
+	}
+	return items
+}
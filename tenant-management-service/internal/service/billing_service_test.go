@@ -0,0 +1,128 @@
+package service
+
+import (
+	"reflect"
+	"tenant-management-service/internal/model"
+	"testing"
+	"time"
+)
+
+func TestDueBillingPeriods(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name       string
+		start, end time.Time
+		anchor     int
+		want       [][2]time.Time
+	}{
+		{"nothing due", date(3, 1), date(3, 1), 1, nil},
+		{"one period", date(2, 1), date(3, 1), 1, [][2]time.Time{{date(2, 1), date(3, 1)}}},
+		{"catch up", date(1, 15), date(4, 15), 15, [][2]time.Time{
+			{date(1, 15), date(2, 15)},
+			{date(2, 15), date(3, 15)},
+			{date(3, 15), date(4, 15)},
+		}},
+		{"anchor moved later", date(1, 1), date(3, 20), 20, [][2]time.Time{
+			{date(1, 1), date(1, 20)},
+			{date(1, 20), date(2, 20)},
+			{date(2, 20), date(3, 20)},
+		}},
+		{"anchor moved earlier", date(1, 20), date(3, 5), 5, [][2]time.Time{
+			{date(1, 20), date(2, 5)},
+			{date(2, 5), date(3, 5)},
+		}},
+		{"invoiced ahead", date(4, 1), date(3, 1), 1, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dueBillingPeriods(tt.start, tt.end, tt.anchor); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dueBillingPeriods = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverageLineItems(t *testing.T) {
+	tiered := model.PlanChannelPrice{
+		Channel:          "sms",
+		PricePerThousand: 10000,
+		OverageTiers: []model.PlanOverageTier{
+			{UpTo: 1000, PricePerThousand: 15000},
+			{UpTo: 5000, PricePerThousand: 12000},
+			{PricePerThousand: 9000},
+		},
+	}
+	tests := []struct {
+		name    string
+		price   model.PlanChannelPrice
+		overage int
+		want    []model.InvoiceLineItem
+	}{
+		{"no overage", tiered, 0, nil},
+		{"first tier", tiered, 400, []model.InvoiceLineItem{
+			{Description: "sms overage 1-1000", Quantity: 400, PricePerThousand: 15000, Amount: 6000},
+		}},
+		{"exactly the first tier", tiered, 1000, []model.InvoiceLineItem{
+			{Description: "sms overage 1-1000", Quantity: 1000, PricePerThousand: 15000, Amount: 15000},
+		}},
+		{"every tier", tiered, 7500, []model.InvoiceLineItem{
+			{Description: "sms overage 1-1000", Quantity: 1000, PricePerThousand: 15000, Amount: 15000},
+			{Description: "sms overage 1001-5000", Quantity: 4000, PricePerThousand: 12000, Amount: 48000},
+			{Description: "sms overage above 5000", Quantity: 2500, PricePerThousand: 9000, Amount: 22500},
+		}},
+		{"last tier bounded", model.PlanChannelPrice{
+			PricePerThousand: 10000,
+			OverageTiers:     []model.PlanOverageTier{{UpTo: 100, PricePerThousand: 20000}},
+		}, 250, []model.InvoiceLineItem{
+			{Description: "sms overage above 0", Quantity: 250, PricePerThousand: 20000, Amount: 5000},
+		}},
+		{"unit price without tiers", model.PlanChannelPrice{PricePerThousand: 10000}, 3, []model.InvoiceLineItem{
+			{Description: "sms overage above 0", Quantity: 3, PricePerThousand: 10000, Amount: 30},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := range tt.want {
+				tt.want[i].Kind = model.InvoiceLineOverage
+				tt.want[i].Channel = "sms"
+			}
+			if got := overageLineItems("sms", tt.price, tt.overage); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("overageLineItems = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvoiceLineItems(t *testing.T) {
+	plan := &model.Plan{
+		BillingTier: "pro",
+		BasePrice:   4900,
+		ChannelPrices: []model.PlanChannelPrice{
+			{Channel: "email", PricePerThousand: 100},
+			{Channel: "sms", PricePerThousand: 10000, OverageTiers: []model.PlanOverageTier{
+				{UpTo: 100, PricePerThousand: 20000},
+				{PricePerThousand: 15000},
+			}},
+		},
+	}
+	usages := []model.Usage{
+		{Channel: "email", NotificationsSent: 12345},
+		{Channel: "sms", NotificationsSent: 1150, OverageSent: 150},
+		{Channel: "push", NotificationsSent: 10},
+		{Channel: "webhook", NotificationsSent: 0},
+	}
+
+	want := []model.InvoiceLineItem{
+		{Kind: model.InvoiceLineBase, Description: "Base fee, pro plan", Quantity: 1, Amount: 4900},
+		{Kind: model.InvoiceLineUsage, Channel: "email", Description: "email notifications", Quantity: 12345, PricePerThousand: 100, Amount: 1235},
+		{Kind: model.InvoiceLineUsage, Channel: "sms", Description: "sms notifications", Quantity: 1000, PricePerThousand: 10000, Amount: 10000},
+		{Kind: model.InvoiceLineOverage, Channel: "sms", Description: "sms overage 1-100", Quantity: 100, PricePerThousand: 20000, Amount: 2000},
+		{Kind: model.InvoiceLineOverage, Channel: "sms", Description: "sms overage above 100", Quantity: 50, PricePerThousand: 15000, Amount: 750},
+		{Kind: model.InvoiceLineUsage, Channel: "push", Description: "push notifications", Quantity: 10},
+	}
+	if got := invoiceLineItems(plan, usages); !reflect.DeepEqual(got, want) {
+		t.Errorf("invoiceLineItems = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"tenant-management-service/internal/model"
+	"tenant-management-service/internal/repository"
+	"tenant-management-service/pkg/broadcast"
+	"tenant-management-service/pkg/logger"
+	"time"
+)
+
+// changePollInterval bounds how long a watcher can miss events committed by
+// another instance, since the broadcaster only sees local writes.
+const changePollInterval = 2 * time.Second
+
+type ChangeService struct {
+	repo        *repository.ChangeRepository
+	transactor  *repository.Transactor
+	broadcaster *broadcast.Broadcaster
+}
+
+func NewChangeService(repo *repository.ChangeRepository, transactor *repository.Transactor, broadcaster *broadcast.Broadcaster) *ChangeService {
+	return &ChangeService{repo: repo, transactor: transactor, broadcaster: broadcaster}
+}
+
+// Commit runs fn in a database transaction that makes changes and records them with
+// RecordChanges, so the change events commit or roll back together with the changes.
+// Waiting watchers are woken up once the transaction committed.
+func (s *ChangeService) Commit(fn func(tx *repository.Tx) error) error {
+	if err := s.transactor.Transaction(fn); err != nil {
+		return err
+	}
+	s.broadcaster.Notify()
+	return nil
+}
+
+// RecordChanges appends a change event for every item within a transaction run by Commit.
+// keyFn extracts the key (config key, channel, ...) of each item.
+func RecordChanges[T any](tx *repository.Tx, tenantID, resource string, items []T, keyFn func(T) string) error {
+	var events []model.ChangeEvent
+	for _, item := range items {
+		payload, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		events = append(events, model.ChangeEvent{
+			TenantID: tenantID,
+			Resource: resource,
+			Key:      keyFn(item),
+			Payload:  payload,
+		})
+	}
+	return tx.Changes.Append(events)
+}
+
+// Poll returns the events newer than since. If there are none yet it blocks
+// until one is committed, the timeout elapses or ctx is cancelled, in which
+// case an empty slice is returned. An empty tenantID watches every tenant.
+func (s *ChangeService) Poll(ctx context.Context, tenantID string, since uint64, limit int, timeout time.Duration) ([]model.ChangeEvent, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(changePollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Grab the wait channel before querying so a commit in between is not missed
+		wake := s.broadcaster.Wait()
+
+		events, err := s.repo.FindSince(tenantID, since, limit)
+		if err != nil {
+			logger.Error("Error fetching change events", zap.Error(err))
+			return nil, errors.New("failed to fetch change events")
+		}
+		if len(events) > 0 {
+			return events, nil
+		}
+
+		select {
+		case <-wake:
+		case <-ticker.C:
+		case <-deadline.C:
+			return []model.ChangeEvent{}, nil
+		case <-ctx.Done():
+			return []model.ChangeEvent{}, nil
+		}
+	}
+}
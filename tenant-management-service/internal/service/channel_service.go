@@ -0,0 +1,199 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+	"sync"
+	"tenant-management-service/internal/model"
+	"tenant-management-service/internal/model/dto"
+	"tenant-management-service/internal/repository"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/utils"
+	"time"
+)
+
+// channelCacheTTL bounds how long the cached catalog is used before it is reloaded, so
+// that changes made through other instances become visible.
+const channelCacheTTL = 30 * time.Second
+
+// ChannelService manages the channel catalog and validates channel references against it.
+type ChannelService struct {
+	repo       *repository.ChannelRepository
+	tenantRepo *repository.TenantRepository
+
+	mu       sync.Mutex
+	cached   map[string]model.Channel
+	loadedAt time.Time
+}
+
+func NewChannelService(repo *repository.ChannelRepository, tenantRepo *repository.TenantRepository) *ChannelService {
+	return &ChannelService{repo: repo, tenantRepo: tenantRepo}
+}
+
+// UpsertChannel creates or updates a catalog channel. Channels are enabled unless stated otherwise.
+func (s *ChannelService) UpsertChannel(channelDTO dto.ChannelDTO) (*model.Channel, error) {
+	// Validation
+	code := strings.ToLower(strings.TrimSpace(channelDTO.Code))
+	if err := utils.ValidateSlug(code, "Code", 50); err != nil {
+		return nil, err
+	}
+	if strings.Contains(code, ".") {
+		return nil, &utils.ValidationError{Field: "Code", Message: "Field must not contain '.'"}
+	}
+	if err := utils.ValidateMaxLength(channelDTO.Name, "Name", 100); err != nil {
+		return nil, err
+	}
+	if err := utils.ValidateMaxLength(channelDTO.UnitOfMeasure, "UnitOfMeasure", 50); err != nil {
+		return nil, err
+	}
+
+	// Convert DTO to model
+	channel := &model.Channel{
+		Code:          code,
+		Name:          channelDTO.Name,
+		UnitOfMeasure: channelDTO.UnitOfMeasure,
+		Enabled:       channelDTO.Enabled == nil || *channelDTO.Enabled,
+		TierOverrides: []model.ChannelTierOverride{},
+	}
+	for tier, enabled := range channelDTO.TierOverrides {
+		if err := utils.ValidateAllowedValues(tier, "TierOverrides", model.BillingTiers); err != nil {
+			return nil, err
+		}
+		channel.TierOverrides = append(channel.TierOverrides, model.ChannelTierOverride{BillingTier: tier, Enabled: enabled})
+	}
+	sort.Slice(channel.TierOverrides, func(i, j int) bool {
+		return channel.TierOverrides[i].BillingTier < channel.TierOverrides[j].BillingTier
+	})
+
+	// Call repository to upsert the channel
+	if err := s.repo.Upsert(channel); err != nil {
+		logger.Error("Error upserting channel", zap.Error(err))
+		return nil, errors.New("failed to upsert channel")
+	}
+	s.invalidate()
+
+	return channel, nil
+}
+
+// GetChannels retrieves the whole channel catalog.
+func (s *ChannelService) GetChannels() ([]model.Channel, error) {
+	channels, err := s.repo.FindAll()
+	if err != nil {
+		logger.Error("Error fetching channels", zap.Error(err))
+		return nil, errors.New("failed to fetch channels")
+	}
+	return channels, nil
+}
+
+// GetAvailableChannels lists the catalog with the availability of each channel to a tenant.
+func (s *ChannelService) GetAvailableChannels(tenant *model.Tenant) ([]model.ChannelAvailability, error) {
+	channels, err := s.GetChannels()
+	if err != nil {
+		return nil, err
+	}
+
+	availability := make([]model.ChannelAvailability, 0, len(channels))
+	for _, channel := range channels {
+		availability = append(availability, model.ChannelAvailability{
+			Code:          channel.Code,
+			Name:          channel.Name,
+			UnitOfMeasure: channel.UnitOfMeasure,
+			Available:     channel.AvailableTo(tenant.BillingTier),
+		})
+	}
+	return availability, nil
+}
+
+// Normalize resolves a channel reference to the code of a catalog channel, ignoring case
+// and surrounding whitespace.
+func (s *ChannelService) Normalize(code string) (string, error) {
+	channel, err := s.lookup(code)
+	if err != nil {
+		return "", err
+	}
+	return channel.Code, nil
+}
+
+// ValidateForTier resolves a channel reference and checks that the channel is available
+// on a billing tier.
+func (s *ChannelService) ValidateForTier(code, billingTier string) (string, error) {
+	channel, err := s.lookup(code)
+	if err != nil {
+		return "", err
+	}
+	if !channel.AvailableTo(billingTier) {
+		return "", &utils.ValidationError{
+			Field:   "Channel",
+			Message: fmt.Sprintf("Channel %q is not available on the %s plan", channel.Code, billingTier),
+		}
+	}
+	return channel.Code, nil
+}
+
+// ValidateForTenant resolves a channel reference and checks that the channel is available
+// on the plan of a tenant.
+func (s *ChannelService) ValidateForTenant(tenantID, code string) (string, error) {
+	tenant, err := s.tenantRepo.FindByTenantID(tenantID)
+	if err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			return "", fmt.Errorf("%w: tenant not found", pkgerr.ErrNotFound)
+		}
+		logger.Error("Error fetching tenant", zap.Error(err))
+		return "", errors.New("failed to fetch tenant")
+	}
+	return s.ValidateForTier(code, tenant.BillingTier)
+}
+
+// lookup finds a catalog channel by a case-insensitive code.
+func (s *ChannelService) lookup(code string) (*model.Channel, error) {
+	catalog, err := s.catalog()
+	if err != nil {
+		return nil, err
+	}
+
+	channel, ok := catalog[strings.ToLower(strings.TrimSpace(code))]
+	if !ok {
+		codes := make([]string, 0, len(catalog))
+		for code := range catalog {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		return nil, &utils.ValidationError{
+			Field:   "Channel",
+			Message: fmt.Sprintf("Unknown channel %q, must be one of: %s", code, strings.Join(codes, ", ")),
+		}
+	}
+	return &channel, nil
+}
+
+// catalog returns the channel catalog keyed by code, reloading it once the cache is stale.
+func (s *ChannelService) catalog() (map[string]model.Channel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cached != nil && time.Since(s.loadedAt) < channelCacheTTL {
+		return s.cached, nil
+	}
+
+	channels, err := s.repo.FindAll()
+	if err != nil {
+		logger.Error("Error loading channel catalog", zap.Error(err))
+		return nil, errors.New("failed to load channel catalog")
+	}
+	s.cached = make(map[string]model.Channel, len(channels))
+	for _, channel := range channels {
+		s.cached[channel.Code] = channel
+	}
+	s.loadedAt = time.Now()
+	return s.cached, nil
+}
+
+func (s *ChannelService) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cached = nil
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"strconv"
+	"tenant-management-service/internal/model"
+	"tenant-management-service/internal/model/dto"
+	"tenant-management-service/internal/repository"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/utils"
+)
+
+type ConfigPresetService struct {
+	repo          *repository.ConfigPresetRepository
+	tenantRepo    *repository.TenantRepository
+	configRepo    *repository.ConfigRepository
+	configService *ConfigService
+}
+
+func NewConfigPresetService(repo *repository.ConfigPresetRepository, tenantRepo *repository.TenantRepository, configRepo *repository.ConfigRepository, configService *ConfigService) *ConfigPresetService {
+	return &ConfigPresetService{repo: repo, tenantRepo: tenantRepo, configRepo: configRepo, configService: configService}
+}
+
+// UpsertPreset creates or updates a configuration preset. Presets cannot carry
+// credentials, which are set per tenant.
+func (s *ConfigPresetService) UpsertPreset(presetDTO dto.ConfigPresetDTO) (*model.ConfigPreset, error) {
+	// Validation
+	if err := utils.ValidateSlug(presetDTO.Name, "Name", 100); err != nil {
+		return nil, err
+	}
+	if err := utils.ValidateMaxLength(presetDTO.Description, "Description", 255); err != nil {
+		return nil, err
+	}
+
+	// Convert DTO to model
+	preset := &model.ConfigPreset{Name: presetDTO.Name, Description: presetDTO.Description}
+	seen := make(map[string]bool)
+	for _, entry := range presetDTO.Entries {
+		configKey, err := s.configService.NormalizeConfigKey(entry.ConfigKey)
+		if err != nil {
+			return nil, err
+		}
+		entry.ConfigKey = configKey
+		if seen[entry.ConfigKey] {
+			return nil, &utils.ValidationError{Field: "Entries", Message: fmt.Sprintf("Duplicate config_key %q", entry.ConfigKey)}
+		}
+		seen[entry.ConfigKey] = true
+		if err := utils.ValidateMaxLength(entry.ConfigKey, "ConfigKey", 255); err != nil {
+			return nil, err
+		}
+		if model.IsSecretConfigKey(entry.ConfigKey) {
+			return nil, &utils.ValidationError{Field: "Entries", Message: fmt.Sprintf("config_key %q holds a credential, which presets cannot carry", entry.ConfigKey)}
+		}
+		if err := utils.ValidateMaxLength(entry.ConfigValue, "ConfigValue", maxConfigValueLength); err != nil {
+			return nil, err
+		}
+		preset.Entries = append(preset.Entries, model.ConfigPresetEntry{
+			ConfigKey:   entry.ConfigKey,
+			ConfigValue: entry.ConfigValue,
+			IsGlobal:    entry.IsGlobal,
+		})
+	}
+
+	// Call repository to upsert the preset
+	if err := s.repo.Upsert(preset); err != nil {
+		logger.Error("Error upserting configuration preset", zap.Error(err))
+		return nil, errors.New("failed to upsert configuration preset")
+	}
+
+	return preset, nil
+}
+
+// GetPresets retrieves all configuration presets.
+func (s *ConfigPresetService) GetPresets() ([]model.ConfigPreset, error) {
+	presets, err := s.repo.FindAll()
+	if err != nil {
+		logger.Error("Error fetching configuration presets", zap.Error(err))
+		return nil, errors.New("failed to fetch configuration presets")
+	}
+	return presets, nil
+}
+
+// DeletePreset deletes a configuration preset by name.
+func (s *ConfigPresetService) DeletePreset(name string) error {
+	if err := s.repo.DeleteByName(name); err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			return err
+		}
+		logger.Error("Error deleting configuration preset", zap.Error(err))
+		return errors.New("failed to delete configuration preset")
+	}
+	return nil
+}
+
+// ApplyPreset applies a preset to the selected tenants and reports, per tenant, which keys
+// were applied and which were skipped. Keys already holding the preset value are always
+// skipped; keys holding a different value are only updated when Overwrite is set. With
+// DryRun the report is computed without writing anything.
+func (s *ConfigPresetService) ApplyPreset(name string, applyDTO dto.ApplyPresetDTO) ([]model.PresetTenantReport, error) {
+	// Validation
+	if len(applyDTO.TenantIDs) == 0 && applyDTO.BillingTier == "" {
+		return nil, &utils.ValidationError{Field: "TenantIDs", Message: "Either tenant_ids or billing_tier must be provided"}
+	}
+	if applyDTO.BillingTier != "" {
+		if err := utils.ValidateAllowedValues(applyDTO.BillingTier, "BillingTier", model.BillingTiers); err != nil {
+			return nil, err
+		}
+	}
+
+	preset, err := s.repo.FindByName(name)
+	if err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			return nil, err
+		}
+		logger.Error("Error fetching configuration preset", zap.Error(err))
+		return nil, errors.New("failed to fetch configuration preset")
+	}
+
+	tenantIDs, reports, err := s.resolveTenants(applyDTO)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tenantID := range tenantIDs {
+		reports = append(reports, s.applyToTenant(preset, tenantID, applyDTO.Overwrite, applyDTO.DryRun))
+	}
+
+	logger.Info("Configuration preset applied",
+		zap.String("preset", name), zap.Int("tenants", len(tenantIDs)), zap.Bool("dry_run", applyDTO.DryRun))
+	return reports, nil
+}
+
+// resolveTenants returns the ids of the existing tenants selected by the request, along
+// with failure reports for explicitly listed tenants that do not exist.
+func (s *ConfigPresetService) resolveTenants(applyDTO dto.ApplyPresetDTO) ([]string, []model.PresetTenantReport, error) {
+	var tenantIDs []string
+	reports := []model.PresetTenantReport{}
+	seen := make(map[string]bool)
+
+	for _, tenantID := range applyDTO.TenantIDs {
+		if seen[tenantID] {
+			continue
+		}
+		seen[tenantID] = true
+
+		id, err := strconv.ParseUint(tenantID, 10, 64)
+		if err == nil {
+			_, err = s.tenantRepo.FindById(uint(id))
+		}
+		if err != nil {
+			reports = append(reports, model.PresetTenantReport{TenantID: tenantID, Error: "tenant not found"})
+			continue
+		}
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+
+	if applyDTO.BillingTier != "" {
+		tenants, err := s.tenantRepo.FindByBillingTier(applyDTO.BillingTier)
+		if err != nil {
+			logger.Error("Error fetching tenants by billing tier", zap.Error(err))
+			return nil, nil, errors.New("failed to fetch tenants")
+		}
+		for _, tenant := range tenants {
+			tenantID := strconv.FormatUint(uint64(tenant.ID), 10)
+			if !seen[tenantID] {
+				seen[tenantID] = true
+				tenantIDs = append(tenantIDs, tenantID)
+			}
+		}
+	}
+
+	return tenantIDs, reports, nil
+}
+
+// presetConfigChange is a configuration a preset writes to a tenant, in the form
+// UpsertConfigurations takes.
+type presetConfigChange = struct {
+	ConfigKey   string
+	ConfigValue string
+	IsGlobal    bool
+	IsSecret    bool
+}
+
+func (s *ConfigPresetService) applyToTenant(preset *model.ConfigPreset, tenantID string, overwrite, dryRun bool) model.PresetTenantReport {
+	configs, err := s.configRepo.FindByTenantId(tenantID)
+	if err != nil {
+		logger.Error("Error fetching configurations", zap.String("tenant_id", tenantID), zap.Error(err))
+		report := model.PresetTenantReport{TenantID: tenantID, Applied: []model.PresetKeyChange{}, Skipped: []model.PresetKeyChange{}}
+		report.Error = "failed to fetch configurations"
+		return report
+	}
+	return applyPresetEntries(preset.Entries, tenantID, configs, overwrite, dryRun, func(changes []presetConfigChange) error {
+		return s.configService.UpsertConfigurations(tenantID, changes)
+	})
+}
+
+// applyPresetEntries works out what preset entries change in a tenant's configurations
+// and, unless dryRun is set, writes the changes with write. If writing fails, every change
+// is reported as skipped.
+func applyPresetEntries(entries []model.ConfigPresetEntry, tenantID string, configs []model.Configuration, overwrite, dryRun bool,
+	write func(changes []presetConfigChange) error) model.PresetTenantReport {
+	report := model.PresetTenantReport{TenantID: tenantID, Applied: []model.PresetKeyChange{}, Skipped: []model.PresetKeyChange{}}
+
+	current := make(map[string]string, len(configs))
+	secret := make(map[string]bool)
+	for _, config := range configs {
+		current[config.ConfigKey] = config.ConfigValue
+		secret[config.ConfigKey] = config.IsSecret
+	}
+
+	// Work out what the preset changes for this tenant
+	var changes []presetConfigChange
+	for _, entry := range entries {
+		change := model.PresetKeyChange{ConfigKey: entry.ConfigKey, NewValue: entry.ConfigValue}
+		oldValue, exists := current[entry.ConfigKey]
+		change.OldValue = oldValue
+		if secret[entry.ConfigKey] {
+			change.OldValue = model.SecretMask
+			change.NewValue = model.SecretMask
+		}
+
+		switch {
+		case !exists:
+			change.Action = model.PresetActionCreate
+		case oldValue == entry.ConfigValue:
+			change.Action = model.PresetActionSkip
+			change.Reason = "unchanged"
+		case !overwrite:
+			change.Action = model.PresetActionSkip
+			change.Reason = "exists"
+		default:
+			change.Action = model.PresetActionUpdate
+		}
+
+		if change.Action == model.PresetActionSkip {
+			report.Skipped = append(report.Skipped, change)
+			continue
+		}
+		report.Applied = append(report.Applied, change)
+		changes = append(changes, presetConfigChange{entry.ConfigKey, entry.ConfigValue, entry.IsGlobal, secret[entry.ConfigKey]})
+	}
+
+	if dryRun || len(changes) == 0 {
+		return report
+	}
+
+	if err := write(changes); err != nil {
+		report.Error = err.Error()
+		for _, change := range report.Applied {
+			change.Reason = "failed"
+			report.Skipped = append(report.Skipped, change)
+		}
+		report.Applied = []model.PresetKeyChange{}
+	}
+	return report
+}
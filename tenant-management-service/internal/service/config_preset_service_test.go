@@ -0,0 +1,122 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"tenant-management-service/internal/model"
+	"testing"
+)
+
+func TestApplyPresetEntries(t *testing.T) {
+	entries := []model.ConfigPresetEntry{
+		{ConfigKey: "locale", ConfigValue: "en"},
+		{ConfigKey: "timezone", ConfigValue: "UTC", IsGlobal: true},
+		{ConfigKey: "theme", ConfigValue: "dark"},
+		{ConfigKey: "api.token", ConfigValue: "preset"},
+	}
+	configs := []model.Configuration{
+		{ConfigKey: "timezone", ConfigValue: "UTC"},
+		{ConfigKey: "theme", ConfigValue: "light"},
+		{ConfigKey: "api.token", ConfigValue: "tenant", IsSecret: true},
+	}
+
+	tests := []struct {
+		name      string
+		overwrite bool
+		dryRun    bool
+		writeErr  error
+		applied   []model.PresetKeyChange
+		skipped   []model.PresetKeyChange
+		written   []presetConfigChange
+		err       string
+	}{
+		{
+			name: "without overwrite",
+			applied: []model.PresetKeyChange{
+				{ConfigKey: "locale", Action: model.PresetActionCreate, NewValue: "en"},
+			},
+			skipped: []model.PresetKeyChange{
+				{ConfigKey: "timezone", Action: model.PresetActionSkip, Reason: "unchanged", OldValue: "UTC", NewValue: "UTC"},
+				{ConfigKey: "theme", Action: model.PresetActionSkip, Reason: "exists", OldValue: "light", NewValue: "dark"},
+				{ConfigKey: "api.token", Action: model.PresetActionSkip, Reason: "exists", OldValue: model.SecretMask, NewValue: model.SecretMask},
+			},
+			written: []presetConfigChange{{ConfigKey: "locale", ConfigValue: "en"}},
+		},
+		{
+			name:      "with overwrite",
+			overwrite: true,
+			applied: []model.PresetKeyChange{
+				{ConfigKey: "locale", Action: model.PresetActionCreate, NewValue: "en"},
+				{ConfigKey: "theme", Action: model.PresetActionUpdate, OldValue: "light", NewValue: "dark"},
+				{ConfigKey: "api.token", Action: model.PresetActionUpdate, OldValue: model.SecretMask, NewValue: model.SecretMask},
+			},
+			skipped: []model.PresetKeyChange{
+				{ConfigKey: "timezone", Action: model.PresetActionSkip, Reason: "unchanged", OldValue: "UTC", NewValue: "UTC"},
+			},
+			written: []presetConfigChange{
+				{ConfigKey: "locale", ConfigValue: "en"},
+				{ConfigKey: "theme", ConfigValue: "dark"},
+				{ConfigKey: "api.token", ConfigValue: "preset", IsSecret: true},
+			},
+		},
+		{
+			name:   "dry run",
+			dryRun: true,
+			applied: []model.PresetKeyChange{
+				{ConfigKey: "locale", Action: model.PresetActionCreate, NewValue: "en"},
+			},
+			skipped: []model.PresetKeyChange{
+				{ConfigKey: "timezone", Action: model.PresetActionSkip, Reason: "unchanged", OldValue: "UTC", NewValue: "UTC"},
+				{ConfigKey: "theme", Action: model.PresetActionSkip, Reason: "exists", OldValue: "light", NewValue: "dark"},
+				{ConfigKey: "api.token", Action: model.PresetActionSkip, Reason: "exists", OldValue: model.SecretMask, NewValue: model.SecretMask},
+			},
+		},
+		{
+			name:     "write fails",
+			writeErr: errors.New("failed to upsert configurations"),
+			applied:  []model.PresetKeyChange{},
+			skipped: []model.PresetKeyChange{
+				{ConfigKey: "timezone", Action: model.PresetActionSkip, Reason: "unchanged", OldValue: "UTC", NewValue: "UTC"},
+				{ConfigKey: "theme", Action: model.PresetActionSkip, Reason: "exists", OldValue: "light", NewValue: "dark"},
+				{ConfigKey: "api.token", Action: model.PresetActionSkip, Reason: "exists", OldValue: model.SecretMask, NewValue: model.SecretMask},
+				{ConfigKey: "locale", Action: model.PresetActionCreate, Reason: "failed", NewValue: "en"},
+			},
+			written: []presetConfigChange{{ConfigKey: "locale", ConfigValue: "en"}},
+			err:     "failed to upsert configurations",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var written []presetConfigChange
+			report := applyPresetEntries(entries, "42", configs, tt.overwrite, tt.dryRun, func(changes []presetConfigChange) error {
+				written = changes
+				return tt.writeErr
+			})
+
+			if report.TenantID != "42" || report.Error != tt.err {
+				t.Errorf("report tenant %q, error %q, want 42, %q", report.TenantID, report.Error, tt.err)
+			}
+			if !reflect.DeepEqual(report.Applied, tt.applied) {
+				t.Errorf("applied = %+v, want %+v", report.Applied, tt.applied)
+			}
+			if !reflect.DeepEqual(report.Skipped, tt.skipped) {
+				t.Errorf("skipped = %+v, want %+v", report.Skipped, tt.skipped)
+			}
+			if !reflect.DeepEqual(written, tt.written) {
+				t.Errorf("written = %+v, want %+v", written, tt.written)
+			}
+		})
+	}
+}
+
+func TestApplyPresetEntriesWithoutChanges(t *testing.T) {
+	entries := []model.ConfigPresetEntry{{ConfigKey: "locale", ConfigValue: "en"}}
+	configs := []model.Configuration{{ConfigKey: "locale", ConfigValue: "en"}}
+	report := applyPresetEntries(entries, "42", configs, true, false, func([]presetConfigChange) error {
+		t.Error("wrote configurations although nothing changed")
+		return nil
+	})
+	if len(report.Applied) != 0 || len(report.Skipped) != 1 {
+		t.Errorf("report = %+v, want one skipped key", report)
+	}
+}
@@ -1,27 +1,46 @@
 package service
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"go.uber.org/zap"
+	"strings"
 	"tenant-management-service/internal/model"
 	"tenant-management-service/internal/repository"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/jsonpointer"
+	"tenant-management-service/pkg/jsonschema"
 	"tenant-management-service/pkg/logger"
 	"tenant-management-service/pkg/utils"
 )
 
+// MaxJSONConfigSize is the largest structured configuration value accepted, in bytes.
+const MaxJSONConfigSize = 1 << 20
+
+// maxConfigValueLength is the largest plain configuration value accepted, in bytes, the
+// capacity of its text column.
+const maxConfigValueLength = 65535
+
 type ConfigService struct {
-	repo *repository.ConfigRepository
+	repo       *repository.ConfigRepository
+	schemaRepo *repository.ConfigSchemaRepository
+	channels   *ChannelService
+	changes    *ChangeService
 }
 
-func NewConfigService(repo *repository.ConfigRepository) *ConfigService {
-	return &ConfigService{repo: repo}
+func NewConfigService(repo *repository.ConfigRepository, schemaRepo *repository.ConfigSchemaRepository, channels *ChannelService,
+	changes *ChangeService) *ConfigService {
+	return &ConfigService{repo: repo, schemaRepo: schemaRepo, channels: channels, changes: changes}
 }
 
-// UpsertConfigurations creates or updates configurations for a tenant.
+// UpsertConfigurations creates or updates configurations for a tenant. Configurations of
+// credential settings are secret whether or not they are marked so.
 func (s *ConfigService) UpsertConfigurations(tenantID string, configs []struct {
 	ConfigKey   string
 	ConfigValue string
 	IsGlobal    bool
+	IsSecret    bool
 }) error {
 
 	// Validation
@@ -32,16 +51,32 @@ func (s *ConfigService) UpsertConfigurations(tenantID string, configs []struct {
 	// Convert input to model
 	var configModels []model.Configuration
 	for _, config := range configs {
+		configKey, err := s.NormalizeConfigKey(config.ConfigKey)
+		if err != nil {
+			return err
+		}
+		if err := utils.ValidateMaxLength(config.ConfigValue, "ConfigValue", maxConfigValueLength); err != nil {
+			return err
+		}
 		configModels = append(configModels, model.Configuration{
 			TenantID:    tenantID,
-			ConfigKey:   config.ConfigKey,
+			ConfigKey:   configKey,
 			ConfigValue: config.ConfigValue,
 			IsGlobal:    config.IsGlobal,
+			IsSecret:    config.IsSecret || model.IsSecretConfigKey(configKey),
 		})
 	}
 
-	// Call repository to upsert configurations
-	if err := s.repo.Upsert(configModels); err != nil {
+	// Upsert the configurations and publish them to watchers in one transaction
+	err := s.changes.Commit(func(tx *repository.Tx) error {
+		if err := tx.Configs.Upsert(configModels); err != nil {
+			return err
+		}
+		return RecordChanges(tx, tenantID, model.ChangeResourceConfig, maskConfigurations(configModels), func(c model.Configuration) string {
+			return c.ConfigKey
+		})
+	})
+	if err != nil {
 		logger.Error("Error upserting configurations", zap.Error(err))
 		return errors.New("failed to upsert configurations")
 	}
@@ -49,7 +84,7 @@ func (s *ConfigService) UpsertConfigurations(tenantID string, configs []struct {
 	return nil
 }
 
-// GetConfigurations retrieves configurations for a tenant.
+// GetConfigurations retrieves configurations for a tenant with secret values masked.
 func (s *ConfigService) GetConfigurations(tenantId string) ([]model.Configuration, error) {
 
 	// Validation
@@ -64,5 +99,212 @@ func (s *ConfigService) GetConfigurations(tenantId string) ([]model.Configuratio
 		return nil, errors.New("failed to fetch configurations")
 	}
 
-	return configs, nil
+	return maskConfigurations(configs), nil
+}
+
+// maskConfigurations masks the values of secret configurations.
+func maskConfigurations(configs []model.Configuration) []model.Configuration {
+	masked := make([]model.Configuration, len(configs))
+	for i, config := range configs {
+		masked[i] = config.Masked()
+	}
+	return masked
+}
+
+// GetJSONConfiguration retrieves the structured value of a tenant's configuration key, or
+// the sub-document selected by a JSON pointer. Secret values are returned masked.
+func (s *ConfigService) GetJSONConfiguration(tenantID, configKey, pointer string) (interface{}, error) {
+
+	// Validation
+	if err := utils.ValidateNonEmptyString(tenantID, "tenantId"); err != nil {
+		return nil, err
+	}
+	path, err := jsonpointer.Parse(pointer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", pkgerr.ErrInvalidInput, err)
+	}
+	if configKey, err = s.NormalizeConfigKey(configKey); err != nil {
+		return nil, err
+	}
+
+	// Fetch configuration from repository
+	config, err := s.repo.FindByTenantIdAndKey(tenantID, configKey)
+	if err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			return nil, err
+		}
+		logger.Error("Error fetching configuration", zap.Error(err))
+		return nil, errors.New("failed to fetch configuration")
+	}
+	if config.JSONValue == nil {
+		return nil, fmt.Errorf("%w: configuration has no JSON value", pkgerr.ErrNotFound)
+	}
+	if config.IsSecret {
+		return model.SecretMask, nil
+	}
+
+	doc, err := utils.DecodeJSON(config.JSONValue)
+	if err != nil {
+		logger.Error("Error decoding stored JSON configuration", zap.String("config_key", configKey), zap.Error(err))
+		return nil, errors.New("failed to decode configuration")
+	}
+
+	value, err := path.Get(doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", pkgerr.ErrNotFound, err)
+	}
+	return value, nil
+}
+
+// SetJSONConfiguration stores a structured value for a tenant's configuration key. With an
+// empty pointer the whole value is replaced, otherwise only the sub-document at the pointer
+// is written. The resulting document must match the JSON Schema registered for the key.
+func (s *ConfigService) SetJSONConfiguration(tenantID, configKey, pointer string, value []byte) (*model.Configuration, error) {
+
+	// Validation
+	if err := utils.ValidateNonEmptyString(tenantID, "tenantId"); err != nil {
+		return nil, err
+	}
+	if len(value) > MaxJSONConfigSize {
+		return nil, fmt.Errorf("%w: value exceeds %d bytes", pkgerr.ErrInvalidInput, MaxJSONConfigSize)
+	}
+	path, err := jsonpointer.Parse(pointer)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", pkgerr.ErrInvalidInput, err)
+	}
+	newValue, err := utils.DecodeJSON(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", pkgerr.ErrInvalidInput, err)
+	}
+	if configKey, err = s.NormalizeConfigKey(configKey); err != nil {
+		return nil, err
+	}
+
+	schema, err := s.loadSchema(configKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply the change under a row lock so concurrent patches do not overwrite each other,
+	// publishing the result to watchers in the same transaction
+	var config *model.Configuration
+	err = s.changes.Commit(func(tx *repository.Tx) error {
+		updated, err := tx.Configs.UpdateJSON(tenantID, configKey, func(config *model.Configuration) error {
+			doc := newValue
+			if len(path) > 0 {
+				if config.JSONValue == nil {
+					return fmt.Errorf("%w: configuration has no JSON value to patch", pkgerr.ErrNotFound)
+				}
+				current, err := utils.DecodeJSON(config.JSONValue)
+				if err != nil {
+					return err
+				}
+				if doc, err = path.Set(current, newValue); err != nil {
+					return fmt.Errorf("%w: %s", pkgerr.ErrInvalidInput, err)
+				}
+			}
+
+			if err := schema.Validate(doc); err != nil {
+				return fmt.Errorf("%w: %s", pkgerr.ErrInvalidInput, err)
+			}
+
+			encoded, err := json.Marshal(doc)
+			if err != nil {
+				return err
+			}
+			if len(encoded) > MaxJSONConfigSize {
+				return fmt.Errorf("%w: value exceeds %d bytes", pkgerr.ErrInvalidInput, MaxJSONConfigSize)
+			}
+			config.JSONValue = encoded
+			config.IsSecret = config.IsSecret || model.IsSecretConfigKey(configKey)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		config = updated
+		return RecordChanges(tx, tenantID, model.ChangeResourceConfig, []model.Configuration{config.Masked()}, func(c model.Configuration) string {
+			return c.ConfigKey
+		})
+	})
+	if err != nil {
+		if errors.Is(err, pkgerr.ErrInvalidInput) || errors.Is(err, pkgerr.ErrNotFound) {
+			return nil, err
+		}
+		logger.Error("Error storing JSON configuration", zap.Error(err))
+		return nil, errors.New("failed to store configuration")
+	}
+
+	masked := config.Masked()
+	return &masked, nil
+}
+
+// NormalizeConfigKey checks that a configuration key under the channel settings prefix
+// names a catalog channel and a setting, and rewrites the channel to its catalog code.
+// Other keys are returned unchanged.
+func (s *ConfigService) NormalizeConfigKey(configKey string) (string, error) {
+	if !strings.HasPrefix(configKey, model.ChannelConfigPrefix) {
+		return configKey, nil
+	}
+
+	code, setting, found := strings.Cut(strings.TrimPrefix(configKey, model.ChannelConfigPrefix), ".")
+	if !found || setting == "" {
+		return "", &utils.ValidationError{
+			Field:   "ConfigKey",
+			Message: fmt.Sprintf("Keys under %q must have the form %s<channel>.<setting>", model.ChannelConfigPrefix, model.ChannelConfigPrefix),
+		}
+	}
+	code, err := s.channels.Normalize(code)
+	if err != nil {
+		return "", err
+	}
+	return model.ChannelConfigPrefix + code + "." + setting, nil
+}
+
+// UpsertConfigSchema registers or replaces the JSON Schema of a configuration key.
+func (s *ConfigService) UpsertConfigSchema(configKey string, schema []byte) (*model.ConfigSchema, error) {
+
+	// Validation
+	if err := utils.ValidateNonEmptyString(configKey, "configKey"); err != nil {
+		return nil, err
+	}
+	if _, err := jsonschema.Compile(schema); err != nil {
+		return nil, fmt.Errorf("%w: %s", pkgerr.ErrInvalidInput, err)
+	}
+
+	configSchema := &model.ConfigSchema{ConfigKey: configKey, Schema: schema}
+	if err := s.schemaRepo.Upsert(configSchema); err != nil {
+		logger.Error("Error upserting configuration schema", zap.Error(err))
+		return nil, errors.New("failed to upsert configuration schema")
+	}
+
+	return configSchema, nil
+}
+
+// GetConfigSchemas retrieves all registered configuration schemas.
+func (s *ConfigService) GetConfigSchemas() ([]model.ConfigSchema, error) {
+	schemas, err := s.schemaRepo.FindAll()
+	if err != nil {
+		logger.Error("Error fetching configuration schemas", zap.Error(err))
+		return nil, errors.New("failed to fetch configuration schemas")
+	}
+	return schemas, nil
+}
+
+func (s *ConfigService) loadSchema(configKey string) (*jsonschema.Schema, error) {
+	configSchema, err := s.schemaRepo.FindByKey(configKey)
+	if err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			return nil, fmt.Errorf("%w: no JSON schema registered for key %q", pkgerr.ErrInvalidInput, configKey)
+		}
+		logger.Error("Error fetching configuration schema", zap.Error(err))
+		return nil, errors.New("failed to fetch configuration schema")
+	}
+
+	schema, err := jsonschema.Compile(configSchema.Schema)
+	if err != nil {
+		logger.Error("Stored configuration schema is invalid", zap.String("config_key", configKey), zap.Error(err))
+		return nil, errors.New("failed to load configuration schema")
+	}
+	return schema, nil
 }
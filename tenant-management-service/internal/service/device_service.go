@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"strings"
+	"tenant-management-service/internal/model"
+	"tenant-management-service/internal/model/dto"
+	"tenant-management-service/internal/repository"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/utils"
+)
+
+// DeviceService manages the device tokens push notifications are delivered to.
+type DeviceService struct {
+	repo *repository.DeviceRepository
+}
+
+func NewDeviceService(repo *repository.DeviceRepository) *DeviceService {
+	return &DeviceService{repo: repo}
+}
+
+// RegisterDevice registers a device token for a recipient of a tenant. Registering a token
+// again moves it to the given recipient.
+func (s *DeviceService) RegisterDevice(tenantID string, deviceDTO dto.DeviceTokenDTO) (*model.DeviceToken, error) {
+	// Validation
+	platform := strings.ToLower(deviceDTO.Platform)
+	if err := utils.ValidateAllowedValues(platform, "Platform", model.DevicePlatforms); err != nil {
+		return nil, err
+	}
+	if err := utils.ValidateMaxLength(deviceDTO.Recipient, "Recipient", 255); err != nil {
+		return nil, err
+	}
+	if err := utils.ValidateMaxLength(deviceDTO.Token, "Token", 255); err != nil {
+		return nil, err
+	}
+
+	// Convert DTO to model
+	device := &model.DeviceToken{
+		TenantID:  tenantID,
+		Recipient: deviceDTO.Recipient,
+		Platform:  platform,
+		Token:     strings.TrimSpace(deviceDTO.Token),
+	}
+
+	// Call repository to register the device
+	if err := s.repo.Upsert(device); err != nil {
+		logger.Error("Error registering device token", zap.Error(err))
+		return nil, errors.New("failed to register device token")
+	}
+	return device, nil
+}
+
+// GetDevices retrieves the device tokens registered for a recipient of a tenant.
+func (s *DeviceService) GetDevices(tenantID, recipient string) ([]model.DeviceToken, error) {
+	// Validation
+	if err := utils.ValidateNonEmptyString(recipient, "Recipient"); err != nil {
+		return nil, err
+	}
+
+	devices, err := s.repo.FindByRecipient(tenantID, recipient)
+	if err != nil {
+		logger.Error("Error fetching device tokens", zap.Error(err))
+		return nil, errors.New("failed to fetch device tokens")
+	}
+	return devices, nil
+}
+
+// DeleteDevice unregisters a device token of a tenant.
+func (s *DeviceService) DeleteDevice(tenantID string, id uint) error {
+	if err := s.repo.Delete(tenantID, id); err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			return fmt.Errorf("%w: device token not found", pkgerr.ErrNotFound)
+		}
+		logger.Error("Error deleting device token", zap.Error(err))
+		return errors.New("failed to delete device token")
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"tenant-management-service/internal/model"
+	"tenant-management-service/internal/model/dto"
+	"tenant-management-service/internal/repository"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/utils"
+)
+
+type FeatureFlagService struct {
+	repo       *repository.FeatureFlagRepository
+	configRepo *repository.ConfigRepository
+}
+
+func NewFeatureFlagService(repo *repository.FeatureFlagRepository, configRepo *repository.ConfigRepository) *FeatureFlagService {
+	return &FeatureFlagService{repo: repo, configRepo: configRepo}
+}
+
+// UpsertFlag creates or updates a feature flag definition.
+func (s *FeatureFlagService) UpsertFlag(flagDTO dto.FeatureFlagDTO) (*model.FeatureFlag, error) {
+	// Validation
+	if err := utils.ValidateSlug(flagDTO.Key, "Key", 100); err != nil {
+		return nil, err
+	}
+	if err := utils.ValidateMaxLength(flagDTO.Description, "Description", 255); err != nil {
+		return nil, err
+	}
+
+	// Convert DTO to model
+	flag := &model.FeatureFlag{
+		Key:               flagDTO.Key,
+		Description:       flagDTO.Description,
+		DefaultEnabled:    flagDTO.DefaultEnabled,
+		RolloutPercentage: flagDTO.RolloutPercentage,
+		TierOverrides:     []model.FeatureFlagTierOverride{},
+	}
+	for tier, enabled := range flagDTO.TierOverrides {
+		if err := utils.ValidateAllowedValues(tier, "TierOverrides", model.BillingTiers); err != nil {
+			return nil, err
+		}
+		flag.TierOverrides = append(flag.TierOverrides, model.FeatureFlagTierOverride{BillingTier: tier, Enabled: enabled})
+	}
+	sort.Slice(flag.TierOverrides, func(i, j int) bool {
+		return flag.TierOverrides[i].BillingTier < flag.TierOverrides[j].BillingTier
+	})
+
+	// Call repository to upsert the flag
+	if err := s.repo.Upsert(flag); err != nil {
+		logger.Error("Error upserting feature flag", zap.Error(err))
+		return nil, errors.New("failed to upsert feature flag")
+	}
+
+	return flag, nil
+}
+
+// GetFlags retrieves all feature flag definitions.
+func (s *FeatureFlagService) GetFlags() ([]model.FeatureFlag, error) {
+	flags, err := s.repo.FindAll()
+	if err != nil {
+		logger.Error("Error fetching feature flags", zap.Error(err))
+		return nil, errors.New("failed to fetch feature flags")
+	}
+	return flags, nil
+}
+
+// DeleteFlag deletes a feature flag definition by its key.
+func (s *FeatureFlagService) DeleteFlag(key string) error {
+	if err := s.repo.DeleteByKey(key); err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			return err
+		}
+		logger.Error("Error deleting feature flag", zap.Error(err))
+		return errors.New("failed to delete feature flag")
+	}
+	return nil
+}
+
+// EvaluateFlags resolves every flag for a tenant. A tenant override in configuration wins
+// over a billing tier override, which wins over the percentage rollout and finally the default.
+func (s *FeatureFlagService) EvaluateFlags(tenant *model.Tenant) ([]model.FlagEvaluation, error) {
+	flags, err := s.repo.FindAll()
+	if err != nil {
+		logger.Error("Error fetching feature flags", zap.Error(err))
+		return nil, errors.New("failed to fetch feature flags")
+	}
+
+	tenantID := strconv.FormatUint(uint64(tenant.ID), 10)
+	configs, err := s.configRepo.FindByTenantId(tenantID)
+	if err != nil {
+		logger.Error("Error fetching configurations", zap.Error(err))
+		return nil, errors.New("failed to fetch configurations")
+	}
+
+	// Collect per-tenant overrides from configuration
+	tenantOverrides := make(map[string]bool)
+	for _, config := range configs {
+		if !strings.HasPrefix(config.ConfigKey, model.FeatureFlagConfigPrefix) {
+			continue
+		}
+		enabled, err := strconv.ParseBool(config.ConfigValue)
+		if err != nil {
+			logger.Warn("Ignoring non-boolean feature flag override",
+				zap.String("tenant_id", tenantID), zap.String("config_key", config.ConfigKey))
+			continue
+		}
+		tenantOverrides[strings.TrimPrefix(config.ConfigKey, model.FeatureFlagConfigPrefix)] = enabled
+	}
+
+	evaluations := make([]model.FlagEvaluation, 0, len(flags))
+	for _, flag := range flags {
+		evaluations = append(evaluations, evaluateFlag(flag, tenantID, tenant.BillingTier, tenantOverrides))
+	}
+	return evaluations, nil
+}
+
+func evaluateFlag(flag model.FeatureFlag, tenantID, billingTier string, tenantOverrides map[string]bool) model.FlagEvaluation {
+	if enabled, ok := tenantOverrides[flag.Key]; ok {
+		return model.FlagEvaluation{Key: flag.Key, Enabled: enabled, Reason: model.FlagReasonTenantOverride}
+	}
+	for _, override := range flag.TierOverrides {
+		if override.BillingTier == billingTier {
+			return model.FlagEvaluation{Key: flag.Key, Enabled: override.Enabled, Reason: model.FlagReasonTierOverride}
+		}
+	}
+	if flag.RolloutPercentage != nil {
+		enabled := rolloutBucket(flag.Key, tenantID) < *flag.RolloutPercentage
+		return model.FlagEvaluation{Key: flag.Key, Enabled: enabled, Reason: model.FlagReasonRollout}
+	}
+	return model.FlagEvaluation{Key: flag.Key, Enabled: flag.DefaultEnabled, Reason: model.FlagReasonDefault}
+}
+
+// rolloutBucket deterministically maps a tenant to a bucket in [0, 100) per flag, so raising
+// the percentage only ever adds tenants and different flags roll out to different tenants.
+func rolloutBucket(flagKey, tenantID string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(flagKey + ":" + tenantID))
+	return int(h.Sum32() % 100)
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"tenant-management-service/internal/model"
+	"testing"
+)
+
+func TestEvaluateFlag(t *testing.T) {
+	proTier := []model.FeatureFlagTierOverride{{BillingTier: "pro", Enabled: true}, {BillingTier: "free", Enabled: false}}
+	tests := []struct {
+		name      string
+		flag      model.FeatureFlag
+		tier      string
+		overrides map[string]bool
+		want      model.FlagEvaluation
+	}{
+		{
+			name:      "tenant override beats tier override",
+			flag:      model.FeatureFlag{Key: "beta", TierOverrides: proTier, RolloutPercentage: ptr(100), DefaultEnabled: true},
+			tier:      "pro",
+			overrides: map[string]bool{"beta": false},
+			want:      model.FlagEvaluation{Key: "beta", Enabled: false, Reason: model.FlagReasonTenantOverride},
+		},
+		{
+			name:      "tier override beats rollout",
+			flag:      model.FeatureFlag{Key: "beta", TierOverrides: proTier, RolloutPercentage: ptr(100)},
+			tier:      "free",
+			overrides: map[string]bool{"other": true},
+			want:      model.FlagEvaluation{Key: "beta", Enabled: false, Reason: model.FlagReasonTierOverride},
+		},
+		{
+			name: "rollout beats default",
+			flag: model.FeatureFlag{Key: "beta", TierOverrides: proTier, RolloutPercentage: ptr(0), DefaultEnabled: true},
+			tier: "enterprise",
+			want: model.FlagEvaluation{Key: "beta", Enabled: false, Reason: model.FlagReasonRollout},
+		},
+		{
+			name: "full rollout",
+			flag: model.FeatureFlag{Key: "beta", RolloutPercentage: ptr(100)},
+			tier: "free",
+			want: model.FlagEvaluation{Key: "beta", Enabled: true, Reason: model.FlagReasonRollout},
+		},
+		{
+			name: "default",
+			flag: model.FeatureFlag{Key: "beta", TierOverrides: proTier, DefaultEnabled: true},
+			tier: "enterprise",
+			want: model.FlagEvaluation{Key: "beta", Enabled: true, Reason: model.FlagReasonDefault},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := evaluateFlag(tt.flag, "42", tt.tier, tt.overrides); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("evaluateFlag = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateFlagRolloutBoundary(t *testing.T) {
+	bucket := rolloutBucket("beta", "42")
+	tests := map[int]bool{0: false, bucket: false, bucket + 1: true, 100: true}
+	for percentage, want := range tests {
+		flag := model.FeatureFlag{Key: "beta", RolloutPercentage: ptr(percentage)}
+		if got := evaluateFlag(flag, "42", "", nil); got.Enabled != want {
+			t.Errorf("rollout %d%% with bucket %d: enabled = %v, want %v", percentage, bucket, got.Enabled, want)
+		}
+	}
+}
+
+func TestRolloutBucket(t *testing.T) {
+	seen := make(map[int]bool)
+	differs := false
+	for i := 0; i < 1000; i++ {
+		tenantID := fmt.Sprint(i)
+		bucket := rolloutBucket("beta", tenantID)
+		if bucket < 0 || bucket > 99 {
+			t.Fatalf("rolloutBucket(beta, %s) = %d, want 0-99", tenantID, bucket)
+		}
+		if again := rolloutBucket("beta", tenantID); again != bucket {
+			t.Fatalf("rolloutBucket(beta, %s) = %d, then %d", tenantID, bucket, again)
+		}
+		if rolloutBucket("other", tenantID) != bucket {
+			differs = true
+		}
+		seen[bucket] = true
+	}
+	// A thousand tenants spread over most buckets
+	if len(seen) < 90 {
+		t.Errorf("a thousand tenants fell into %d buckets", len(seen))
+	}
+	if !differs {
+		t.Error("flags roll out to the same tenants")
+	}
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"math"
+	"sort"
+	"tenant-management-service/internal/model"
+	"tenant-management-service/internal/repository"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/utils"
+	"time"
+)
+
+const (
+	// forecastHistoryDays is how many past days usage forecasts are based on.
+	forecastHistoryDays = 28
+	// forecastSeasonalDays is the history needed before weekday seasonality is applied,
+	// so that every weekday has been seen at least twice.
+	forecastSeasonalDays = 14
+)
+
+// ForecastService projects usage and quota exhaustion from recent usage.
+type ForecastService struct {
+	transactor *repository.Transactor
+	channels   *ChannelService
+}
+
+func NewForecastService(transactor *repository.Transactor, channels *ChannelService) *ForecastService {
+	return &ForecastService{transactor: transactor, channels: channels}
+}
+
+// usageModel predicts daily usage as a level, the moving average of past days weighted
+// towards recent ones, scaled by the factor of the weekday.
+type usageModel struct {
+	level   float64
+	weekday [7]float64
+}
+
+func (m usageModel) predict(date time.Time) float64 {
+	return m.level * m.weekday[date.Weekday()]
+}
+
+// forecastDay is the projected usage of a day, in full and as far as it is not yet
+// recorded.
+type forecastDay struct {
+	date    time.Time
+	full    float64
+	pending float64
+}
+
+// GetForecast projects the usage of a tenant's channels to the end of the current month,
+// along with the day each quota window is projected to run out. Channels with neither
+// recent usage nor a quota are left out; channel restricts the forecast to one channel.
+func (s *ForecastService) GetForecast(tenantID, channel string) ([]model.UsageForecast, error) {
+	// Validation
+	if err := utils.ValidateNonEmptyString(tenantID, "TenantID"); err != nil {
+		return nil, err
+	}
+	if channel != "" {
+		var err error
+		if channel, err = s.channels.Normalize(channel); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	forecasts := []model.UsageForecast{}
+	err := s.transactor.Transaction(func(tx *repository.Tx) error {
+		tenant, err := tx.Tenants.FindByTenantID(tenantID)
+		if err != nil {
+			return err
+		}
+		loc := tenant.Location()
+		today := model.UsageDate(now, loc)
+		monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+		historyStart := today.AddDate(0, 0, -forecastHistoryDays)
+		if created := model.UsageDate(tenant.CreatedAt, loc); created.After(historyStart) {
+			historyStart = created
+		}
+
+		// Load daily usage covering both the history and the month so far
+		from := historyStart
+		if monthStart.Before(from) {
+			from = monthStart
+		}
+		rows, err := tx.Usage.DailySent(tenantID, channel, from, today)
+		if err != nil {
+			return err
+		}
+		daily := make(map[string]map[string]int)
+		for _, row := range rows {
+			if daily[row.Channel] == nil {
+				daily[row.Channel] = make(map[string]int)
+			}
+			daily[row.Channel][row.Date.Format(time.DateOnly)] = row.NotificationsSent
+		}
+
+		quotas, err := tx.Quotas.FindByTenantID(tenantID)
+		if err != nil {
+			return err
+		}
+		quotasByChannel := make(map[string]*model.Quota)
+		for i := range quotas {
+			if channel == "" || quotas[i].Channel == channel {
+				quotasByChannel[quotas[i].Channel] = &quotas[i]
+			}
+		}
+
+		codes := make([]string, 0, len(daily)+len(quotasByChannel))
+		for code := range daily {
+			codes = append(codes, code)
+		}
+		for code := range quotasByChannel {
+			if daily[code] == nil {
+				codes = append(codes, code)
+			}
+		}
+		sort.Strings(codes)
+
+		localNow := now.In(loc)
+		elapsed := localNow.Sub(time.Date(localNow.Year(), localNow.Month(), localNow.Day(), 0, 0, 0, 0, loc)).Hours() / 24
+		for _, code := range codes {
+			forecast, days := forecastUsage(code, daily[code], historyStart, today, monthStart, elapsed)
+			forecast.Quotas = []model.QuotaExhaustion{}
+			if quota := quotasByChannel[code]; quota != nil {
+				windows, err := quotaWindows(tx, quota, tenant, now)
+				if err != nil {
+					return err
+				}
+				for i, window := range allQuotaWindows(quota) {
+					forecast.Quotas = append(forecast.Quotas, projectExhaustion(window, windows[i], days, tenant))
+				}
+			}
+			forecasts = append(forecasts, forecast)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			return nil, fmt.Errorf("%w: tenant not found", pkgerr.ErrNotFound)
+		}
+		logger.Error("Error computing usage forecast", zap.Error(err))
+		return nil, errors.New("failed to compute usage forecast")
+	}
+
+	return forecasts, nil
+}
+
+// forecastUsage projects the usage of a channel from its daily counts, keyed by date, to
+// the end of the month starting at monthStart. elapsed is the part of today that has passed.
+func forecastUsage(channel string, counts map[string]int, historyStart, today, monthStart time.Time, elapsed float64) (model.UsageForecast, []forecastDay) {
+	count := func(date time.Time) int { return counts[date.Format(time.DateOnly)] }
+
+	usage := fitUsageModel(count, historyStart, today)
+	if today.Equal(historyStart) && elapsed > 0 {
+		// Without history the best guess is the rate of today so far
+		usage.level = float64(count(today)) / elapsed
+	}
+
+	forecast := model.UsageForecast{Channel: channel, DailyAverage: round2(usage.level), Days: []model.ForecastDay{}}
+	for date := monthStart; date.Before(today); date = date.AddDate(0, 0, 1) {
+		forecast.MonthToDate += count(date)
+	}
+	total := float64(forecast.MonthToDate)
+	forecast.MonthToDate += count(today)
+
+	var days []forecastDay
+	for date := today; date.Before(monthStart.AddDate(0, 1, 0)); date = date.AddDate(0, 0, 1) {
+		day := forecastDay{date: date, full: usage.predict(date), pending: usage.predict(date)}
+		if date.Equal(today) {
+			// Today is what has been used so far plus the prediction for the rest of it
+			day.pending = day.full * (1 - elapsed)
+			day.full = float64(count(today)) + day.pending
+		}
+		days = append(days, day)
+		total += day.full
+		forecast.Days = append(forecast.Days, model.ForecastDay{Date: date, Projected: int(math.Round(day.full))})
+	}
+	forecast.ProjectedMonthTotal = int(math.Round(total))
+	return forecast, days
+}
+
+// fitUsageModel fits a usage model to the days from historyStart up to but excluding
+// today. Recent days weigh more in the level; weekday factors compare the average of a
+// weekday to the overall average and are only used with enough history.
+func fitUsageModel(count func(date time.Time) int, historyStart, today time.Time) usageModel {
+	usage := usageModel{weekday: [7]float64{1, 1, 1, 1, 1, 1, 1}}
+
+	var weighted, weights, sum float64
+	var weekdaySums, weekdayDays [7]float64
+	days := 0
+	for date := historyStart; date.Before(today); date = date.AddDate(0, 0, 1) {
+		n := float64(count(date))
+		days++
+		weighted += float64(days) * n
+		weights += float64(days)
+		sum += n
+		weekdaySums[date.Weekday()] += n
+		weekdayDays[date.Weekday()]++
+	}
+	if days == 0 {
+		return usage
+	}
+	usage.level = weighted / weights
+
+	mean := sum / float64(days)
+	if days >= forecastSeasonalDays && mean > 0 {
+		for day := range usage.weekday {
+			if weekdayDays[day] > 0 {
+				usage.weekday[day] = weekdaySums[day] / weekdayDays[day] / mean
+			}
+		}
+	}
+	return usage
+}
+
+// projectExhaustion finds the first day on which a quota window is projected to run out,
+// counting capacity held by reservations as used. Calendar windows run out once their
+// accumulated usage reaches the limit; hourly and rolling windows once the projected rate
+// over their length does.
+func projectExhaustion(window model.QuotaWindow, status model.QuotaWindowStatus, days []forecastDay, tenant *model.Tenant) model.QuotaExhaustion {
+	exhaustion := model.QuotaExhaustion{Window: window.Window, Hours: window.Hours, Limit: window.Limit, Used: status.Used}
+	loc := tenant.Location()
+
+	hours := 0
+	switch window.Window {
+	case model.QuotaWindowHourly:
+		hours = 1
+	case model.QuotaWindowRolling:
+		hours = window.Hours
+	}
+
+	used := float64(status.Used + status.Reserved)
+	windowStart := status.StartsAt
+	for i, day := range days {
+		if i == 0 && used >= float64(window.Limit) {
+			exhaustion.ExhaustedOn = &days[i].date
+			break
+		}
+
+		if hours > 0 {
+			if day.full*float64(hours)/24 >= float64(window.Limit) {
+				exhaustion.ExhaustedOn = &days[i].date
+				break
+			}
+			continue
+		}
+
+		// Calendar windows start over when the day falls into the next one
+		noon := time.Date(day.date.Year(), day.date.Month(), day.date.Day(), 12, 0, 0, 0, loc)
+		if start, _ := windowBounds(window, noon, loc, tenant.BillingCycleAnchor); !start.UTC().Equal(windowStart) {
+			windowStart = start.UTC()
+			used = 0
+		}
+		used += day.pending
+		if used >= float64(window.Limit) {
+			exhaustion.ExhaustedOn = &days[i].date
+			break
+		}
+	}
+	return exhaustion
+}
+
+// round2 rounds to two decimals.
+func round2(x float64) float64 {
+	return math.Round(x*100) / 100
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"math"
+	"tenant-management-service/internal/model"
+	"testing"
+	"time"
+)
+
+func TestFitUsageModel(t *testing.T) {
+	// 2024-03-04 is a Monday
+	monday := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	constant := func(n int) func(time.Time) int {
+		return func(time.Time) int { return n }
+	}
+	weekdaysOnly := func(date time.Time) int {
+		if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+			return 0
+		}
+		return 10
+	}
+	lastDay := func(date time.Time) int {
+		if date.Equal(monday.AddDate(0, 0, 2)) {
+			return 30
+		}
+		return 0
+	}
+	flat := [7]float64{1, 1, 1, 1, 1, 1, 1}
+
+	tests := []struct {
+		name    string
+		count   func(time.Time) int
+		days    int
+		level   float64
+		weekday [7]float64
+	}{
+		{"no history", constant(10), 0, 0, flat},
+		{"constant usage", constant(10), forecastHistoryDays, 10, flat},
+		{"recent days weigh more", lastDay, 3, 15, flat},
+		{"too short for seasonality", weekdaysOnly, forecastSeasonalDays - 1, 0, flat},
+		{"weekday seasonality", weekdaysOnly, forecastSeasonalDays, 0, [7]float64{0, 1.4, 1.4, 1.4, 1.4, 1.4, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usage := fitUsageModel(tt.count, monday, monday.AddDate(0, 0, tt.days))
+			if tt.level != 0 && !approxEqual(usage.level, tt.level) {
+				t.Errorf("level = %v, want %v", usage.level, tt.level)
+			}
+			for day, want := range tt.weekday {
+				if !approxEqual(usage.weekday[day], want) {
+					t.Errorf("weekday[%s] = %v, want %v", time.Weekday(day), usage.weekday[day], want)
+				}
+			}
+		})
+	}
+}
+
+func TestForecastUsage(t *testing.T) {
+	monthStart := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	today := time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC)
+	counts := map[string]int{}
+	for date := today.AddDate(0, 0, -forecastHistoryDays); !date.After(today); date = date.AddDate(0, 0, 1) {
+		counts[date.Format(time.DateOnly)] = 10
+	}
+	counts[today.Format(time.DateOnly)] = 4
+
+	forecast, days := forecastUsage("sms", counts, today.AddDate(0, 0, -forecastHistoryDays), today, monthStart, 0.5)
+	if forecast.DailyAverage != 10 || forecast.MonthToDate != 284 {
+		t.Errorf("forecast = %+v, want a daily average of 10 and 284 to date", forecast)
+	}
+	// Today is 4 so far plus half a day at 10, then one more day of 10
+	if len(days) != 2 || !approxEqual(days[0].full, 9) || !approxEqual(days[0].pending, 5) || !approxEqual(days[1].full, 10) {
+		t.Errorf("days = %+v", days)
+	}
+	if forecast.ProjectedMonthTotal != 299 {
+		t.Errorf("ProjectedMonthTotal = %d, want 299", forecast.ProjectedMonthTotal)
+	}
+
+	// Without history today's rate so far is extrapolated
+	forecast, _ = forecastUsage("sms", map[string]int{today.Format(time.DateOnly): 6}, today, today, monthStart, 0.25)
+	if forecast.DailyAverage != 24 {
+		t.Errorf("DailyAverage without history = %v, want 24", forecast.DailyAverage)
+	}
+}
+
+func TestProjectExhaustion(t *testing.T) {
+	tenant := &model.Tenant{Timezone: "UTC", BillingCycleAnchor: 1}
+	start := time.Date(2024, 4, 28, 0, 0, 0, 0, time.UTC)
+	forecastDaysFrom := func(from time.Time, perDay float64, n int) []forecastDay {
+		days := make([]forecastDay, n)
+		for i := range days {
+			days[i] = forecastDay{date: from.AddDate(0, 0, i), full: perDay, pending: perDay}
+		}
+		return days
+	}
+	forecastDays := func(perDay float64, n int) []forecastDay {
+		return forecastDaysFrom(start, perDay, n)
+	}
+	monthly := model.QuotaWindow{Window: model.QuotaWindowMonthly, Limit: 100}
+	monthlyStatus := model.QuotaWindowStatus{StartsAt: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), Used: 40, Reserved: 10}
+
+	tests := []struct {
+		name   string
+		window model.QuotaWindow
+		status model.QuotaWindowStatus
+		days   []forecastDay
+		want   *time.Time
+	}{
+		{"monthly runs out", monthly, monthlyStatus, forecastDays(20, 3), ptr(start.AddDate(0, 0, 2))},
+		{"monthly starts over", monthly, monthlyStatus, forecastDaysFrom(start.AddDate(0, 0, 1), 20, 4), nil},
+		{"already exhausted", monthly, model.QuotaWindowStatus{StartsAt: monthlyStatus.StartsAt, Used: 90, Reserved: 10}, forecastDays(0, 3), ptr(start)},
+		{"daily never runs out", model.QuotaWindow{Window: model.QuotaWindowDaily, Limit: 100},
+			model.QuotaWindowStatus{StartsAt: start, Used: 50}, forecastDays(40, 3), nil},
+		{"daily runs out today", model.QuotaWindow{Window: model.QuotaWindowDaily, Limit: 100},
+			model.QuotaWindowStatus{StartsAt: start, Used: 50}, forecastDays(60, 3), ptr(start)},
+		{"hourly rate", model.QuotaWindow{Window: model.QuotaWindowHourly, Limit: 10},
+			model.QuotaWindowStatus{StartsAt: start}, []forecastDay{{date: start, full: 200}, {date: start.AddDate(0, 0, 1), full: 240}}, ptr(start.AddDate(0, 0, 1))},
+		{"rolling rate", model.QuotaWindow{Window: model.QuotaWindowRolling, Hours: 12, Limit: 60},
+			model.QuotaWindowStatus{StartsAt: start}, forecastDays(100, 3), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := projectExhaustion(tt.window, tt.status, tt.days, tenant).ExhaustedOn
+			if (got == nil) != (tt.want == nil) || got != nil && !got.Equal(*tt.want) {
+				t.Errorf("ExhaustedOn = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
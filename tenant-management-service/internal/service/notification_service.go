@@ -0,0 +1,398 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"strconv"
+	"sync"
+	"tenant-management-service/internal/config"
+	"tenant-management-service/internal/model"
+	"tenant-management-service/internal/model/dto"
+	"tenant-management-service/internal/provider"
+	"tenant-management-service/internal/repository"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/ratelimit"
+	"tenant-management-service/pkg/utils"
+	"text/template"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxNotificationBody is the largest notification body accepted, in bytes.
+	maxNotificationBody = 64 << 10
+	// maxNotificationMetadata is the largest number of metadata entries of a notification.
+	maxNotificationMetadata = 20
+)
+
+// NotificationService accepts notifications against the tenant's quota and delivers them
+// in the background. Accepted notifications are persisted before they are queued, so the
+// sweeper picks up whatever the in-memory queue loses to a full queue or a restart.
+type NotificationService struct {
+	repo       *repository.NotificationRepository
+	tenantRepo *repository.TenantRepository
+	configRepo *repository.ConfigRepository
+	channels   *ChannelService
+	quotas     *QuotaService
+	usage      *UsageService
+	providers  *provider.Registry
+	queue      chan string
+	config     config.NotificationConfig
+}
+
+func NewNotificationService(repo *repository.NotificationRepository, tenantRepo *repository.TenantRepository, configRepo *repository.ConfigRepository,
+	channels *ChannelService, quotas *QuotaService, usage *UsageService, providers *provider.Registry, notificationConfig config.NotificationConfig) *NotificationService {
+	return &NotificationService{
+		repo:       repo,
+		tenantRepo: tenantRepo,
+		configRepo: configRepo,
+		channels:   channels,
+		quotas:     quotas,
+		usage:      usage,
+		providers:  providers,
+		queue:      make(chan string, notificationConfig.QueueSize),
+		config:     notificationConfig,
+	}
+}
+
+// Send validates a notification of a tenant, enforces the rate limits and quota of its
+// channel and, if it fits, records the usage, stores the notification and queues it for
+// delivery. The rate limit decision is returned for response headers whenever one was made.
+func (s *NotificationService) Send(tenant *model.Tenant, notificationDTO dto.NotificationDTO) (*model.Notification, *ratelimit.Decision, error) {
+	tenantID := strconv.FormatUint(uint64(tenant.ID), 10)
+
+	// Validation
+	notification, err := s.notificationModel(tenantID, notificationDTO)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Enforce the rate limits before touching the quota windows
+	decision, err := s.quotas.CheckRateLimit(tenantID, notificationDTO.Channel, 1)
+	if err != nil {
+		return nil, decision, err
+	}
+
+	// Store the notification together with the quota consumption
+	_, err = s.quotas.ConsumeFor(tenantID, notificationDTO.Channel, 1, func(tx *repository.Tx, channel string) error {
+		notification.Channel = channel
+		if err := tx.Notifications.Create(notification); err != nil {
+			logger.Error("Error storing notification", zap.Error(err))
+			return errors.New("failed to store notification")
+		}
+		return nil
+	})
+	if err != nil {
+		s.quotas.RefundRateLimit(decision)
+		return nil, decision, err
+	}
+
+	s.enqueue(notification.ID)
+	return notification, decision, nil
+}
+
+// notificationModel validates a notification request and converts it to a queued
+// notification, rendering its content from a template if one is referenced.
+func (s *NotificationService) notificationModel(tenantID string, notificationDTO dto.NotificationDTO) (*model.Notification, error) {
+	if err := utils.ValidateMaxLength(notificationDTO.Recipient, "Recipient", 255); err != nil {
+		return nil, err
+	}
+	if len(notificationDTO.Metadata) > maxNotificationMetadata {
+		return nil, &utils.ValidationError{Field: "Metadata", Message: fmt.Sprintf("Field must not have more than %d entries", maxNotificationMetadata)}
+	}
+
+	now := time.Now()
+	notification := &model.Notification{
+		ID:            utils.GenerateUUID(),
+		TenantID:      tenantID,
+		Recipient:     notificationDTO.Recipient,
+		Subject:       notificationDTO.Subject,
+		Body:          notificationDTO.Body,
+		Status:        model.NotificationStatusQueued,
+		NextAttemptAt: now,
+	}
+
+	if notificationDTO.Template != nil {
+		if notificationDTO.Subject != "" || notificationDTO.Body != "" {
+			return nil, &utils.ValidationError{Field: "Template", Message: "Field must not be combined with subject or body"}
+		}
+		if err := utils.ValidateSlug(notificationDTO.Template.Name, "Template.Name", 100); err != nil {
+			return nil, err
+		}
+		subject, body, err := s.renderTemplate(tenantID, notificationDTO.Template)
+		if err != nil {
+			return nil, err
+		}
+		notification.Template = notificationDTO.Template.Name
+		notification.Subject, notification.Body = subject, body
+	}
+
+	if err := utils.ValidateNonEmptyString(notification.Body, "Body"); err != nil {
+		return nil, err
+	}
+	if err := utils.ValidateMaxLength(notification.Subject, "Subject", 255); err != nil {
+		return nil, err
+	}
+	if err := utils.ValidateMaxLength(notification.Body, "Body", maxNotificationBody); err != nil {
+		return nil, err
+	}
+
+	if len(notificationDTO.Metadata) > 0 {
+		metadata, err := json.Marshal(notificationDTO.Metadata)
+		if err != nil {
+			return nil, &utils.ValidationError{Field: "Metadata", Message: err.Error()}
+		}
+		notification.Metadata = metadata
+	}
+	return notification, nil
+}
+
+// renderTemplate renders the subject and body of a tenant's notification template.
+func (s *NotificationService) renderTemplate(tenantID string, templateDTO *dto.NotificationTemplateDTO) (string, string, error) {
+	configKey := model.NotificationTemplatePrefix + templateDTO.Name
+	config, err := s.configRepo.FindByTenantIdAndKey(tenantID, configKey)
+	if err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			return "", "", &utils.ValidationError{Field: "Template.Name", Message: fmt.Sprintf("No template %q is configured", templateDTO.Name)}
+		}
+		logger.Error("Error fetching notification template", zap.Error(err))
+		return "", "", errors.New("failed to fetch notification template")
+	}
+
+	var content struct {
+		Subject string `json:"subject"`
+		Body    string `json:"body"`
+	}
+	if config.JSONValue == nil || json.Unmarshal(config.JSONValue, &content) != nil {
+		return "", "", &utils.ValidationError{
+			Field:   "Template.Name",
+			Message: fmt.Sprintf("Configuration %q must hold a JSON object with a subject and a body", configKey),
+		}
+	}
+
+	var rendered [2]bytes.Buffer
+	for i, text := range []string{content.Subject, content.Body} {
+		tmpl, err := template.New(templateDTO.Name).Option("missingkey=error").Parse(text)
+		if err == nil {
+			err = tmpl.Execute(&rendered[i], templateDTO.Data)
+		}
+		if err != nil {
+			return "", "", &utils.ValidationError{Field: "Template", Message: err.Error()}
+		}
+	}
+	return rendered[0].String(), rendered[1].String(), nil
+}
+
+// GetNotification retrieves a notification of a tenant.
+func (s *NotificationService) GetNotification(tenantID, id string) (*model.Notification, error) {
+	notification, err := s.repo.FindByID(tenantID, id)
+	if err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			return nil, fmt.Errorf("%w: notification not found", pkgerr.ErrNotFound)
+		}
+		logger.Error("Error fetching notification", zap.Error(err))
+		return nil, errors.New("failed to fetch notification")
+	}
+	return notification, nil
+}
+
+// GetAttempts retrieves the delivery attempts of a notification of a tenant in order.
+func (s *NotificationService) GetAttempts(tenantID, id string) ([]model.NotificationAttempt, error) {
+	if _, err := s.GetNotification(tenantID, id); err != nil {
+		return nil, err
+	}
+	attempts, err := s.repo.FindAttempts(id)
+	if err != nil {
+		logger.Error("Error fetching notification attempts", zap.Error(err))
+		return nil, errors.New("failed to fetch notification attempts")
+	}
+	return attempts, nil
+}
+
+// enqueue hands a notification to the delivery workers without blocking. Notifications
+// that do not fit in the queue are left to the sweeper.
+func (s *NotificationService) enqueue(id string) bool {
+	select {
+	case s.queue <- id:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run delivers queued notifications with the configured number of workers until ctx is
+// cancelled. Notifications still queued in memory then are delivered after a restart.
+func (s *NotificationService) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < s.config.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case id := <-s.queue:
+					s.deliver(ctx, id)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// Sweep queues notifications that are due for a delivery attempt but not in the queue:
+// retries, notifications that did not fit in the queue and ones whose worker stopped.
+func (s *NotificationService) Sweep(ctx context.Context) {
+	ids, err := s.repo.FindDue(time.Now(), cap(s.queue))
+	if err != nil {
+		logger.Error("Error fetching due notifications", zap.Error(err))
+		return
+	}
+	for _, id := range ids {
+		if !s.enqueue(id) {
+			return
+		}
+	}
+}
+
+// deliver claims a notification and attempts to send it, recording the attempt. Failed
+// attempts are retried with exponential backoff until the configured number of attempts is
+// used up.
+func (s *NotificationService) deliver(ctx context.Context, id string) {
+	now := time.Now()
+	notification, err := s.repo.Claim(id, now, 2*s.config.SendTimeout)
+	if err != nil {
+		logger.Error("Error claiming notification", zap.String("notification_id", id), zap.Error(err))
+		return
+	}
+	if notification == nil {
+		return
+	}
+
+	attempt := &model.NotificationAttempt{NotificationID: id, Attempt: notification.Attempts, AttemptedAt: now}
+	tenant, err := s.tenantRepo.FindByTenantID(notification.TenantID)
+	if err == nil {
+		attempt.Provider, err = s.send(ctx, notification)
+	}
+	attempt.DurationMs = time.Since(now).Milliseconds()
+	if err != nil {
+		attempt.Error = truncate(err.Error(), 500)
+		var statusErr *provider.StatusError
+		if errors.As(err, &statusErr) {
+			attempt.StatusCode = statusErr.StatusCode
+		}
+	}
+
+	var updateErr error
+	switch {
+	case err == nil:
+		attempt.Outcome = model.NotificationAttemptSent
+		updateErr = s.repo.MarkSent(id, time.Now())
+		s.usage.Track(notification.TenantID, notification.Channel, model.UsageOutcomeDelivered, now, tenant.Location(), 1)
+	case ctx.Err() != nil:
+		// Shutting down; retry as soon as delivery resumes
+		attempt.Outcome = model.NotificationAttemptAborted
+		updateErr = s.repo.Reschedule(id, "delivery interrupted", now)
+	case provider.IsPermanent(err), errors.Is(err, pkgerr.ErrNotFound), notification.Attempts >= s.config.MaxAttempts:
+		logger.Warn("Notification delivery failed", zap.String("notification_id", id), zap.Int("attempts", notification.Attempts), zap.Error(err))
+		attempt.Outcome = model.NotificationAttemptFailed
+		updateErr = s.repo.MarkFailed(id, attempt.Error)
+		if tenant != nil {
+			s.usage.Track(notification.TenantID, notification.Channel, model.UsageOutcomeUndelivered, now, tenant.Location(), 1)
+		}
+	default:
+		backoff := s.config.RetryBackoff << (notification.Attempts - 1)
+		logger.Info("Notification delivery attempt failed, retrying", zap.String("notification_id", id),
+			zap.Int("attempts", notification.Attempts), zap.Duration("backoff", backoff), zap.Error(err))
+		attempt.Outcome = model.NotificationAttemptRetry
+		updateErr = s.repo.Reschedule(id, attempt.Error, time.Now().Add(backoff))
+	}
+	if updateErr != nil {
+		logger.Error("Error updating notification status", zap.String("notification_id", id), zap.Error(updateErr))
+	}
+	// The attempt happened either way, so it is recorded even when the status update failed
+	if err := s.repo.CreateAttempt(attempt); err != nil {
+		logger.Error("Error recording notification attempt", zap.String("notification_id", id), zap.Error(err))
+	}
+}
+
+// send delivers a notification through the provider the tenant selected for its channel
+// and returns the name of the provider.
+func (s *NotificationService) send(ctx context.Context, notification *model.Notification) (string, error) {
+	settings, err := s.channelSettings(notification.TenantID, notification.Channel)
+	if err != nil {
+		return "", err
+	}
+	channelProvider, err := s.providers.Select(notification.Channel, settings)
+	if err != nil {
+		return "", err
+	}
+	capabilities := channelProvider.Capabilities()
+	if capabilities.MaxBodyLength > 0 && utf8.RuneCountInString(notification.Body) > capabilities.MaxBodyLength {
+		return channelProvider.Name(), provider.Permanent(fmt.Errorf("body exceeds the %d characters %s accepts",
+			capabilities.MaxBodyLength, channelProvider.Name()))
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, s.config.SendTimeout)
+	defer cancel()
+	return channelProvider.Name(), channelProvider.Send(sendCtx, settings, notification)
+}
+
+// CheckProviderHealth checks the provider a tenant selected for a channel with the
+// tenant's settings. Failed checks are reported in the result rather than as errors.
+func (s *NotificationService) CheckProviderHealth(ctx context.Context, tenantID, channel string) (*provider.HealthReport, error) {
+	channel, err := s.channels.Normalize(channel)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.channelSettings(tenantID, channel)
+	if err != nil {
+		logger.Error("Error fetching channel settings", zap.Error(err))
+		return nil, errors.New("failed to fetch channel settings")
+	}
+	channelProvider, err := s.providers.Select(channel, settings)
+	if err != nil {
+		return nil, &utils.ValidationError{Field: "Channel", Message: err.Error()}
+	}
+
+	report := &provider.HealthReport{
+		Channel:      channel,
+		Provider:     channelProvider.Name(),
+		Capabilities: channelProvider.Capabilities(),
+		Healthy:      true,
+		CheckedAt:    time.Now(),
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, s.config.SendTimeout)
+	defer cancel()
+	if err := channelProvider.Health(checkCtx, settings); err != nil {
+		report.Healthy = false
+		report.Error = err.Error()
+	}
+	return report, nil
+}
+
+// channelSettings loads a tenant's settings of a channel.
+func (s *NotificationService) channelSettings(tenantID, channel string) (provider.Settings, error) {
+	configs, err := s.configRepo.FindByTenantId(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return provider.NewSettings(configs, channel), nil
+}
+
+// truncate shortens s to at most n bytes without splitting a UTF-8 encoded rune.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		n    int
+		want string
+	}{
+		{"short", "timeout", 16, "timeout"},
+		{"exact", "timeout", 7, "timeout"},
+		{"ascii", "connection refused", 10, "connection"},
+		{"rune boundary", "héllo", 3, "hé"},
+		{"inside two-byte rune", "héllo", 2, "h"},
+		{"inside three-byte rune", "a€b", 3, "a"},
+		{"inside four-byte rune", "😀😀", 6, "😀"},
+		{"first rune cut", "€", 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncate(tt.s, tt.n)
+			if got != tt.want {
+				t.Errorf("truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("truncate(%q, %d) = %q is not valid UTF-8", tt.s, tt.n, got)
+			}
+		})
+	}
+}
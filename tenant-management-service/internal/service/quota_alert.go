@@ -0,0 +1,94 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"tenant-management-service/internal/model"
+	"tenant-management-service/internal/model/dto"
+	"tenant-management-service/internal/repository"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/utils"
+	"time"
+)
+
+// quotaThresholdModels validates the alert thresholds of a quota and converts them to models.
+func quotaThresholdModels(thresholds []dto.QuotaThresholdDTO) ([]model.QuotaThreshold, error) {
+	models := []model.QuotaThreshold{}
+	seen := map[int]bool{}
+	for _, threshold := range thresholds {
+		if err := utils.ValidateRange(threshold.Percent, "Percent", 1, model.MaxQuotaThresholdPercent); err != nil {
+			return nil, err
+		}
+		if seen[threshold.Percent] {
+			return nil, &utils.ValidationError{Field: "Percent", Message: fmt.Sprintf("Threshold %d%% is listed more than once", threshold.Percent)}
+		}
+		seen[threshold.Percent] = true
+		models = append(models, model.QuotaThreshold{Percent: threshold.Percent})
+	}
+	return models, nil
+}
+
+// raiseThresholdAlerts records an alert for every threshold of a quota that the last count
+// units of usage crossed in one of its windows. Each alert is raised at most once per
+// window, so the returned alerts are only those that are new.
+func raiseThresholdAlerts(tx *repository.Tx, quota *model.Quota, windows []model.QuotaWindowStatus, count int) ([]model.QuotaAlert, error) {
+	var alerts []model.QuotaAlert
+	for _, window := range windows {
+		if window.Limit <= 0 {
+			continue
+		}
+		before := window.Used - count
+		for _, threshold := range quota.Thresholds {
+			mark := window.Limit * threshold.Percent
+			if before*100 >= mark || window.Used*100 < mark {
+				continue
+			}
+			alerts = append(alerts, model.QuotaAlert{
+				TenantID:    quota.TenantID,
+				Channel:     quota.Channel,
+				Window:      window.Window,
+				Hours:       window.Hours,
+				WindowStart: window.StartsAt,
+				WindowEnd:   window.ResetAt,
+				Threshold:   threshold.Percent,
+				Limit:       window.Limit,
+				Used:        window.Used,
+			})
+		}
+	}
+	if len(alerts) == 0 {
+		return nil, nil
+	}
+	return tx.Alerts.CreateIfAbsent(alerts)
+}
+
+// CheckThresholds raises the alerts for thresholds of a tenant's channel quota that count
+// units of usage recorded outside quota consumption, such as reported usage events, just
+// crossed, and queues them for delivery. Channels without a quota have nothing to check.
+func (s *AlertService) CheckThresholds(tenantID, channel string, count int) error {
+	var alerts []model.QuotaAlert
+	err := s.transactor.Transaction(func(tx *repository.Tx) error {
+		tenant, err := tx.Tenants.FindByTenantID(tenantID)
+		if err != nil {
+			return err
+		}
+		quota, err := tx.Quotas.FindForUpdate(tenantID, channel)
+		if err != nil {
+			return err
+		}
+		if len(quota.Thresholds) == 0 {
+			return nil
+		}
+		windows, err := quotaWindows(tx, quota, tenant, time.Now())
+		if err != nil {
+			return err
+		}
+		alerts, err = raiseThresholdAlerts(tx, quota, windows, count)
+		return err
+	})
+	if err != nil && !errors.Is(err, pkgerr.ErrNotFound) {
+		return err
+	}
+	s.Enqueue(alerts)
+	return nil
+}
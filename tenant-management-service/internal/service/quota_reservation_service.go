@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"tenant-management-service/internal/model"
+	"tenant-management-service/internal/repository"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/utils"
+	"time"
+)
+
+// ReserveQuota holds count units of a tenant's channel quota for ttl, failing with a
+// QuotaExceededError if they do not fit in every window. A zero ttl uses the default.
+func (s *QuotaService) ReserveQuota(tenantID, channel string, count int, ttl time.Duration) (*model.QuotaReservation, []model.QuotaWindowStatus, error) {
+	// Validation
+	channel, err := s.validateQuotaRequest(tenantID, channel, count)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ttl == 0 {
+		ttl = s.config.ReservationDefaultTTL
+	}
+	if ttl < 0 || ttl > s.config.ReservationMaxTTL {
+		return nil, nil, &utils.ValidationError{
+			Field:   "TTL",
+			Message: fmt.Sprintf("Field must be between 1s and %s", s.config.ReservationMaxTTL),
+		}
+	}
+
+	now := time.Now()
+	reservation := &model.QuotaReservation{
+		ID:        utils.GenerateUUID(),
+		TenantID:  tenantID,
+		Channel:   channel,
+		Status:    model.ReservationStatusActive,
+		Count:     count,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	var windows []model.QuotaWindowStatus
+	err = s.transactor.Transaction(func(tx *repository.Tx) error {
+		tenant, err := tx.Tenants.FindByTenantID(tenantID)
+		if err != nil {
+			return err
+		}
+		if _, err := s.channels.ValidateForTier(channel, tenant.BillingTier); err != nil {
+			return err
+		}
+		quota, err := tx.Quotas.FindForUpdate(tenantID, channel)
+		if err != nil {
+			return err
+		}
+
+		if windows, err = quotaWindows(tx, quota, tenant, now); err != nil {
+			return err
+		}
+		if err := reserveInWindows(quota, windows, count, true); err != nil {
+			return err
+		}
+
+		return tx.Reservations.Create(reservation)
+	})
+	if err != nil {
+		return nil, nil, s.quotaError(err, channel, "Error reserving quota", "failed to reserve quota")
+	}
+
+	return reservation, windows, nil
+}
+
+// CommitReservation records count units of an active reservation as usage. The
+// reservation is complete once everything it held has been committed. Committed usage
+// beyond the limits is recorded as overage, and thresholds it crosses raise alerts.
+func (s *QuotaService) CommitReservation(tenantID, reservationID string, count int) (*model.QuotaReservation, error) {
+	if count <= 0 {
+		return nil, &utils.ValidationError{Field: "Count", Message: "Field must be positive"}
+	}
+
+	var alerts []model.QuotaAlert
+	reservation, err := s.updateReservation(tenantID, reservationID, func(tx *repository.Tx, quota *model.Quota, reservation *model.QuotaReservation) error {
+		tenant, err := tx.Tenants.FindByTenantID(tenantID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		windows, err := quotaWindows(tx, quota, tenant, now)
+		if err != nil {
+			return err
+		}
+		delta := usageDelta(quota, windows, count)
+		if err := commitReservation(reservation, windows, count); err != nil {
+			return err
+		}
+		if err := tx.Usage.Increment(tenantID, reservation.Channel, now, tenant.Location(), delta); err != nil {
+			return err
+		}
+
+		alerts, err = raiseThresholdAlerts(tx, quota, windows, count)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.alerts.Enqueue(alerts)
+	return reservation, nil
+}
+
+// ReleaseReservation returns the capacity an active reservation still holds.
+func (s *QuotaService) ReleaseReservation(tenantID, reservationID string) (*model.QuotaReservation, error) {
+	return s.updateReservation(tenantID, reservationID, func(tx *repository.Tx, quota *model.Quota, reservation *model.QuotaReservation) error {
+		releaseReservation(reservation)
+		return nil
+	})
+}
+
+// commitReservation moves count units of an active reservation from held to used, in the
+// reservation and in the windows computed while it held them. The reservation is
+// committed once it holds nothing more.
+func commitReservation(reservation *model.QuotaReservation, windows []model.QuotaWindowStatus, count int) error {
+	if count > reservation.Held() {
+		return &utils.ValidationError{
+			Field:   "Count",
+			Message: fmt.Sprintf("Field must not exceed the %d units still held", reservation.Held()),
+		}
+	}
+
+	reservation.Committed += count
+	if reservation.Held() == 0 {
+		reservation.Status = model.ReservationStatusCommitted
+	}
+	for i := range windows {
+		windows[i].Reserved -= count
+		addWindowUsage(&windows[i], count)
+	}
+	return nil
+}
+
+// releaseReservation ends an active reservation, returning the capacity it still holds.
+func releaseReservation(reservation *model.QuotaReservation) {
+	reservation.Status = model.ReservationStatusReleased
+}
+
+// GetReservation retrieves a reservation of a tenant.
+func (s *QuotaService) GetReservation(tenantID, reservationID string) (*model.QuotaReservation, error) {
+	reservation, err := s.reservationRepo.FindByID(tenantID, reservationID)
+	if err != nil {
+		return nil, s.quotaError(err, "", "Error fetching reservation", "failed to fetch reservation")
+	}
+	return reservation, nil
+}
+
+// ExpireReservations reclaims the capacity of reservations whose TTL has passed.
+func (s *QuotaService) ExpireReservations(ctx context.Context) {
+	expired, err := s.reservationRepo.ExpireBefore(time.Now())
+	if err != nil {
+		logger.Error("Error expiring quota reservations", zap.Error(err))
+		return
+	}
+	if expired > 0 {
+		logger.Info("Expired quota reservations reclaimed", zap.Int64("count", expired))
+	}
+}
+
+// updateReservation locks the quota and then the reservation, which must still be active,
+// applies update and saves the reservation.
+func (s *QuotaService) updateReservation(tenantID, reservationID string,
+	update func(tx *repository.Tx, quota *model.Quota, reservation *model.QuotaReservation) error) (*model.QuotaReservation, error) {
+	var reservation *model.QuotaReservation
+	err := s.transactor.Transaction(func(tx *repository.Tx) error {
+		current, err := tx.Reservations.FindByID(tenantID, reservationID)
+		if err != nil {
+			return err
+		}
+		quota, err := tx.Quotas.FindForUpdate(tenantID, current.Channel)
+		if err != nil {
+			return err
+		}
+
+		if reservation, err = tx.Reservations.FindForUpdate(tenantID, reservationID); err != nil {
+			return err
+		}
+		if reservation.Status != model.ReservationStatusActive || !time.Now().Before(reservation.ExpiresAt) {
+			return fmt.Errorf("%w: reservation is no longer active", pkgerr.ErrConflict)
+		}
+
+		if err := update(tx, quota, reservation); err != nil {
+			return err
+		}
+		return tx.Reservations.Update(reservation)
+	})
+	if err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			return nil, fmt.Errorf("%w: reservation not found", pkgerr.ErrNotFound)
+		}
+		return nil, s.quotaError(err, "", "Error updating reservation", "failed to update reservation")
+	}
+	return reservation, nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"errors"
+	"tenant-management-service/internal/model"
+	"tenant-management-service/pkg/utils"
+	"testing"
+)
+
+// windowsHolding returns a daily window with the given usage in which the reservations
+// hold what they hold, as quotaWindows computes it.
+func windowsHolding(used int, reservations ...*model.QuotaReservation) []model.QuotaWindowStatus {
+	window := model.QuotaWindowStatus{Window: model.QuotaWindowDaily, Limit: 10}
+	for _, reservation := range reservations {
+		window.Reserved += reservation.Held()
+	}
+	addWindowUsage(&window, used)
+	return []model.QuotaWindowStatus{window}
+}
+
+func TestCommitReservation(t *testing.T) {
+	reservation := &model.QuotaReservation{Status: model.ReservationStatusActive, Count: 5}
+	windows := windowsHolding(2, reservation)
+
+	// Partial commits keep the reservation active
+	if err := commitReservation(reservation, windows, 3); err != nil {
+		t.Fatalf("commitReservation: %v", err)
+	}
+	if reservation.Status != model.ReservationStatusActive || reservation.Committed != 3 || reservation.Held() != 2 {
+		t.Errorf("reservation after partial commit = %+v", reservation)
+	}
+	if daily := windows[0]; daily.Used != 5 || daily.Reserved != 2 || daily.Remaining != 3 {
+		t.Errorf("window after partial commit = %+v", daily)
+	}
+
+	// Committing beyond what is held is rejected and changes nothing
+	err := commitReservation(reservation, windows, 3)
+	var validationErr *utils.ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Field != "Count" {
+		t.Errorf("commitReservation beyond held = %v, want a validation error", err)
+	}
+	if reservation.Committed != 3 || windows[0].Used != 5 {
+		t.Errorf("rejected commit changed reservation %+v, window %+v", reservation, windows[0])
+	}
+
+	// Committing the rest completes the reservation
+	if err := commitReservation(reservation, windows, 2); err != nil {
+		t.Fatalf("commitReservation: %v", err)
+	}
+	if reservation.Status != model.ReservationStatusCommitted || reservation.Committed != 5 || reservation.Held() != 0 {
+		t.Errorf("reservation after full commit = %+v", reservation)
+	}
+	if daily := windows[0]; daily.Used != 7 || daily.Reserved != 0 || daily.Remaining != 3 {
+		t.Errorf("window after full commit = %+v", daily)
+	}
+}
+
+func TestReleaseReservation(t *testing.T) {
+	reservation := &model.QuotaReservation{Status: model.ReservationStatusActive, Count: 6, Committed: 2}
+	quota := &model.Quota{OveragePolicy: model.OveragePolicyBlock}
+
+	var exceeded *QuotaExceededError
+	if err := reserveInWindows(quota, windowsHolding(3, reservation), 4, false); !errors.As(err, &exceeded) {
+		t.Fatalf("reserveInWindows while held = %v, want the quota exceeded", err)
+	}
+
+	releaseReservation(reservation)
+	if reservation.Status != model.ReservationStatusReleased || reservation.Held() != 0 {
+		t.Errorf("reservation after release = %+v, held %d", reservation, reservation.Held())
+	}
+	windows := windowsHolding(3, reservation)
+	if windows[0].Remaining != 7 {
+		t.Errorf("remaining after release = %d, want 7", windows[0].Remaining)
+	}
+	if err := reserveInWindows(quota, windows, 4, false); err != nil {
+		t.Errorf("reserveInWindows after release = %v, want the capacity returned", err)
+	}
+}
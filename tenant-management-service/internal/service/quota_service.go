@@ -2,20 +2,69 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"go.uber.org/zap"
+	"tenant-management-service/internal/config"
 	"tenant-management-service/internal/model"
 	"tenant-management-service/internal/model/dto"
 	"tenant-management-service/internal/repository"
+	pkgerr "tenant-management-service/pkg/error"
 	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/ratelimit"
 	"tenant-management-service/pkg/utils"
+	"time"
 )
 
+// QuotaExceededError reports the quota window that would be exceeded by a consumption.
+// Ceiling is set when the window allows billed overage up to that point.
+type QuotaExceededError struct {
+	Window    string    `json:"window"`
+	Limit     int       `json:"limit"`
+	Ceiling   int       `json:"ceiling,omitempty"`
+	Used      int       `json:"used"`
+	Requested int       `json:"requested"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+func (e *QuotaExceededError) Error() string {
+	if e.Ceiling > 0 {
+		return fmt.Sprintf("%s quota overage ceiling exceeded: %d of %d used, %d requested", e.Window, e.Used, e.Ceiling, e.Requested)
+	}
+	return fmt.Sprintf("%s quota exceeded: %d of %d used, %d requested", e.Window, e.Used, e.Limit, e.Requested)
+}
+
+// RateLimitedError reports that a rate limit bucket of a quota is exhausted.
+type RateLimitedError struct {
+	Decision *ratelimit.Decision
+}
+
+func (e *RateLimitedError) Error() string {
+	return "rate limit exceeded"
+}
+
 type QuotaService struct {
-	repo *repository.QuotaRepository
+	repo            *repository.QuotaRepository
+	reservationRepo *repository.ReservationRepository
+	transactor      *repository.Transactor
+	channels        *ChannelService
+	limiter         *ratelimit.Limiter
+	changes         *ChangeService
+	alerts          *AlertService
+	config          config.QuotaConfig
 }
 
-func NewQuotaService(repo *repository.QuotaRepository) *QuotaService {
-	return &QuotaService{repo: repo}
+func NewQuotaService(repo *repository.QuotaRepository, reservationRepo *repository.ReservationRepository, transactor *repository.Transactor,
+	channels *ChannelService, limiter *ratelimit.Limiter, changes *ChangeService, alerts *AlertService, quotaConfig config.QuotaConfig) *QuotaService {
+	return &QuotaService{
+		repo:            repo,
+		reservationRepo: reservationRepo,
+		transactor:      transactor,
+		channels:        channels,
+		limiter:         limiter,
+		changes:         changes,
+		alerts:          alerts,
+		config:          quotaConfig,
+	}
 }
 
 // UpdateQuotas updates the quotas for a tenant.
@@ -28,17 +77,48 @@ func (s *QuotaService) UpdateQuotas(tenantID string, quotas []dto.QuotaDTO) erro
 	// Convert DTO to model
 	var quotaModels []model.Quota
 	for _, quota := range quotas {
+		channel, err := s.channels.ValidateForTenant(tenantID, quota.Channel)
+		if err != nil {
+			return err
+		}
+		windows, err := quotaWindowModels(quota.Windows)
+		if err != nil {
+			return err
+		}
+		thresholds, err := quotaThresholdModels(quota.Thresholds)
+		if err != nil {
+			return err
+		}
+		policy, err := validateOveragePolicy(quota.OveragePolicy, quota.OverageCeilingPercent)
+		if err != nil {
+			return err
+		}
 		quotaModels = append(quotaModels, model.Quota{
-			TenantID:     tenantID,
-			Channel:      quota.Channel,
-			DailyLimit:   quota.DailyLimit,
-			MonthlyLimit: quota.MonthlyLimit,
-			IsGlobal:     quota.IsGlobal,
+			TenantID:              tenantID,
+			Channel:               channel,
+			DailyLimit:            quota.DailyLimit,
+			MonthlyLimit:          quota.MonthlyLimit,
+			IsGlobal:              quota.IsGlobal,
+			RateLimitPerSecond:    quota.RateLimitPerSecond,
+			RateLimitPerMinute:    quota.RateLimitPerMinute,
+			RateLimitBurst:        quota.RateLimitBurst,
+			OveragePolicy:         policy,
+			OverageCeilingPercent: quota.OverageCeilingPercent,
+			Windows:               windows,
+			Thresholds:            thresholds,
 		})
 	}
 
-	// Call repository to upsert quotas
-	if err := s.repo.Upsert(quotaModels); err != nil {
+	// Upsert the quotas and publish them to watchers in one transaction
+	err := s.changes.Commit(func(tx *repository.Tx) error {
+		if err := tx.Quotas.Upsert(quotaModels); err != nil {
+			return err
+		}
+		return RecordChanges(tx, tenantID, model.ChangeResourceQuota, quotaModels, func(q model.Quota) string {
+			return q.Channel
+		})
+	})
+	if err != nil {
 		logger.Error("Error updating quotas", zap.Error(err))
 		return errors.New("failed to update quotas")
 	}
@@ -62,3 +142,313 @@ func (s *QuotaService) GetQuotas(tenantID string) ([]model.Quota, error) {
 
 	return quotas, nil
 }
+
+// GetQuotaStatus computes the limit, usage, remaining allowance and reset time of every
+// window of a tenant's quotas, optionally restricted to one channel. All windows are
+// computed in one transaction, so they reflect a single point in time.
+func (s *QuotaService) GetQuotaStatus(tenantID, channel string) ([]model.QuotaStatus, error) {
+	// Validation
+	if err := utils.ValidateNonEmptyString(tenantID, "TenantID"); err != nil {
+		return nil, err
+	}
+	if channel != "" {
+		var err error
+		if channel, err = s.channels.Normalize(channel); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	statuses := []model.QuotaStatus{}
+	err := s.transactor.Transaction(func(tx *repository.Tx) error {
+		tenant, err := tx.Tenants.FindByTenantID(tenantID)
+		if err != nil {
+			return err
+		}
+
+		quotas, err := tx.Quotas.FindByTenantID(tenantID)
+		if err != nil {
+			return err
+		}
+		for i := range quotas {
+			if channel != "" && quotas[i].Channel != channel {
+				continue
+			}
+			windows, err := quotaWindows(tx, &quotas[i], tenant, now)
+			if err != nil {
+				return err
+			}
+			statuses = append(statuses, model.QuotaStatus{
+				Channel:       quotas[i].Channel,
+				OveragePolicy: quotas[i].OveragePolicy,
+				Windows:       windows,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			return nil, fmt.Errorf("%w: tenant not found", pkgerr.ErrNotFound)
+		}
+		return nil, s.quotaError(err, "", "Error computing quota status", "failed to compute quota status")
+	}
+	if channel != "" && len(statuses) == 0 {
+		return nil, fmt.Errorf("%w: no quota configured for channel %q", pkgerr.ErrNotFound, channel)
+	}
+
+	return statuses, nil
+}
+
+// Consume atomically checks every window of a tenant's channel quota against current
+// usage and active reservations and, if count fits in all of them, records it as usage.
+// Whether count fits depends on the quota's overage policy; usage beyond the limits is
+// recorded as overage. The quota row stays locked for the duration, so concurrent callers
+// cannot overdraw the quota. Thresholds crossed by the consumption raise alerts.
+func (s *QuotaService) Consume(tenantID, channel string, count int) (*model.QuotaConsumption, error) {
+	return s.ConsumeFor(tenantID, channel, count, nil)
+}
+
+// ConsumeFor consumes quota like Consume and runs record in the same transaction, so that
+// what the quota was consumed for is stored if and only if the consumption is. Errors of
+// record are returned as they are.
+func (s *QuotaService) ConsumeFor(tenantID, channel string, count int, record func(tx *repository.Tx, channel string) error) (*model.QuotaConsumption, error) {
+	// Validation
+	channel, err := s.validateQuotaRequest(tenantID, channel, count)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	consumption := &model.QuotaConsumption{TenantID: tenantID, Channel: channel, Consumed: count}
+	var alerts []model.QuotaAlert
+	err = s.transactor.Transaction(func(tx *repository.Tx) error {
+		tenant, err := tx.Tenants.FindByTenantID(tenantID)
+		if err != nil {
+			return err
+		}
+		if _, err := s.channels.ValidateForTier(channel, tenant.BillingTier); err != nil {
+			return err
+		}
+		quota, err := tx.Quotas.FindForUpdate(tenantID, channel)
+		if err != nil {
+			return err
+		}
+
+		windows, err := quotaWindows(tx, quota, tenant, now)
+		if err != nil {
+			return err
+		}
+		delta := usageDelta(quota, windows, count)
+		if err := reserveInWindows(quota, windows, count, false); err != nil {
+			return err
+		}
+
+		consumption.Windows = windows
+		consumption.Overage = delta.Overage + delta.Flagged
+		consumption.OveragePolicy = quota.OveragePolicy
+		if err := tx.Usage.Increment(tenantID, channel, now, tenant.Location(), delta); err != nil {
+			return err
+		}
+		if alerts, err = raiseThresholdAlerts(tx, quota, windows, count); err != nil {
+			return err
+		}
+		if record != nil {
+			if err := record(tx, channel); err != nil {
+				return &recordError{err: err}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		var recordErr *recordError
+		if errors.As(err, &recordErr) {
+			return nil, recordErr.err
+		}
+		return nil, s.quotaError(err, channel, "Error consuming quota", "failed to consume quota")
+	}
+	s.alerts.Enqueue(alerts)
+
+	return consumption, nil
+}
+
+// recordError carries an error of the record callback of ConsumeFor past quota error handling.
+type recordError struct {
+	err error
+}
+
+func (e *recordError) Error() string {
+	return e.err.Error()
+}
+
+// reserveInWindows checks that count fits in every window under the quota's overage policy
+// and accounts for it, either as usage or, for reservations, as held capacity.
+func reserveInWindows(quota *model.Quota, windows []model.QuotaWindowStatus, count int, hold bool) error {
+	if quota.OveragePolicy != model.OveragePolicyFlag {
+		for _, window := range windows {
+			ceiling := window.Limit
+			if quota.OveragePolicy == model.OveragePolicyBilled {
+				ceiling = window.Ceiling
+			}
+			if window.Used+window.Reserved+count > ceiling {
+				return &QuotaExceededError{
+					Window:    window.Window,
+					Limit:     window.Limit,
+					Ceiling:   window.Ceiling,
+					Used:      window.Used + window.Reserved,
+					Requested: count,
+					ResetAt:   window.ResetAt,
+				}
+			}
+		}
+	}
+	for i := range windows {
+		if hold {
+			windows[i].Reserved += count
+			windows[i].Remaining = max(windows[i].Remaining-count, 0)
+		} else {
+			addWindowUsage(&windows[i], count)
+		}
+	}
+	return nil
+}
+
+// usageDelta splits count units of usage into the part within the quota limits and the
+// part beyond them, which is the largest excess over any single window. The excess is
+// billable under the billed policy and flagged under the flag policy.
+func usageDelta(quota *model.Quota, windows []model.QuotaWindowStatus, count int) model.UsageDelta {
+	excess := 0
+	for _, window := range windows {
+		excess = max(excess, max(window.Used+count-window.Limit, 0)-max(window.Used-window.Limit, 0))
+	}
+
+	delta := model.UsageDelta{Sent: count}
+	switch quota.OveragePolicy {
+	case model.OveragePolicyBilled:
+		delta.Overage = excess
+	case model.OveragePolicyFlag:
+		delta.Flagged = excess
+	}
+	return delta
+}
+
+// validateOveragePolicy validates the overage settings of a quota and returns its policy,
+// defaulting to blocking. Only the billed policy takes a ceiling, which must exceed 100%.
+func validateOveragePolicy(policy string, ceilingPercent int) (string, error) {
+	if policy == "" {
+		policy = model.OveragePolicyBlock
+	}
+	if err := utils.ValidateAllowedValues(policy, "OveragePolicy", model.OveragePolicies); err != nil {
+		return "", err
+	}
+	if policy == model.OveragePolicyBilled {
+		if err := utils.ValidateRange(ceilingPercent, "OverageCeilingPercent", 101, model.MaxOverageCeilingPercent); err != nil {
+			return "", err
+		}
+	} else if ceilingPercent != 0 {
+		return "", &utils.ValidationError{
+			Field:   "OverageCeilingPercent",
+			Message: fmt.Sprintf("Field is only allowed for the %s overage policy", model.OveragePolicyBilled),
+		}
+	}
+	return policy, nil
+}
+
+// quotaError passes through errors callers can act on and logs and hides the rest.
+func (s *QuotaService) quotaError(err error, channel, logMessage, message string) error {
+	var exceededErr *QuotaExceededError
+	var validationErr *utils.ValidationError
+	switch {
+	case errors.As(err, &exceededErr), errors.As(err, &validationErr), errors.Is(err, pkgerr.ErrConflict):
+		return err
+	case errors.Is(err, pkgerr.ErrNotFound):
+		if channel != "" {
+			return fmt.Errorf("%w: no quota configured for channel %q", pkgerr.ErrNotFound, channel)
+		}
+		return err
+	default:
+		logger.Error(logMessage, zap.Error(err))
+		return errors.New(message)
+	}
+}
+
+// validateQuotaRequest validates a request against a channel quota and returns the
+// catalog code of its channel.
+func (s *QuotaService) validateQuotaRequest(tenantID, channel string, count int) (string, error) {
+	if err := utils.ValidateNonEmptyString(tenantID, "TenantID"); err != nil {
+		return "", err
+	}
+	if count <= 0 {
+		return "", &utils.ValidationError{Field: "Count", Message: "Field must be positive"}
+	}
+	return s.channels.Normalize(channel)
+}
+
+// CheckRateLimit takes count tokens from the rate limit buckets of a tenant's channel. The
+// returned decision describes the buckets even when the request is rejected with a
+// RateLimitedError; it has no buckets when the quota defines no rate limits.
+func (s *QuotaService) CheckRateLimit(tenantID, channel string, count int) (*ratelimit.Decision, error) {
+	channel, err := s.validateQuotaRequest(tenantID, channel, count)
+	if err != nil {
+		return nil, err
+	}
+
+	quota, err := s.repo.FindByTenantIDAndChannel(tenantID, channel)
+	if err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			return nil, fmt.Errorf("%w: no quota configured for channel %q", pkgerr.ErrNotFound, channel)
+		}
+		logger.Error("Error fetching quota", zap.Error(err))
+		return nil, errors.New("failed to fetch quota")
+	}
+
+	limits := rateLimits(quota)
+	for _, limit := range limits {
+		if count > limit.Burst {
+			return nil, &utils.ValidationError{
+				Field:   "Count",
+				Message: fmt.Sprintf("Field must not exceed the rate limit capacity of %d per %s", limit.Burst, limit.Name),
+			}
+		}
+	}
+
+	decision, err := s.limiter.Allow(tenantID+"|"+channel, limits, count)
+	if err != nil {
+		logger.Error("Error checking rate limit", zap.Error(err))
+		return nil, errors.New("failed to check rate limit")
+	}
+	if !decision.Allowed {
+		return decision, &RateLimitedError{Decision: decision}
+	}
+	return decision, nil
+}
+
+// RefundRateLimit returns the tokens taken by CheckRateLimit for a request that was then
+// rejected, so that rejected requests do not count against the rate limits. The decision is
+// updated to describe the buckets after the refund.
+func (s *QuotaService) RefundRateLimit(decision *ratelimit.Decision) {
+	if err := s.limiter.Refund(decision); err != nil {
+		logger.Error("Error refunding rate limit tokens", zap.Error(err))
+	}
+}
+
+// rateLimits converts the rate limits of a quota into token buckets.
+func rateLimits(quota *model.Quota) []ratelimit.Limit {
+	var limits []ratelimit.Limit
+	if quota.RateLimitPerSecond > 0 {
+		limits = append(limits, ratelimit.Limit{
+			Name:   "second",
+			Tokens: quota.RateLimitPerSecond,
+			Period: time.Second,
+			Burst:  max(quota.RateLimitBurst, quota.RateLimitPerSecond),
+		})
+	}
+	if quota.RateLimitPerMinute > 0 {
+		limits = append(limits, ratelimit.Limit{
+			Name:   "minute",
+			Tokens: quota.RateLimitPerMinute,
+			Period: time.Minute,
+			Burst:  quota.RateLimitPerMinute,
+		})
+	}
+	return limits
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"fmt"
+	"tenant-management-service/internal/model"
+	"tenant-management-service/internal/model/dto"
+	"tenant-management-service/internal/repository"
+	"tenant-management-service/pkg/utils"
+	"time"
+)
+
+// quotaWindowModels validates the additional windows of a quota and converts them to models.
+// Daily and monthly limits have dedicated quota fields and cannot be repeated here.
+func quotaWindowModels(windows []dto.QuotaWindowDTO) ([]model.QuotaWindow, error) {
+	models := []model.QuotaWindow{}
+	for _, window := range windows {
+		allowed := []string{model.QuotaWindowHourly, model.QuotaWindowWeekly, model.QuotaWindowRolling}
+		if err := utils.ValidateAllowedValues(window.Window, "Window", allowed); err != nil {
+			return nil, err
+		}
+		if window.Window == model.QuotaWindowRolling {
+			if err := utils.ValidateRange(window.Hours, "Hours", 1, model.MaxRollingWindowHours); err != nil {
+				return nil, err
+			}
+		} else if window.Hours != 0 {
+			return nil, &utils.ValidationError{Field: "Hours", Message: fmt.Sprintf("Field is only allowed for %s windows", model.QuotaWindowRolling)}
+		}
+		models = append(models, model.QuotaWindow{Window: window.Window, Hours: window.Hours, Limit: window.Limit})
+	}
+	return models, nil
+}
+
+// quotaWindows computes the state of every window of a quota in the tenant's timezone,
+// counting both recorded usage and capacity held by active reservations.
+func quotaWindows(tx *repository.Tx, quota *model.Quota, tenant *model.Tenant, now time.Time) ([]model.QuotaWindowStatus, error) {
+	reserved, err := tx.Reservations.SumHeld(quota.TenantID, quota.Channel, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var windows []model.QuotaWindowStatus
+	for _, window := range allQuotaWindows(quota) {
+		start, end := windowBounds(window, now, tenant.Location(), tenant.BillingCycleAnchor)
+		used, err := windowUsage(tx.Usage, quota, window, start, end, tenant.Location())
+		if err != nil {
+			return nil, err
+		}
+
+		status := model.QuotaWindowStatus{
+			Window:   window.Window,
+			StartsAt: start.UTC(),
+			Hours:    window.Hours,
+			Limit:    window.Limit,
+			Reserved: reserved,
+			ResetAt:  end.UTC(),
+		}
+		if quota.OveragePolicy == model.OveragePolicyBilled {
+			status.Ceiling = window.Limit * quota.OverageCeilingPercent / 100
+		}
+		addWindowUsage(&status, used)
+		windows = append(windows, status)
+	}
+	return windows, nil
+}
+
+// addWindowUsage adds count to the usage of a window and updates what is left of it.
+func addWindowUsage(window *model.QuotaWindowStatus, count int) {
+	window.Used += count
+	window.Overage = max(window.Used-window.Limit, 0)
+	window.Remaining = max(window.Limit-window.Used-window.Reserved, 0)
+}
+
+// allQuotaWindows returns the daily and monthly limits of a quota followed by its
+// additional windows.
+func allQuotaWindows(quota *model.Quota) []model.QuotaWindow {
+	windows := []model.QuotaWindow{
+		{Window: model.QuotaWindowDaily, Limit: quota.DailyLimit},
+		{Window: model.QuotaWindowMonthly, Limit: quota.MonthlyLimit},
+	}
+	return append(windows, quota.Windows...)
+}
+
+// windowUsage sums the usage of a quota within a window. Sub-day windows are backed by
+// hourly buckets, the others by daily usage rows.
+func windowUsage(usageRepo *repository.UsageRepository, quota *model.Quota, window model.QuotaWindow, start, end time.Time, loc *time.Location) (int, error) {
+	switch window.Window {
+	case model.QuotaWindowHourly, model.QuotaWindowRolling:
+		return usageRepo.SumBuckets(quota.TenantID, quota.Channel, start.UTC(), end.UTC())
+	default:
+		lastDay := model.UsageDate(end.AddDate(0, 0, -1), loc)
+		return usageRepo.SumSent(quota.TenantID, quota.Channel, model.UsageDate(start, loc), lastDay)
+	}
+}
+
+// windowBounds returns the start and end of the window containing now. Calendar windows
+// follow the local time of loc; weeks start on Monday and months on the billing cycle
+// anchor day. A rolling window covers the current hour and the hours before it, and frees
+// capacity at every hour boundary.
+func windowBounds(window model.QuotaWindow, now time.Time, loc *time.Location, anchor int) (time.Time, time.Time) {
+	local := now.In(loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	hour := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), 0, 0, 0, loc)
+
+	switch window.Window {
+	case model.QuotaWindowHourly:
+		return hour, hour.Add(time.Hour)
+	case model.QuotaWindowWeekly:
+		start := midnight.AddDate(0, 0, -((int(local.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 7)
+	case model.QuotaWindowMonthly:
+		start := billingPeriodStart(local, anchor)
+		return start, start.AddDate(0, 1, 0)
+	case model.QuotaWindowRolling:
+		return hour.Add(-time.Duration(window.Hours-1) * time.Hour), hour.Add(time.Hour)
+	default:
+		return midnight, midnight.AddDate(0, 0, 1)
+	}
+}
+
+// billingPeriodStart returns the start of the billing period containing local, which
+// begins at midnight on the anchor day of a month.
+func billingPeriodStart(local time.Time, anchor int) time.Time {
+	if anchor < 1 || anchor > model.MaxBillingCycleAnchor {
+		anchor = 1
+	}
+	start := time.Date(local.Year(), local.Month(), anchor, 0, 0, 0, 0, local.Location())
+	if local.Before(start) {
+		start = start.AddDate(0, -1, 0)
+	}
+	return start
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"errors"
+	"tenant-management-service/internal/model"
+	"tenant-management-service/internal/model/dto"
+	"testing"
+	"time"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%q): %v", name, err)
+	}
+	return loc
+}
+
+func TestWindowBounds(t *testing.T) {
+	utc := time.UTC
+	tokyo := mustLoadLocation(t, "Asia/Tokyo")
+	newYork := mustLoadLocation(t, "America/New_York")
+	kolkata := mustLoadLocation(t, "Asia/Kolkata")
+	at := func(value string) time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", value, err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name   string
+		window model.QuotaWindow
+		now    string
+		loc    *time.Location
+		anchor int
+		start  string
+		end    string
+	}{
+		{"daily in UTC", model.QuotaWindow{Window: model.QuotaWindowDaily}, "2024-03-10T16:30:00Z", utc, 1,
+			"2024-03-10T00:00:00Z", "2024-03-11T00:00:00Z"},
+		{"daily follows the tenant's date", model.QuotaWindow{Window: model.QuotaWindowDaily}, "2024-03-10T16:30:00Z", tokyo, 1,
+			"2024-03-10T15:00:00Z", "2024-03-11T15:00:00Z"},
+		{"daily across a DST change", model.QuotaWindow{Window: model.QuotaWindowDaily}, "2024-03-10T12:00:00Z", newYork, 1,
+			"2024-03-10T05:00:00Z", "2024-03-11T04:00:00Z"},
+		{"hourly", model.QuotaWindow{Window: model.QuotaWindowHourly}, "2024-03-10T10:45:00Z", utc, 1,
+			"2024-03-10T10:00:00Z", "2024-03-10T11:00:00Z"},
+		{"hourly with a half-hour offset", model.QuotaWindow{Window: model.QuotaWindowHourly}, "2024-03-10T10:45:00Z", kolkata, 1,
+			"2024-03-10T10:30:00Z", "2024-03-10T11:30:00Z"},
+		{"weekly starts on Monday", model.QuotaWindow{Window: model.QuotaWindowWeekly}, "2024-03-13T09:00:00Z", utc, 1,
+			"2024-03-11T00:00:00Z", "2024-03-18T00:00:00Z"},
+		{"weekly on Sunday", model.QuotaWindow{Window: model.QuotaWindowWeekly}, "2024-03-17T23:59:59Z", utc, 1,
+			"2024-03-11T00:00:00Z", "2024-03-18T00:00:00Z"},
+		{"weekly at Monday midnight", model.QuotaWindow{Window: model.QuotaWindowWeekly}, "2024-03-18T00:00:00Z", utc, 1,
+			"2024-03-18T00:00:00Z", "2024-03-25T00:00:00Z"},
+		{"monthly", model.QuotaWindow{Window: model.QuotaWindowMonthly}, "2024-02-29T12:00:00Z", utc, 1,
+			"2024-02-01T00:00:00Z", "2024-03-01T00:00:00Z"},
+		{"monthly before the anchor day", model.QuotaWindow{Window: model.QuotaWindowMonthly}, "2024-03-10T12:00:00Z", utc, 15,
+			"2024-02-15T00:00:00Z", "2024-03-15T00:00:00Z"},
+		{"monthly on the anchor day", model.QuotaWindow{Window: model.QuotaWindowMonthly}, "2024-03-15T00:00:00Z", utc, 15,
+			"2024-03-15T00:00:00Z", "2024-04-15T00:00:00Z"},
+		{"monthly across the year", model.QuotaWindow{Window: model.QuotaWindowMonthly}, "2024-01-05T00:00:00Z", utc, 20,
+			"2023-12-20T00:00:00Z", "2024-01-20T00:00:00Z"},
+		{"monthly ignores an invalid anchor", model.QuotaWindow{Window: model.QuotaWindowMonthly}, "2024-03-10T12:00:00Z", utc, 31,
+			"2024-03-01T00:00:00Z", "2024-04-01T00:00:00Z"},
+		{"monthly in the tenant's timezone", model.QuotaWindow{Window: model.QuotaWindowMonthly}, "2024-03-31T16:00:00Z", tokyo, 1,
+			"2024-03-31T15:00:00Z", "2024-04-30T15:00:00Z"},
+		{"rolling", model.QuotaWindow{Window: model.QuotaWindowRolling, Hours: 24}, "2024-03-10T10:45:00Z", utc, 1,
+			"2024-03-09T11:00:00Z", "2024-03-10T11:00:00Z"},
+		{"rolling single hour", model.QuotaWindow{Window: model.QuotaWindowRolling, Hours: 1}, "2024-03-10T10:45:00Z", utc, 1,
+			"2024-03-10T10:00:00Z", "2024-03-10T11:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := windowBounds(tt.window, at(tt.now), tt.loc, tt.anchor)
+			if !start.Equal(at(tt.start)) || !end.Equal(at(tt.end)) {
+				t.Errorf("windowBounds = %s, %s, want %s, %s", start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339), tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestQuotaWindowModels(t *testing.T) {
+	valid := []dto.QuotaWindowDTO{
+		{Window: model.QuotaWindowHourly, Limit: 10},
+		{Window: model.QuotaWindowWeekly, Limit: 100},
+		{Window: model.QuotaWindowRolling, Hours: model.MaxRollingWindowHours, Limit: 50},
+	}
+	windows, err := quotaWindowModels(valid)
+	if err != nil {
+		t.Fatalf("quotaWindowModels: %v", err)
+	}
+	if len(windows) != 3 || windows[2].Hours != model.MaxRollingWindowHours || windows[2].Limit != 50 {
+		t.Errorf("quotaWindowModels = %+v", windows)
+	}
+
+	for _, invalid := range []dto.QuotaWindowDTO{
+		{Window: model.QuotaWindowDaily, Limit: 10},
+		{Window: model.QuotaWindowMonthly, Limit: 10},
+		{Window: "yearly", Limit: 10},
+		{Window: model.QuotaWindowRolling, Limit: 10},
+		{Window: model.QuotaWindowRolling, Hours: model.MaxRollingWindowHours + 1, Limit: 10},
+		{Window: model.QuotaWindowHourly, Hours: 2, Limit: 10},
+	} {
+		if _, err := quotaWindowModels([]dto.QuotaWindowDTO{invalid}); err == nil {
+			t.Errorf("quotaWindowModels(%+v) succeeded, want an error", invalid)
+		}
+	}
+}
+
+func TestReserveInWindows(t *testing.T) {
+	windows := func() []model.QuotaWindowStatus {
+		return []model.QuotaWindowStatus{
+			{Window: model.QuotaWindowDaily, Limit: 10, Ceiling: 15, Used: 6, Reserved: 2, Remaining: 2},
+			{Window: model.QuotaWindowMonthly, Limit: 100, Ceiling: 150, Used: 50, Remaining: 50},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		policy   string
+		count    int
+		exceeded bool
+	}{
+		{"block within the limit", model.OveragePolicyBlock, 2, false},
+		{"block beyond the limit", model.OveragePolicyBlock, 3, true},
+		{"billed within the ceiling", model.OveragePolicyBilled, 7, false},
+		{"billed beyond the ceiling", model.OveragePolicyBilled, 8, true},
+		{"flag beyond any limit", model.OveragePolicyFlag, 1000, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quota := &model.Quota{OveragePolicy: tt.policy}
+			err := reserveInWindows(quota, windows(), tt.count, false)
+			var exceededErr *QuotaExceededError
+			if tt.exceeded != errors.As(err, &exceededErr) {
+				t.Fatalf("reserveInWindows = %v, want exceeded %v", err, tt.exceeded)
+			}
+			if tt.exceeded && (exceededErr.Window != model.QuotaWindowDaily || exceededErr.Used != 8 || exceededErr.Requested != tt.count) {
+				t.Errorf("QuotaExceededError = %+v", exceededErr)
+			}
+		})
+	}
+
+	// Consumption is added to the usage of every window
+	consumed := windows()
+	if err := reserveInWindows(&model.Quota{OveragePolicy: model.OveragePolicyBilled}, consumed, 5, false); err != nil {
+		t.Fatalf("reserveInWindows: %v", err)
+	}
+	if daily := consumed[0]; daily.Used != 11 || daily.Overage != 1 || daily.Remaining != 0 {
+		t.Errorf("daily window after consumption = %+v", daily)
+	}
+	if monthly := consumed[1]; monthly.Used != 55 || monthly.Remaining != 45 {
+		t.Errorf("monthly window after consumption = %+v", monthly)
+	}
+
+	// Reservations hold capacity without using it
+	held := windows()
+	if err := reserveInWindows(&model.Quota{OveragePolicy: model.OveragePolicyBlock}, held, 2, true); err != nil {
+		t.Fatalf("reserveInWindows: %v", err)
+	}
+	if daily := held[0]; daily.Used != 6 || daily.Reserved != 4 || daily.Remaining != 0 {
+		t.Errorf("daily window after reservation = %+v", daily)
+	}
+}
+
+func TestUsageDelta(t *testing.T) {
+	windows := []model.QuotaWindowStatus{
+		{Window: model.QuotaWindowDaily, Limit: 10, Used: 8},
+		{Window: model.QuotaWindowMonthly, Limit: 100, Used: 99},
+	}
+	tests := []struct {
+		name   string
+		policy string
+		used   int
+		count  int
+		want   model.UsageDelta
+	}{
+		{"within the limits", model.OveragePolicyBilled, 0, 1, model.UsageDelta{Sent: 1}},
+		{"largest excess over any window", model.OveragePolicyBilled, 0, 5, model.UsageDelta{Sent: 5, Overage: 4}},
+		{"already beyond a limit", model.OveragePolicyBilled, 5, 2, model.UsageDelta{Sent: 2, Overage: 2}},
+		{"flagged", model.OveragePolicyFlag, 0, 5, model.UsageDelta{Sent: 5, Flagged: 4}},
+		{"blocking policies record no excess", model.OveragePolicyBlock, 0, 5, model.UsageDelta{Sent: 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := append([]model.QuotaWindowStatus(nil), windows...)
+			for i := range current {
+				current[i].Used += tt.used
+			}
+			got := usageDelta(&model.Quota{OveragePolicy: tt.policy}, current, tt.count)
+			if got != tt.want {
+				t.Errorf("usageDelta = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -16,7 +16,7 @@ func NewTenantService(repo *repository.TenantRepository) *TenantService {
 	return &TenantService{repo: repo}
 }
 
-func (s *TenantService) CreateTenant(name, email, phone, billingTier, defaultLanguage string) (*model.Tenant, error) {
+func (s *TenantService) CreateTenant(name, email, phone, billingTier, defaultLanguage, timezone string, billingCycleAnchor int) (*model.Tenant, error) {
 
 	// Perform validations
 	if err := utils.ValidateNonEmptyString(name, "name"); err != nil {
@@ -28,12 +28,24 @@ func (s *TenantService) CreateTenant(name, email, phone, billingTier, defaultLan
 	if err := utils.ValidatePhone(phone); err != nil {
 		return nil, err
 	}
-	if err := utils.ValidateAllowedValues(billingTier, "BillingTier", []string{"basic", "standard", "enterprise"}); err != nil {
+	if err := utils.ValidateAllowedValues(billingTier, "BillingTier", model.BillingTiers); err != nil {
 		return nil, err
 	}
 	if err := utils.ValidateMaxLength(defaultLanguage, "DefaultLanguage", 5); err != nil {
 		return nil, err
 	}
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if err := utils.ValidateTimezone(timezone, "Timezone"); err != nil {
+		return nil, err
+	}
+	if billingCycleAnchor == 0 {
+		billingCycleAnchor = 1
+	}
+	if err := utils.ValidateRange(billingCycleAnchor, "BillingCycleAnchor", 1, model.MaxBillingCycleAnchor); err != nil {
+		return nil, err
+	}
 
 	// Generate secure client ID and client secret
 	clientID := utils.GenerateUUID()                   // UUID is fine for client ID
@@ -43,13 +55,15 @@ func (s *TenantService) CreateTenant(name, email, phone, billingTier, defaultLan
 	}
 
 	tenant := &model.Tenant{
-		Name:            name,
-		ClientID:        clientID,
-		ClientSecret:    clientSecret,
-		Email:           email,
-		Phone:           phone,
-		BillingTier:     billingTier,
-		DefaultLanguage: defaultLanguage,
+		Name:               name,
+		ClientID:           clientID,
+		ClientSecret:       clientSecret,
+		Email:              email,
+		Phone:              phone,
+		BillingTier:        billingTier,
+		DefaultLanguage:    defaultLanguage,
+		Timezone:           timezone,
+		BillingCycleAnchor: billingCycleAnchor,
 	}
 
 	// Save the tenant in the repository
@@ -70,7 +84,7 @@ func (s *TenantService) GetTenantByID(id uint) (*model.Tenant, error) {
 }
 
 // UpdateTenant updates the details of an existing tenant.
-func (s *TenantService) UpdateTenant(id uint, name, email, phone, billingTier, defaultLanguage string) error {
+func (s *TenantService) UpdateTenant(id uint, name, email, phone, billingTier, defaultLanguage, timezone string, billingCycleAnchor int) error {
 	tenant, err := s.repo.FindById(id)
 	if err != nil {
 		return errors.New("tenant not found")
@@ -96,7 +110,7 @@ func (s *TenantService) UpdateTenant(id uint, name, email, phone, billingTier, d
 		tenant.Phone = phone
 	}
 	if billingTier != "" {
-		if err := utils.ValidateAllowedValues(billingTier, "BillingTier", []string{"basic", "standard", "enterprise"}); err != nil {
+		if err := utils.ValidateAllowedValues(billingTier, "BillingTier", model.BillingTiers); err != nil {
 			return err
 		}
 		tenant.BillingTier = billingTier
@@ -107,6 +121,18 @@ func (s *TenantService) UpdateTenant(id uint, name, email, phone, billingTier, d
 		}
 		tenant.DefaultLanguage = defaultLanguage
 	}
+	if timezone != "" {
+		if err := utils.ValidateTimezone(timezone, "Timezone"); err != nil {
+			return err
+		}
+		tenant.Timezone = timezone
+	}
+	if billingCycleAnchor != 0 {
+		if err := utils.ValidateRange(billingCycleAnchor, "BillingCycleAnchor", 1, model.MaxBillingCycleAnchor); err != nil {
+			return err
+		}
+		tenant.BillingCycleAnchor = billingCycleAnchor
+	}
 
 	// Save the updated tenant
 	return s.repo.Update(tenant)
@@ -122,17 +148,27 @@ func (s *TenantService) DeleteTenant(id uint) error {
 
 // ValidateClientCredentials validates the client_id and client_secret.
 func (s *TenantService) ValidateClientCredentials(clientID, clientSecret string) (bool, error) {
+	tenant, err := s.AuthenticateClient(clientID, clientSecret)
+	if err != nil {
+		return false, err // Internal error
+	}
+	return tenant != nil, nil
+}
+
+// AuthenticateClient returns the tenant owning the client_id and client_secret,
+// or nil if the credentials are invalid.
+func (s *TenantService) AuthenticateClient(clientID, clientSecret string) (*model.Tenant, error) {
 	tenant, err := s.repo.FindByClientId(clientID)
 	if err != nil {
 		if errors.Is(err, pkgerr.ErrNotFound) {
-			return false, nil // Invalid client_id
+			return nil, nil // Invalid client_id
 		}
-		return false, err // Internal error
+		return nil, err // Internal error
 	}
 
 	// Compare client_secret
 	if tenant.ClientSecret != clientSecret {
-		return false, nil
+		return nil, nil
 	}
-	return true, nil
+	return tenant, nil
 }
@@ -1,35 +1,430 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"go.uber.org/zap"
+	"hash/fnv"
+	"sort"
+	"tenant-management-service/internal/config"
 	"tenant-management-service/internal/model"
+	"tenant-management-service/internal/model/dto"
 	"tenant-management-service/internal/repository"
+	"tenant-management-service/pkg/counter"
+	pkgerr "tenant-management-service/pkg/error"
 	"tenant-management-service/pkg/logger"
 	"tenant-management-service/pkg/utils"
+	"time"
 )
 
+const (
+	// MaxUsageEventBatch is the largest number of usage events accepted at once.
+	MaxUsageEventBatch = 1000
+	// maxUsageEventAge and maxUsageEventSkew bound how far in the past and in the future
+	// the timestamp of a usage event may lie.
+	maxUsageEventAge  = 30 * 24 * time.Hour
+	maxUsageEventSkew = 5 * time.Minute
+)
+
+const (
+	// minUsageRollupAge keeps the daily usage that quota windows and forecasts read from
+	// being rolled up, whatever the configured age. Days that have yet to be invoiced are
+	// kept regardless of their age.
+	minUsageRollupAge = 62 * 24 * time.Hour
+	// minBucketRetention keeps the hourly buckets of the longest rolling quota window.
+	minBucketRetention = model.MaxRollingWindowHours * time.Hour
+)
+
+// finalFlushAttempts is how often the last flush on shutdown is tried before the counts
+// still held in memory are given up.
+const finalFlushAttempts = 3
+
+// usageKey identifies the usage with one outcome counted for a tenant's channel in one
+// hour. Date is the tenant's local calendar day of that hour, as stored in Usage.Date.
+type usageKey struct {
+	tenantID string
+	channel  string
+	outcome  string
+	date     time.Time
+	hour     time.Time
+}
+
 type UsageService struct {
-	repo *repository.UsageRepository
+	repo       *repository.UsageRepository
+	tenantRepo *repository.TenantRepository
+	channels   *ChannelService
+	alerts     *AlertService
+	counts     *counter.Sharded[usageKey]
+	config     config.UsageConfig
 }
 
-func NewUsageService(repo *repository.UsageRepository) *UsageService {
-	return &UsageService{repo: repo}
+func NewUsageService(repo *repository.UsageRepository, tenantRepo *repository.TenantRepository, channels *ChannelService,
+	alerts *AlertService, usageConfig config.UsageConfig) *UsageService {
+	return &UsageService{
+		repo:       repo,
+		tenantRepo: tenantRepo,
+		channels:   channels,
+		alerts:     alerts,
+		counts:     counter.New(usageConfig.CounterShards, hashUsageKey),
+		config:     usageConfig,
+	}
 }
 
-// GetUsage retrieves usage data for a tenant, optionally filtered by channel.
-func (s *UsageService) GetUsage(tenantID string, channel string) ([]model.Usage, error) {
+// GetUsage aggregates the usage of a tenant as described by query, returning a page of
+// usage points, the totals per outcome over the whole range and the number of points
+// across all pages.
+func (s *UsageService) GetUsage(query model.UsageQuery) ([]model.UsagePoint, map[string]int, int64, error) {
 	// Validation
-	if err := utils.ValidateNonEmptyString(tenantID, "TenantID"); err != nil {
-		return nil, err
+	if err := s.ValidateUsageQuery(&query); err != nil {
+		return nil, nil, 0, err
 	}
 
 	// Fetch usage data from repository
-	usage, err := s.repo.FindByTenantIDAndChannel(tenantID, channel)
+	points, total, err := s.repo.Aggregate(query)
 	if err != nil {
 		logger.Error("Error fetching usage data", zap.Error(err))
-		return nil, errors.New("failed to fetch usage data")
+		return nil, nil, 0, errors.New("failed to fetch usage data")
+	}
+	totals, err := s.repo.Totals(query)
+	if err != nil {
+		logger.Error("Error fetching usage totals", zap.Error(err))
+		return nil, nil, 0, errors.New("failed to fetch usage data")
+	}
+
+	return points, totals, total, nil
+}
+
+// ExportUsage streams the usage aggregated as described by query to write, one point at a
+// time and without pagination.
+func (s *UsageService) ExportUsage(query model.UsageQuery, write func(point model.UsagePoint) error) error {
+	// Validation
+	if err := s.ValidateUsageQuery(&query); err != nil {
+		return err
+	}
+
+	var writeErr error
+	err := s.repo.StreamAggregate(query, func(point model.UsagePoint) error {
+		writeErr = write(point)
+		return writeErr
+	})
+	if err != nil {
+		if writeErr != nil {
+			return writeErr
+		}
+		logger.Error("Error exporting usage data", zap.Error(err))
+		return errors.New("failed to export usage data")
+	}
+	return nil
+}
+
+// ValidateUsageQuery validates the filters of a usage query, normalizing its channel and
+// defaulting to daily granularity.
+func (s *UsageService) ValidateUsageQuery(query *model.UsageQuery) error {
+	if err := utils.ValidateNonEmptyString(query.TenantID, "TenantID"); err != nil {
+		return err
+	}
+	if query.Channel != "" {
+		var err error
+		if query.Channel, err = s.channels.Normalize(query.Channel); err != nil {
+			return err
+		}
+	}
+	if query.Granularity == "" {
+		query.Granularity = model.UsageGranularityDay
+	}
+	if err := utils.ValidateAllowedValues(query.Granularity, "Granularity", model.UsageGranularities); err != nil {
+		return err
+	}
+	if query.From != nil && query.To != nil && query.From.After(*query.To) {
+		return &utils.ValidationError{Field: "From", Message: "Field must not be after To"}
+	}
+	return nil
+}
+
+// RecordEvents validates usage events of a tenant and counts them towards the daily usage
+// of the tenant's timezone. Either every event is accepted or, if one is invalid, none.
+func (s *UsageService) RecordEvents(tenantID string, events []dto.UsageEventDTO) (int, error) {
+	// Validation
+	if err := utils.ValidateNonEmptyString(tenantID, "TenantID"); err != nil {
+		return 0, err
+	}
+	if len(events) == 0 || len(events) > MaxUsageEventBatch {
+		return 0, &utils.ValidationError{Field: "Events", Message: fmt.Sprintf("Field must contain between 1 and %d events", MaxUsageEventBatch)}
+	}
+
+	tenant, err := s.tenantRepo.FindByTenantID(tenantID)
+	if err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			return 0, fmt.Errorf("%w: tenant not found", pkgerr.ErrNotFound)
+		}
+		logger.Error("Error fetching tenant", zap.Error(err))
+		return 0, errors.New("failed to fetch tenant")
+	}
+
+	// Validate every event before counting any of them
+	type usageEvent struct {
+		channel, outcome string
+		at               time.Time
+		count            int
+	}
+	now := time.Now()
+	accepted := make([]usageEvent, len(events))
+	for i, event := range events {
+		field := fmt.Sprintf("Events[%d]", i)
+		channel, err := s.channels.ValidateForTier(event.Channel, tenant.BillingTier)
+		if err != nil {
+			return 0, withFieldPrefix(err, field)
+		}
+
+		outcome := event.Outcome
+		if outcome == "" || outcome == model.UsageOutcomeSent {
+			outcome = model.UsageOutcomeAccepted
+		}
+		if err := utils.ValidateAllowedValues(outcome, field+".Outcome", model.UsageOutcomes); err != nil {
+			return 0, err
+		}
+
+		count := 1
+		if event.Count != nil {
+			count = *event.Count
+		}
+		if count <= 0 {
+			return 0, &utils.ValidationError{Field: field + ".Count", Message: "Field must be positive"}
+		}
+
+		at := now
+		if event.Timestamp != nil {
+			at = *event.Timestamp
+		}
+		if at.Before(now.Add(-maxUsageEventAge)) || at.After(now.Add(maxUsageEventSkew)) {
+			return 0, &utils.ValidationError{
+				Field:   field + ".Timestamp",
+				Message: fmt.Sprintf("Field must lie within the last %d days and not in the future", int(maxUsageEventAge.Hours()/24)),
+			}
+		}
+
+		accepted[i] = usageEvent{channel: channel, outcome: outcome, at: at, count: count}
+	}
+
+	total := 0
+	for _, event := range accepted {
+		s.Track(tenantID, event.channel, event.outcome, event.at, tenant.Location(), event.count)
+		total += event.count
 	}
+	return total, nil
+}
+
+// withFieldPrefix qualifies the field of a validation error with the position of the item
+// it was found in.
+func withFieldPrefix(err error, prefix string) error {
+	var validationErr *utils.ValidationError
+	if errors.As(err, &validationErr) {
+		return &utils.ValidationError{Field: prefix + "." + validationErr.Field, Message: validationErr.Message}
+	}
+	return err
+}
+
+// Track counts usage of a tenant's channel with an outcome in memory. Counts reach the
+// usage table with the next flush, so they are not visible to reads and quota checks
+// until then.
+func (s *UsageService) Track(tenantID, channel, outcome string, at time.Time, loc *time.Location, count int) {
+	key := usageKey{
+		tenantID: tenantID,
+		channel:  channel,
+		outcome:  outcome,
+		date:     model.UsageDate(at, loc),
+		hour:     model.UsageHour(at, loc),
+	}
+	s.counts.Add(key, int64(count))
+}
+
+// RunFlusher writes tracked usage to the database once per flush interval until ctx is
+// cancelled, and a final time afterwards so that a graceful stop loses no counts.
+func (s *UsageService) RunFlusher(ctx context.Context) {
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.flush()
+		case <-ctx.Done():
+			for attempt := 1; attempt <= finalFlushAttempts; attempt++ {
+				if s.flush() {
+					return
+				}
+				time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
+			}
+			lost := s.counts.Drain()
+			logger.Error("Usage counts lost on shutdown", zap.Int("keys", len(lost)))
+			return
+		}
+	}
+}
+
+// flush drains the tracked counts into the usage table, putting them back if the write
+// fails so that the next flush retries them, and then checks the quota thresholds the
+// flushed usage may have crossed. It reports whether the write succeeded.
+func (s *UsageService) flush() bool {
+	drained := s.counts.Drain()
+	if len(drained) == 0 {
+		return true
+	}
+
+	usages, buckets := usageRows(drained)
+	if err := s.repo.AddCounts(usages, buckets); err != nil {
+		logger.Error("Error flushing usage counts", zap.Int("keys", len(drained)), zap.Error(err))
+		s.counts.Merge(drained)
+		return false
+	}
+
+	for key, n := range sentCounts(drained) {
+		if err := s.alerts.CheckThresholds(key.tenantID, key.channel, n); err != nil {
+			logger.Error("Error checking quota thresholds", zap.String("tenant_id", key.tenantID), zap.String("channel", key.channel), zap.Error(err))
+		}
+	}
+	return true
+}
+
+// channelKey identifies a tenant's channel.
+type channelKey struct {
+	tenantID, channel string
+}
+
+// sentCounts sums the counts of accepted notifications, which count towards quotas, by
+// tenant and channel.
+func sentCounts(counts map[usageKey]int64) map[channelKey]int {
+	sent := make(map[channelKey]int)
+	for key, n := range counts {
+		if countsTowardsQuota(key.outcome) {
+			sent[channelKey{key.tenantID, key.channel}] += int(n)
+		}
+	}
+	return sent
+}
+
+// countsTowardsQuota reports whether usage with an outcome counts towards quotas.
+func countsTowardsQuota(outcome string) bool {
+	switch outcome {
+	case model.UsageOutcomeDelivered, model.UsageOutcomeFailed, model.UsageOutcomeUndelivered,
+		model.UsageOutcomeBounced, model.UsageOutcomeComplained, model.UsageOutcomeSuppressed:
+		return false
+	}
+	return true
+}
+
+// RollupUsage rolls daily usage of the calendar months that ended before the configured
+// age up into monthly rollups, one month per transaction, and deletes hourly buckets past
+// their retention.
+func (s *UsageService) RollupUsage(ctx context.Context) {
+	now := time.Now().UTC()
+	cutoff := now.Add(-max(s.config.RollupAfter, minUsageRollupAge))
+	cutoff = time.Date(cutoff.Year(), cutoff.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	for ctx.Err() == nil {
+		oldest, err := s.repo.OldestDateBefore(cutoff)
+		if err != nil {
+			logger.Error("Error finding usage to roll up", zap.Error(err))
+			return
+		}
+		if oldest == nil {
+			break
+		}
+
+		month := time.Date(oldest.Year(), oldest.Month(), 1, 0, 0, 0, 0, time.UTC)
+		rows, err := s.repo.RollupMonth(month)
+		if err != nil {
+			logger.Error("Error rolling up usage", zap.Time("month", month), zap.Error(err))
+			return
+		}
+		logger.Info("Usage rolled up", zap.Time("month", month), zap.Int64("rows", rows))
+	}
+
+	deleted, err := s.repo.DeleteBucketsBefore(now.Add(-max(s.config.BucketRetention, minBucketRetention)))
+	if err != nil {
+		logger.Error("Error deleting expired usage buckets", zap.Error(err))
+		return
+	}
+	if deleted > 0 {
+		logger.Info("Expired usage buckets deleted", zap.Int64("count", deleted))
+	}
+}
+
+// usageRows aggregates hourly counts into daily usage rows and hourly buckets, sorted by
+// their unique keys. Buckets back quota windows and only count accepted notifications.
+func usageRows(counts map[usageKey]int64) ([]model.Usage, []model.UsageBucket) {
+	type dayKey struct {
+		tenantID, channel string
+		date              time.Time
+	}
+	type hourKey struct {
+		tenantID, channel string
+		hour              time.Time
+	}
+	days := make(map[dayKey]*model.Usage)
+	hours := make(map[hourKey]int)
+	for key, n := range counts {
+		day := days[dayKey{key.tenantID, key.channel, key.date}]
+		if day == nil {
+			day = &model.Usage{TenantID: key.tenantID, Channel: key.channel, Date: key.date}
+			days[dayKey{key.tenantID, key.channel, key.date}] = day
+		}
+		switch key.outcome {
+		case model.UsageOutcomeDelivered:
+			day.NotificationsDelivered += int(n)
+		case model.UsageOutcomeFailed:
+			day.NotificationsFailed += int(n)
+		case model.UsageOutcomeUndelivered:
+			day.NotificationsUndelivered += int(n)
+		case model.UsageOutcomeBounced:
+			day.NotificationsBounced += int(n)
+		case model.UsageOutcomeComplained:
+			day.NotificationsComplained += int(n)
+		case model.UsageOutcomeSuppressed:
+			day.NotificationsSuppressed += int(n)
+		default:
+			day.NotificationsSent += int(n)
+			hours[hourKey{key.tenantID, key.channel, key.hour}] += int(n)
+		}
+	}
+
+	usages := make([]model.Usage, 0, len(days))
+	for _, day := range days {
+		usages = append(usages, *day)
+	}
+	buckets := make([]model.UsageBucket, 0, len(hours))
+	for key, n := range hours {
+		buckets = append(buckets, model.UsageBucket{TenantID: key.tenantID, Channel: key.channel, BucketStart: key.hour, NotificationsSent: n})
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		a, b := usages[i], usages[j]
+		if a.TenantID != b.TenantID {
+			return a.TenantID < b.TenantID
+		}
+		if a.Channel != b.Channel {
+			return a.Channel < b.Channel
+		}
+		return a.Date.Before(b.Date)
+	})
+	sort.Slice(buckets, func(i, j int) bool {
+		a, b := buckets[i], buckets[j]
+		if a.TenantID != b.TenantID {
+			return a.TenantID < b.TenantID
+		}
+		if a.Channel != b.Channel {
+			return a.Channel < b.Channel
+		}
+		return a.BucketStart.Before(b.BucketStart)
+	})
+	return usages, buckets
+}
 
-	return usage, nil
+// hashUsageKey spreads the keys of different tenants and channels over counter shards.
+func hashUsageKey(key usageKey) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key.tenantID))
+	h.Write([]byte{0})
+	h.Write([]byte(key.channel))
+	return h.Sum32()
 }
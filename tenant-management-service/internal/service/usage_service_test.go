@@ -0,0 +1,67 @@
+package service
+
+import (
+	"reflect"
+	"tenant-management-service/internal/model"
+	"testing"
+	"time"
+)
+
+func TestUsageRows(t *testing.T) {
+	day := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	nextDay := day.AddDate(0, 0, 1)
+	hour := time.Date(2024, 3, 10, 9, 0, 0, 0, time.UTC)
+	counts := map[usageKey]int64{
+		{tenantID: "b", channel: "sms", outcome: model.UsageOutcomeAccepted, date: day, hour: hour}:                           4,
+		{tenantID: "a", channel: "sms", outcome: model.UsageOutcomeAccepted, date: day, hour: hour}:                           2,
+		{tenantID: "a", channel: "sms", outcome: model.UsageOutcomeAccepted, date: day, hour: hour.Add(time.Hour)}:            3,
+		{tenantID: "a", channel: "sms", outcome: model.UsageOutcomeDelivered, date: day, hour: hour}:                          1,
+		{tenantID: "a", channel: "sms", outcome: model.UsageOutcomeFailed, date: day, hour: hour}:                             5,
+		{tenantID: "a", channel: "sms", outcome: model.UsageOutcomeUndelivered, date: day, hour: hour}:                        6,
+		{tenantID: "a", channel: "sms", outcome: model.UsageOutcomeBounced, date: day, hour: hour}:                            7,
+		{tenantID: "a", channel: "sms", outcome: model.UsageOutcomeComplained, date: day, hour: hour}:                         8,
+		{tenantID: "a", channel: "sms", outcome: model.UsageOutcomeSuppressed, date: day, hour: hour}:                         9,
+		{tenantID: "a", channel: "email", outcome: model.UsageOutcomeAccepted, date: nextDay, hour: hour.Add(24 * time.Hour)}: 1,
+		{tenantID: "a", channel: "email", outcome: model.UsageOutcomeAccepted, date: day, hour: hour}:                         1,
+	}
+
+	usages, buckets := usageRows(counts)
+
+	wantUsages := []model.Usage{
+		{TenantID: "a", Channel: "email", Date: day, NotificationsSent: 1},
+		{TenantID: "a", Channel: "email", Date: nextDay, NotificationsSent: 1},
+		{TenantID: "a", Channel: "sms", Date: day, NotificationsSent: 5, NotificationsDelivered: 1, NotificationsFailed: 5,
+			NotificationsUndelivered: 6, NotificationsBounced: 7, NotificationsComplained: 8, NotificationsSuppressed: 9},
+		{TenantID: "b", Channel: "sms", Date: day, NotificationsSent: 4},
+	}
+	if !reflect.DeepEqual(usages, wantUsages) {
+		t.Errorf("usages = %+v, want %+v", usages, wantUsages)
+	}
+
+	// Only accepted notifications count towards the hourly buckets
+	wantBuckets := []model.UsageBucket{
+		{TenantID: "a", Channel: "email", BucketStart: hour, NotificationsSent: 1},
+		{TenantID: "a", Channel: "email", BucketStart: hour.Add(24 * time.Hour), NotificationsSent: 1},
+		{TenantID: "a", Channel: "sms", BucketStart: hour, NotificationsSent: 2},
+		{TenantID: "a", Channel: "sms", BucketStart: hour.Add(time.Hour), NotificationsSent: 3},
+		{TenantID: "b", Channel: "sms", BucketStart: hour, NotificationsSent: 4},
+	}
+	if !reflect.DeepEqual(buckets, wantBuckets) {
+		t.Errorf("buckets = %+v, want %+v", buckets, wantBuckets)
+	}
+}
+
+func TestSentCounts(t *testing.T) {
+	day := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	counts := map[usageKey]int64{
+		{tenantID: "a", channel: "sms", outcome: model.UsageOutcomeAccepted, date: day, hour: day}:                   2,
+		{tenantID: "a", channel: "sms", outcome: model.UsageOutcomeAccepted, date: day, hour: day.Add(time.Hour)}:    3,
+		{tenantID: "a", channel: "sms", outcome: model.UsageOutcomeDelivered, date: day, hour: day}:                  4,
+		{tenantID: "a", channel: "email", outcome: model.UsageOutcomeSuppressed, date: day, hour: day}:               1,
+		{tenantID: "b", channel: "sms", outcome: model.UsageOutcomeAccepted, date: day.AddDate(0, 0, -1), hour: day}: 1,
+	}
+	want := map[channelKey]int{{"a", "sms"}: 5, {"b", "sms"}: 1}
+	if got := sentCounts(counts); !reflect.DeepEqual(got, want) {
+		t.Errorf("sentCounts = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"net/url"
+	"tenant-management-service/internal/model"
+	"tenant-management-service/internal/model/dto"
+	"tenant-management-service/internal/repository"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/logger"
+	"tenant-management-service/pkg/safehttp"
+	"tenant-management-service/pkg/utils"
+	"time"
+)
+
+// webhookResolveTimeout bounds resolving the host of a webhook URL during validation.
+const webhookResolveTimeout = 5 * time.Second
+
+// WebhookService manages the webhook endpoints tenants register for the webhook channel.
+type WebhookService struct {
+	repo *repository.WebhookRepository
+}
+
+func NewWebhookService(repo *repository.WebhookRepository) *WebhookService {
+	return &WebhookService{repo: repo}
+}
+
+// UpsertEndpoint registers or updates a webhook endpoint of a tenant. New endpoints get a
+// signing secret, which is returned only then; updated endpoints keep theirs.
+func (s *WebhookService) UpsertEndpoint(tenantID string, endpointDTO dto.WebhookEndpointDTO) (*model.WebhookEndpoint, error) {
+	// Validation
+	if err := utils.ValidateSlug(endpointDTO.Name, "Name", 100); err != nil {
+		return nil, err
+	}
+	if err := validateWebhookURL(endpointDTO.URL); err != nil {
+		return nil, err
+	}
+
+	// Convert DTO to model
+	endpoint := &model.WebhookEndpoint{
+		TenantID: tenantID,
+		Name:     endpointDTO.Name,
+		URL:      endpointDTO.URL,
+		Enabled:  endpointDTO.Enabled == nil || *endpointDTO.Enabled,
+	}
+	secret, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		logger.Error("Error generating webhook secret", zap.Error(err))
+		return nil, errors.New("failed to generate webhook secret")
+	}
+	endpoint.Secret = secret
+
+	// Call repository to upsert the endpoint
+	created, err := s.repo.Upsert(endpoint)
+	if err != nil {
+		logger.Error("Error upserting webhook endpoint", zap.Error(err))
+		return nil, errors.New("failed to upsert webhook endpoint")
+	}
+	if !created {
+		endpoint.Secret = ""
+	}
+	return endpoint, nil
+}
+
+// validateWebhookURL checks that a webhook URL is an absolute http or https URL whose host
+// is public. Hosts are checked again on every delivery, as names may resolve differently
+// by then.
+func validateWebhookURL(rawURL string) error {
+	if err := utils.ValidateMaxLength(rawURL, "URL", 2048); err != nil {
+		return err
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return &utils.ValidationError{Field: "URL", Message: "Field must be an absolute http or https URL"}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), webhookResolveTimeout)
+	defer cancel()
+	if err := safehttp.CheckHost(ctx, parsed.Hostname()); err != nil {
+		return &utils.ValidationError{Field: "URL", Message: "Field must point to a public address"}
+	}
+	return nil
+}
+
+// GetEndpoints retrieves the webhook endpoints of a tenant without their secrets.
+func (s *WebhookService) GetEndpoints(tenantID string) ([]model.WebhookEndpoint, error) {
+	endpoints, err := s.repo.FindByTenantID(tenantID)
+	if err != nil {
+		logger.Error("Error fetching webhook endpoints", zap.Error(err))
+		return nil, errors.New("failed to fetch webhook endpoints")
+	}
+	for i := range endpoints {
+		endpoints[i].Secret = ""
+	}
+	return endpoints, nil
+}
+
+// RotateSecret replaces the signing secret of a webhook endpoint and returns the endpoint
+// with its new secret. Payloads are signed with the new secret from then on.
+func (s *WebhookService) RotateSecret(tenantID, name string) (*model.WebhookEndpoint, error) {
+	secret, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		logger.Error("Error generating webhook secret", zap.Error(err))
+		return nil, errors.New("failed to generate webhook secret")
+	}
+	endpoint, err := s.repo.UpdateSecret(tenantID, name, secret)
+	if err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			return nil, fmt.Errorf("%w: webhook endpoint not found", pkgerr.ErrNotFound)
+		}
+		logger.Error("Error rotating webhook secret", zap.Error(err))
+		return nil, errors.New("failed to rotate webhook secret")
+	}
+	return endpoint, nil
+}
+
+// DeleteEndpoint removes a webhook endpoint of a tenant. Notifications still queued for it
+// fail on their next attempt.
+func (s *WebhookService) DeleteEndpoint(tenantID, name string) error {
+	if err := s.repo.DeleteByName(tenantID, name); err != nil {
+		if errors.Is(err, pkgerr.ErrNotFound) {
+			return fmt.Errorf("%w: webhook endpoint not found", pkgerr.ErrNotFound)
+		}
+		logger.Error("Error deleting webhook endpoint", zap.Error(err))
+		return errors.New("failed to delete webhook endpoint")
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"errors"
+	"tenant-management-service/pkg/utils"
+	"testing"
+)
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{"https://93.184.216.34/hooks", true},
+		{"http://93.184.216.34:8080/hooks", true},
+		{"ftp://93.184.216.34/hooks", false},
+		{"/hooks", false},
+		{"https://127.0.0.1/hooks", false},
+		{"http://10.0.0.5/hooks", false},
+		{"http://169.254.169.254/latest/meta-data/", false},
+		{"http://[::1]:8080/hooks", false},
+		{"http://[::ffff:127.0.0.1]/hooks", false},
+		{"http://localhost:8080/hooks", false},
+		{"http://internal.localhost/hooks", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := validateWebhookURL(tt.url)
+			if tt.valid && err != nil {
+				t.Errorf("validateWebhookURL(%q) = %v, want nil", tt.url, err)
+			}
+			var validationErr *utils.ValidationError
+			if !tt.valid && !errors.As(err, &validationErr) {
+				t.Errorf("validateWebhookURL(%q) = %v, want a validation error", tt.url, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package broadcast
+
+import "sync"
+
+// Broadcaster wakes up every goroutine waiting on it when Notify is called.
+type Broadcaster struct {
+	mu sync.Mutex
+	ch chan struct{}
+}
+
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{ch: make(chan struct{})}
+}
+
+// Wait returns a channel that is closed on the next call to Notify.
+func (b *Broadcaster) Wait() <-chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.ch
+}
+
+// Notify releases all current waiters.
+func (b *Broadcaster) Notify() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	close(b.ch)
+	b.ch = make(chan struct{})
+}
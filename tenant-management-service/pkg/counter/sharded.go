@@ -0,0 +1,64 @@
+package counter
+
+import "sync"
+
+type shard[K comparable] struct {
+	mu     sync.Mutex
+	counts map[K]int64
+}
+
+// Sharded accumulates integer deltas per key in memory. Keys are spread over
+// independently locked shards so that concurrent writers to different keys rarely contend.
+type Sharded[K comparable] struct {
+	shards []*shard[K]
+	hash   func(K) uint32
+}
+
+// New creates a counter with the given number of shards, assigning keys to shards by hash.
+func New[K comparable](shards int, hash func(K) uint32) *Sharded[K] {
+	if shards < 1 {
+		shards = 1
+	}
+	c := &Sharded[K]{shards: make([]*shard[K], shards), hash: hash}
+	for i := range c.shards {
+		c.shards[i] = &shard[K]{counts: make(map[K]int64)}
+	}
+	return c
+}
+
+// Add adds n to the count of key.
+func (c *Sharded[K]) Add(key K, n int64) {
+	s := c.shards[c.hash(key)%uint32(len(c.shards))]
+	s.mu.Lock()
+	s.counts[key] += n
+	s.mu.Unlock()
+}
+
+// Merge adds every count of counts, typically ones returned by Drain that could not be
+// persisted.
+func (c *Sharded[K]) Merge(counts map[K]int64) {
+	for key, n := range counts {
+		c.Add(key, n)
+	}
+}
+
+// Drain returns the accumulated counts and resets the counter. Counts added concurrently
+// either end up in the result or stay in the counter, never both.
+func (c *Sharded[K]) Drain() map[K]int64 {
+	drained := make(map[K]int64)
+	for _, s := range c.shards {
+		s.mu.Lock()
+		if len(s.counts) == 0 {
+			s.mu.Unlock()
+			continue
+		}
+		counts := s.counts
+		s.counts = make(map[K]int64)
+		s.mu.Unlock()
+
+		for key, n := range counts {
+			drained[key] += n
+		}
+	}
+	return drained
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"log"
 	"tenant-management-service/internal/config"
 	"tenant-management-service/internal/model"
@@ -11,7 +12,7 @@ import (
 
 func Connect(config config.DatabaseConfig) (*gorm.DB, error) {
 	dsn := fmt.Sprintf(
-		"%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		"%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=UTC",
 		config.User, config.Password, config.Host, config.Port, config.DBName,
 	)
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
@@ -34,11 +35,52 @@ func Close(db *gorm.DB) {
 	}
 }
 
-func RunMigrations(db *gorm.DB) error {
-	return db.AutoMigrate(
-		&model.Tenant{},
-		&model.Configuration{},
-		&model.Quota{},
-		&model.Usage{},
-	)
+// models lists the models whose tables are managed by the migrations.
+var models = []interface{}{
+	&model.Tenant{},
+	&model.Configuration{},
+	&model.ConfigSchema{},
+	&model.ConfigPreset{},
+	&model.ConfigPresetEntry{},
+	&model.Quota{},
+	&model.QuotaWindow{},
+	&model.QuotaThreshold{},
+	&model.QuotaReservation{},
+	&model.QuotaAlert{},
+	&model.Usage{},
+	&model.UsageBucket{},
+	&model.UsageRollup{},
+	&model.ChangeEvent{},
+	&model.FeatureFlag{},
+	&model.FeatureFlagTierOverride{},
+	&model.Channel{},
+	&model.ChannelTierOverride{},
+	&model.Plan{},
+	&model.PlanChannelPrice{},
+	&model.PlanOverageTier{},
+	&model.Invoice{},
+	&model.InvoiceLineItem{},
+	&model.Notification{},
+	&model.NotificationAttempt{},
+	&model.WebhookEndpoint{},
+	&model.DeviceToken{},
+	&model.ChangeSequence{},
+}
+
+// RunMigrations migrates the schema and then the data of existing databases.
+func RunMigrations(db *gorm.DB, config config.DatabaseConfig) error {
+	if err := prepareSchema(db, config); err != nil {
+		return err
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		return err
+	}
+	return migrateData(db)
+}
+
+// SeedChannels adds the default channels missing from the channel catalog, leaving
+// channels that already exist untouched.
+func SeedChannels(db *gorm.DB) error {
+	channels := append([]model.Channel(nil), model.DefaultChannels...)
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&channels).Error
 }
@@ -0,0 +1,342 @@
+package database
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"reflect"
+	"slices"
+	"strings"
+	"tenant-management-service/internal/config"
+	"tenant-management-service/internal/model"
+	"time"
+)
+
+// Names of one-off migrations, recorded once they are applied.
+const (
+	// migrationUTCTimestamps converts timestamps to UTC.
+	migrationUTCTimestamps = "utc-timestamps"
+	// migrationNormalizeChannels rewrites channel references to catalog codes.
+	migrationNormalizeChannels = "normalize-channels"
+)
+
+// calendarDateColumns are the time columns holding calendar dates rather than instants,
+// by table. Their dates were stored as midnight and stay as they are.
+var calendarDateColumns = map[string][]string{
+	"usages":        {"date"},
+	"usage_rollups": {"month"},
+}
+
+// prepareSchema fixes data of existing databases that would keep AutoMigrate from
+// migrating the schema, such as rows violating unique indexes it is about to create.
+// Earlier versions inserted a new configuration, quota or usage row on every write.
+func prepareSchema(db *gorm.DB, config config.DatabaseConfig) error {
+	if err := db.AutoMigrate(&model.SchemaMigration{}); err != nil {
+		return err
+	}
+	if err := convertTimestampsToUTC(db, config.LegacyTimezone); err != nil {
+		return err
+	}
+	if err := normalizeChannels(db); err != nil {
+		return err
+	}
+	if err := deleteOlderDuplicates(db, &model.Configuration{}, "idx_config_tenant_key", "tenant_id", "config_key"); err != nil {
+		return err
+	}
+	if err := deleteOlderDuplicates(db, &model.Quota{}, "idx_quota_tenant_channel", "tenant_id", "channel"); err != nil {
+		return err
+	}
+	if err := mergeDuplicates(db, &model.Usage{}, "idx_usage_tenant_channel_date", []string{"tenant_id", "channel", "date"}, usageCounterColumns); err != nil {
+		return err
+	}
+	if err := mergeDuplicates(db, &model.UsageRollup{}, "idx_rollup_tenant_channel_month", []string{"tenant_id", "channel", "month"}, usageCounterColumns); err != nil {
+		return err
+	}
+	return mergeDuplicates(db, &model.UsageBucket{}, "idx_bucket_tenant_channel_start", []string{"tenant_id", "channel", "bucket_start"}, []string{"notifications_sent"})
+}
+
+// convertTimestampsToUTC converts the timestamps of a database written while the service
+// stored them in the local time of legacyTimezone to UTC, once. Databases without tenants
+// hold no such timestamps and are only marked as converted.
+func convertTimestampsToUTC(db *gorm.DB, legacyTimezone string) error {
+	if applied, err := migrationApplied(db, migrationUTCTimestamps); err != nil || applied {
+		return err
+	}
+
+	var tenants int64
+	if db.Migrator().HasTable(&model.Tenant{}) {
+		if err := db.Model(&model.Tenant{}).Count(&tenants).Error; err != nil {
+			return err
+		}
+	}
+	var loc *time.Location
+	if tenants > 0 {
+		var err error
+		if loc, err = legacyLocation(legacyTimezone); err != nil {
+			return err
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if loc != nil && loc != time.UTC {
+			for _, value := range models {
+				if err := convertTableTimestamps(tx, value, loc); err != nil {
+					return err
+				}
+			}
+		}
+		return recordMigration(tx, migrationUTCTimestamps)
+	})
+}
+
+// legacyLocation returns the timezone timestamps were stored in before they were stored in
+// UTC. Earlier versions connected with loc=Local, so it is the local timezone of the
+// server unless legacyTimezone names another.
+func legacyLocation(legacyTimezone string) (*time.Location, error) {
+	if legacyTimezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(legacyTimezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid database.legacy_timezone: %w", err)
+	}
+	return loc, nil
+}
+
+// convertTableTimestamps reinterprets the timestamps of a table, read as UTC, as local
+// times of loc and stores them in UTC.
+func convertTableTimestamps(db *gorm.DB, value interface{}, loc *time.Location) error {
+	if !db.Migrator().HasTable(value) {
+		return nil
+	}
+	statement := &gorm.Statement{DB: db}
+	if err := statement.Parse(value); err != nil {
+		return err
+	}
+	table := statement.Schema.Table
+	var columns []string
+	for _, field := range statement.Schema.Fields {
+		if field.DBName == "" || field.IndirectFieldType != reflect.TypeOf(time.Time{}) ||
+			slices.Contains(calendarDateColumns[table], field.DBName) || !db.Migrator().HasColumn(value, field.DBName) {
+			continue
+		}
+		columns = append(columns, field.DBName)
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	// Page through the rows by primary key
+	primary := statement.Schema.PrioritizedPrimaryField
+	var lastID interface{} = 0
+	if primary.IndirectFieldType.Kind() == reflect.String {
+		lastID = ""
+	}
+	for {
+		var rows []map[string]interface{}
+		err := db.Table(table).Select(append([]string{primary.DBName}, columns...)).
+			Where(primary.DBName+" > ?", lastID).Order(primary.DBName).Limit(500).Find(&rows).Error
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		for _, row := range rows {
+			updates := map[string]interface{}{}
+			for _, column := range columns {
+				if t, ok := row[column].(time.Time); ok {
+					updates[column] = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc).UTC()
+				}
+			}
+			if len(updates) > 0 {
+				if err := db.Table(table).Where(primary.DBName+" = ?", row[primary.DBName]).UpdateColumns(updates).Error; err != nil {
+					return err
+				}
+			}
+			lastID = row[primary.DBName]
+		}
+	}
+}
+
+// legacyChannelAliases maps names clients used for channels before channel references were
+// validated against the catalog to catalog codes.
+var legacyChannelAliases = map[string]string{
+	"text":     "sms",
+	"mail":     "email",
+	"e-mail":   "email",
+	"web-push": "webpush",
+	"in-app":   "in_app",
+}
+
+// channelTables are the tables referring to channels along with the unique index covering
+// their channel column, if any.
+var channelTables = []struct {
+	value interface{}
+	index string
+}{
+	{&model.Quota{}, "idx_quota_tenant_channel"},
+	{&model.QuotaReservation{}, ""},
+	{&model.Usage{}, "idx_usage_tenant_channel_date"},
+	{&model.UsageRollup{}, "idx_rollup_tenant_channel_month"},
+	{&model.UsageBucket{}, "idx_bucket_tenant_channel_start"},
+}
+
+// normalizeChannels rewrites the channels of quotas, reservations and usage written before
+// channel references were validated, such as "SMS" or "text", to catalog codes, once.
+// Rewritten rows may collide with rows of the catalog code, so the unique index of their
+// table is dropped for the duplicates to be merged before AutoMigrate recreates it.
+func normalizeChannels(db *gorm.DB) error {
+	if applied, err := migrationApplied(db, migrationNormalizeChannels); err != nil || applied {
+		return err
+	}
+
+	for _, table := range channelTables {
+		if !db.Migrator().HasTable(table.value) {
+			continue
+		}
+		statement := &gorm.Statement{DB: db}
+		if err := statement.Parse(table.value); err != nil {
+			return err
+		}
+
+		// Compare channels byte by byte, as the collation ignores case and trailing spaces
+		var channels []string
+		if err := db.Raw("SELECT DISTINCT BINARY channel FROM " + statement.Schema.Table).Scan(&channels).Error; err != nil {
+			return err
+		}
+		renames := make(map[string]string)
+		for _, channel := range channels {
+			if code := normalizeChannel(channel); code != channel {
+				renames[channel] = code
+			}
+		}
+		if len(renames) == 0 {
+			continue
+		}
+
+		if table.index != "" && db.Migrator().HasIndex(table.value, table.index) {
+			if err := db.Migrator().DropIndex(table.value, table.index); err != nil {
+				return err
+			}
+		}
+		for channel, code := range renames {
+			err := db.Table(statement.Schema.Table).Where("BINARY channel = ?", channel).UpdateColumn("channel", code).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return recordMigration(db, migrationNormalizeChannels)
+}
+
+// normalizeChannel returns the catalog code a legacy channel reference stands for.
+func normalizeChannel(channel string) string {
+	code := strings.ToLower(strings.TrimSpace(channel))
+	if alias, ok := legacyChannelAliases[code]; ok {
+		return alias
+	}
+	return code
+}
+
+// migrationApplied reports whether a one-off migration has been applied.
+func migrationApplied(db *gorm.DB, name string) (bool, error) {
+	var applied int64
+	if err := db.Model(&model.SchemaMigration{}).Where("name = ?", name).Count(&applied).Error; err != nil {
+		return false, err
+	}
+	return applied > 0, nil
+}
+
+// recordMigration records that a one-off migration has been applied.
+func recordMigration(db *gorm.DB, name string) error {
+	return db.Create(&model.SchemaMigration{Name: name, AppliedAt: time.Now()}).Error
+}
+
+// usageCounterColumns are the columns of usage rows holding counts.
+var usageCounterColumns = []string{
+	"notifications_sent",
+	"notifications_delivered",
+	"notifications_failed",
+	"notifications_undelivered",
+	"notifications_bounced",
+	"notifications_complained",
+	"notifications_suppressed",
+	"overage_sent",
+	"flagged_sent",
+}
+
+// deleteOlderDuplicates keeps only the newest row of every group of rows sharing the key
+// columns of a unique index that does not exist yet.
+func deleteOlderDuplicates(db *gorm.DB, value interface{}, index string, keyColumns ...string) error {
+	table, ok, err := tableMissingIndex(db, value, index)
+	if err != nil || !ok {
+		return err
+	}
+	return db.Exec(fmt.Sprintf("DELETE older FROM %s older JOIN %s newer ON %s AND newer.id > older.id",
+		table, table, joinOn("older", "newer", keyColumns))).Error
+}
+
+// mergeDuplicates merges every group of rows sharing the key columns of a unique index that
+// does not exist yet into the oldest row of the group, summing their counter columns.
+func mergeDuplicates(db *gorm.DB, value interface{}, index string, keyColumns, counterColumns []string) error {
+	table, ok, err := tableMissingIndex(db, value, index)
+	if err != nil || !ok {
+		return err
+	}
+
+	// Only sum the counters the table has already been migrated to
+	var sums, assignments []string
+	for _, column := range counterColumns {
+		if db.Migrator().HasColumn(value, column) {
+			sums = append(sums, fmt.Sprintf("SUM(%s) AS %s", column, column))
+			assignments = append(assignments, fmt.Sprintf("kept.%s = merged.%s", column, column))
+		}
+	}
+	keys := strings.Join(keyColumns, ", ")
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if len(sums) > 0 {
+			err := tx.Exec(fmt.Sprintf(`UPDATE %s kept JOIN (
+					SELECT MIN(id) AS id, %s FROM %s GROUP BY %s HAVING COUNT(*) > 1
+				) merged ON kept.id = merged.id SET %s`,
+				table, strings.Join(sums, ", "), table, keys, strings.Join(assignments, ", "))).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Exec(fmt.Sprintf("DELETE merged FROM %s merged JOIN %s kept ON %s AND kept.id < merged.id",
+			table, table, joinOn("merged", "kept", keyColumns))).Error
+	})
+}
+
+// tableMissingIndex returns the table of a model and whether it exists without the index.
+func tableMissingIndex(db *gorm.DB, value interface{}, index string) (string, bool, error) {
+	statement := &gorm.Statement{DB: db}
+	if err := statement.Parse(value); err != nil {
+		return "", false, err
+	}
+	migrator := db.Migrator()
+	return statement.Schema.Table, migrator.HasTable(value) && !migrator.HasIndex(value, index), nil
+}
+
+// joinOn returns the condition joining two aliases of a table on equal key columns.
+func joinOn(left, right string, keyColumns []string) string {
+	conditions := make([]string, len(keyColumns))
+	for i, column := range keyColumns {
+		conditions[i] = fmt.Sprintf("%s.%s = %s.%s", right, column, left, column)
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// migrateData brings the data of existing databases in line with the current schema. Every
+// step is idempotent, so it runs on each startup.
+func migrateData(db *gorm.DB) error {
+	return seedChangeSequence(db)
+}
+
+// seedChangeSequence creates the change sequence unless it exists, starting at revision 0.
+func seedChangeSequence(db *gorm.DB) error {
+	sequence := model.ChangeSequence{ID: model.ChangeSequenceID}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&sequence).Error
+}
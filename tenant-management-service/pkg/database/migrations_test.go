@@ -0,0 +1,46 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizeChannel(t *testing.T) {
+	tests := map[string]string{
+		"sms":      "sms",
+		"SMS":      "sms",
+		" Email ":  "email",
+		"text":     "sms",
+		"TEXT":     "sms",
+		"e-mail":   "email",
+		"In-App":   "in_app",
+		"web-push": "webpush",
+		"carrier":  "carrier",
+	}
+	for channel, want := range tests {
+		if got := normalizeChannel(channel); got != want {
+			t.Errorf("normalizeChannel(%q) = %q, want %q", channel, got, want)
+		}
+	}
+}
+
+func TestLegacyLocation(t *testing.T) {
+	loc, err := legacyLocation("")
+	if err != nil || loc != time.Local {
+		t.Errorf("legacyLocation(\"\") = %v, %v, want the local timezone", loc, err)
+	}
+
+	loc, err = legacyLocation("Asia/Tokyo")
+	if err != nil || loc.String() != "Asia/Tokyo" {
+		t.Errorf("legacyLocation(\"Asia/Tokyo\") = %v, %v, want Asia/Tokyo", loc, err)
+	}
+
+	loc, err = legacyLocation("UTC")
+	if err != nil || loc != time.UTC {
+		t.Errorf("legacyLocation(\"UTC\") = %v, %v, want UTC", loc, err)
+	}
+
+	if _, err := legacyLocation("Mars/Olympus_Mons"); err == nil {
+		t.Error("legacyLocation accepted an unknown timezone")
+	}
+}
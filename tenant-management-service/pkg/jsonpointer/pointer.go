@@ -0,0 +1,111 @@
+// Package jsonpointer implements RFC 6901 JSON pointers over documents decoded into
+// generic values (map[string]interface{}, []interface{} and scalars).
+package jsonpointer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Pointer is a parsed JSON pointer. The empty pointer refers to the whole document.
+type Pointer []string
+
+// Parse parses a JSON pointer such as "/routes/0/target".
+func Parse(pointer string) (Pointer, error) {
+	if pointer == "" {
+		return Pointer{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q: must be empty or start with '/'", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		// "~1" must be decoded before "~0" so that "~01" yields "~1"
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// String returns the pointer in its encoded form.
+func (p Pointer) String() string {
+	var b strings.Builder
+	for _, token := range p {
+		b.WriteString("/")
+		b.WriteString(strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return b.String()
+}
+
+// Get returns the value the pointer refers to.
+func (p Pointer) Get(doc interface{}) (interface{}, error) {
+	current := doc
+	for i, token := range p {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("path %s not found", p[:i+1])
+			}
+			current = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(node))
+			if err != nil {
+				return nil, fmt.Errorf("path %s: %w", p[:i+1], err)
+			}
+			current = node[index]
+		default:
+			return nil, fmt.Errorf("path %s not found", p[:i+1])
+		}
+	}
+	return current, nil
+}
+
+// Set stores value at the pointer and returns the updated document. The parent of the
+// target must exist; the token "-" appends to an array.
+func (p Pointer) Set(doc interface{}, value interface{}) (interface{}, error) {
+	if len(p) == 0 {
+		return value, nil
+	}
+
+	parent, err := p[:len(p)-1].Get(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	token := p[len(p)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[token] = value
+	case []interface{}:
+		var updated []interface{}
+		if token == "-" {
+			updated = append(node, value)
+		} else {
+			index, err := arrayIndex(token, len(node))
+			if err != nil {
+				return nil, fmt.Errorf("path %s: %w", p, err)
+			}
+			node[index] = value
+			updated = node
+		}
+		// Appending may reallocate the array, so re-link it into its own parent
+		return p[:len(p)-1].Set(doc, updated)
+	default:
+		return nil, fmt.Errorf("path %s: parent is not an object or array", p)
+	}
+	return doc, nil
+}
+
+func arrayIndex(token string, length int) (int, error) {
+	if token == "0" || (token != "" && token[0] != '0' && token[0] != '-' && token[0] != '+') {
+		if index, err := strconv.Atoi(token); err == nil {
+			if index >= length {
+				return 0, fmt.Errorf("index %d out of range", index)
+			}
+			return index, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid array index %q", token)
+}
@@ -0,0 +1,146 @@
+package jsonpointer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func decode(t *testing.T, doc string) interface{} {
+	t.Helper()
+	var value interface{}
+	if err := json.Unmarshal([]byte(doc), &value); err != nil {
+		t.Fatalf("decoding %s: %v", doc, err)
+	}
+	return value
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		pointer string
+		want    Pointer
+	}{
+		{"", Pointer{}},
+		{"/", Pointer{""}},
+		{"/a/0", Pointer{"a", "0"}},
+		{"/a~1b", Pointer{"a/b"}},
+		{"/m~0n", Pointer{"m~n"}},
+		{"/~01", Pointer{"~1"}},
+		{"/~10", Pointer{"/0"}},
+		{"/-", Pointer{"-"}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.pointer)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.pointer, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Parse(%q) = %q, want %q", tt.pointer, got, tt.want)
+		}
+		if got.String() != tt.pointer {
+			t.Errorf("Parse(%q).String() = %q", tt.pointer, got.String())
+		}
+	}
+
+	if _, err := Parse("a/b"); err == nil {
+		t.Error("Parse(\"a/b\") succeeded, want an error")
+	}
+}
+
+func TestGet(t *testing.T) {
+	// The example document of RFC 6901
+	doc := decode(t, `{"foo":["bar","baz"],"":0,"a/b":1,"c%d":2,"e^f":3,"g|h":4,"i\\j":5,"k\"l":6," ":7,"m~n":8}`)
+	tests := []struct {
+		pointer string
+		want    interface{}
+	}{
+		{"", doc},
+		{"/foo", []interface{}{"bar", "baz"}},
+		{"/foo/0", "bar"},
+		{"/", 0.0},
+		{"/a~1b", 1.0},
+		{"/c%d", 2.0},
+		{"/e^f", 3.0},
+		{"/g|h", 4.0},
+		{"/i\\j", 5.0},
+		{"/k\"l", 6.0},
+		{"/ ", 7.0},
+		{"/m~0n", 8.0},
+	}
+	for _, tt := range tests {
+		pointer, err := Parse(tt.pointer)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.pointer, err)
+		}
+		got, err := pointer.Get(doc)
+		if err != nil {
+			t.Errorf("Get(%q): %v", tt.pointer, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Get(%q) = %v, want %v", tt.pointer, got, tt.want)
+		}
+	}
+}
+
+func TestGetErrors(t *testing.T) {
+	doc := decode(t, `{"a":[1,2],"s":"x"}`)
+	for _, pointer := range []string{"/b", "/a/2", "/a/-", "/a/01", "/a/-1", "/a/+1", "/a/x", "/a/", "/s/0"} {
+		parsed, err := Parse(pointer)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", pointer, err)
+		}
+		if _, err := parsed.Get(doc); err == nil {
+			t.Errorf("Get(%q) succeeded, want an error", pointer)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		pointer string
+		value   string
+		want    string
+	}{
+		{"whole document", `{"a":1}`, "", `[1]`, `[1]`},
+		{"new member", `{"a":1}`, "/b", `2`, `{"a":1,"b":2}`},
+		{"replace member", `{"a":{"b":1}}`, "/a/b", `"x"`, `{"a":{"b":"x"}}`},
+		{"escaped member", `{}`, "/a~1b~0c", `1`, `{"a/b~c":1}`},
+		{"replace item", `{"a":[1,2]}`, "/a/1", `3`, `{"a":[1,3]}`},
+		{"append item", `{"a":[1,2]}`, "/a/-", `3`, `{"a":[1,2,3]}`},
+		{"append to nested array", `{"a":[[1]]}`, "/a/0/-", `2`, `{"a":[[1,2]]}`},
+		{"append to root array", `[1]`, "/-", `2`, `[1,2]`},
+		{"dash is a member name in objects", `{}`, "/-", `1`, `{"-":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pointer, err := Parse(tt.pointer)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			got, err := pointer.Set(decode(t, tt.doc), decode(t, tt.value))
+			if err != nil {
+				t.Fatalf("Set: %v", err)
+			}
+			if want := decode(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("Set = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestSetErrors(t *testing.T) {
+	doc := `{"a":[1],"s":"x"}`
+	for _, pointer := range []string{"/b/c", "/a/1", "/a/x", "/s/0", "/a/-/0"} {
+		parsed, err := Parse(pointer)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", pointer, err)
+		}
+		if _, err := parsed.Set(decode(t, doc), 1.0); err == nil {
+			t.Errorf("Set(%q) succeeded, want an error", pointer)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+// Package jsonschema validates documents against a practical subset of JSON Schema:
+// type, enum, const, properties, required, additionalProperties, items, the numeric,
+// string and array bounds, pattern, allOf, anyOf, oneOf and not. Other keywords such
+// as $schema, title or description are accepted and ignored.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a compiled JSON Schema.
+type Schema struct {
+	// boolean schemas: true accepts everything, false rejects everything
+	always *bool
+
+	Type                 typeList           `json:"type"`
+	Enum                 []interface{}      `json:"enum"`
+	Const                *interface{}       `json:"const"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *Schema            `json:"additionalProperties"`
+	Items                *Schema            `json:"items"`
+	Minimum              *json.Number       `json:"minimum"`
+	Maximum              *json.Number       `json:"maximum"`
+	ExclusiveMinimum     *json.Number       `json:"exclusiveMinimum"`
+	ExclusiveMaximum     *json.Number       `json:"exclusiveMaximum"`
+	MinLength            *int               `json:"minLength"`
+	MaxLength            *int               `json:"maxLength"`
+	Pattern              string             `json:"pattern"`
+	MinItems             *int               `json:"minItems"`
+	MaxItems             *int               `json:"maxItems"`
+	AllOf                []*Schema          `json:"allOf"`
+	AnyOf                []*Schema          `json:"anyOf"`
+	OneOf                []*Schema          `json:"oneOf"`
+	Not                  *Schema            `json:"not"`
+
+	pattern *regexp.Regexp
+}
+
+// typeList accepts both "type": "string" and "type": ["string", "null"].
+type typeList []string
+
+func (t *typeList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = typeList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("type must be a string or an array of strings")
+	}
+	*t = list
+	return nil
+}
+
+func (s *Schema) UnmarshalJSON(data []byte) error {
+	var always bool
+	if err := json.Unmarshal(data, &always); err == nil {
+		*s = Schema{always: &always}
+		return nil
+	}
+
+	// Decode through an alias type to avoid recursing into this method
+	type plain Schema
+	var decoded plain
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&decoded); err != nil {
+		return err
+	}
+	*s = Schema(decoded)
+
+	// A null constant decodes into a nil pointer, indistinguishable from no constant
+	if s.Const == nil {
+		var keywords map[string]json.RawMessage
+		if err := json.Unmarshal(data, &keywords); err != nil {
+			return err
+		}
+		if _, ok := keywords["const"]; ok {
+			s.Const = new(interface{})
+		}
+	}
+	return nil
+}
+
+// Compile parses a JSON Schema document.
+func Compile(data []byte) (*Schema, error) {
+	var schema Schema
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&schema); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	if err := schema.compile("#"); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+func (s *Schema) compile(path string) error {
+	if s == nil || s.always != nil {
+		return nil
+	}
+	for _, t := range s.Type {
+		switch t {
+		case "null", "boolean", "object", "array", "number", "integer", "string":
+		default:
+			return fmt.Errorf("invalid schema at %s: unknown type %q", path, t)
+		}
+	}
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid schema at %s: %w", path, err)
+		}
+		s.pattern = pattern
+	}
+	for name, property := range s.Properties {
+		if err := property.compile(path + "/properties/" + name); err != nil {
+			return err
+		}
+	}
+	if err := s.AdditionalProperties.compile(path + "/additionalProperties"); err != nil {
+		return err
+	}
+	if err := s.Items.compile(path + "/items"); err != nil {
+		return err
+	}
+	for keyword, group := range map[string][]*Schema{"allOf": s.AllOf, "anyOf": s.AnyOf, "oneOf": s.OneOf} {
+		for i, sub := range group {
+			if err := sub.compile(fmt.Sprintf("%s/%s/%d", path, keyword, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return s.Not.compile(path + "/not")
+}
+
+// ValidationError describes why a document does not match a schema.
+type ValidationError struct {
+	Failures []string
+}
+
+func (e *ValidationError) Error() string {
+	return "document does not match schema: " + strings.Join(e.Failures, "; ")
+}
+
+// Validate checks a document decoded with json.Number numbers against the schema.
+func (s *Schema) Validate(doc interface{}) error {
+	var failures []string
+	s.validate(doc, "", &failures)
+	if len(failures) > 0 {
+		return &ValidationError{Failures: failures}
+	}
+	return nil
+}
+
+func (s *Schema) validate(value interface{}, path string, failures *[]string) {
+	fail := func(format string, args ...interface{}) {
+		location := path
+		if location == "" {
+			location = "/"
+		}
+		*failures = append(*failures, location+": "+fmt.Sprintf(format, args...))
+	}
+
+	if s == nil {
+		return
+	}
+	if s.always != nil {
+		if !*s.always {
+			fail("no value is allowed here")
+		}
+		return
+	}
+
+	if len(s.Type) > 0 && !matchesAnyType(value, s.Type) {
+		fail("expected %s, got %s", strings.Join(s.Type, " or "), typeOf(value))
+		return
+	}
+	if s.Enum != nil {
+		found := false
+		for _, candidate := range s.Enum {
+			if equal(value, candidate) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("value is not one of the allowed values")
+		}
+	}
+	if s.Const != nil && !equal(value, *s.Const) {
+		fail("value does not match the constant")
+	}
+
+	switch v := value.(type) {
+	case json.Number:
+		s.validateNumber(v, fail)
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.MinLength != nil && length < *s.MinLength {
+			fail("string is shorter than %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("string is longer than %d characters", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			fail("string does not match pattern %q", s.Pattern)
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("array has fewer than %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("array has more than %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(item, fmt.Sprintf("%s/%d", path, i), failures)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		for name, property := range v {
+			child := path + "/" + strings.ReplaceAll(strings.ReplaceAll(name, "~", "~0"), "/", "~1")
+			if schema, ok := s.Properties[name]; ok {
+				schema.validate(property, child, failures)
+			} else if s.AdditionalProperties != nil {
+				s.AdditionalProperties.validate(property, child, failures)
+			}
+		}
+	}
+
+	for _, sub := range s.AllOf {
+		sub.validate(value, path, failures)
+	}
+	if len(s.AnyOf) > 0 && countMatches(s.AnyOf, value) == 0 {
+		fail("value does not match any of the anyOf schemas")
+	}
+	if len(s.OneOf) > 0 {
+		if matched := countMatches(s.OneOf, value); matched != 1 {
+			fail("value matches %d of the oneOf schemas, expected exactly 1", matched)
+		}
+	}
+	if s.Not != nil && countMatches([]*Schema{s.Not}, value) == 1 {
+		fail("value must not match the not schema")
+	}
+}
+
+func (s *Schema) validateNumber(v json.Number, fail func(string, ...interface{})) {
+	n, ok := new(big.Float).SetString(v.String())
+	if !ok {
+		fail("invalid number %s", v)
+		return
+	}
+	compare := func(bound *json.Number) int {
+		b, _ := new(big.Float).SetString(bound.String())
+		return n.Cmp(b)
+	}
+	if s.Minimum != nil && compare(s.Minimum) < 0 {
+		fail("number is less than %s", *s.Minimum)
+	}
+	if s.Maximum != nil && compare(s.Maximum) > 0 {
+		fail("number is greater than %s", *s.Maximum)
+	}
+	if s.ExclusiveMinimum != nil && compare(s.ExclusiveMinimum) <= 0 {
+		fail("number must be greater than %s", *s.ExclusiveMinimum)
+	}
+	if s.ExclusiveMaximum != nil && compare(s.ExclusiveMaximum) >= 0 {
+		fail("number must be less than %s", *s.ExclusiveMaximum)
+	}
+}
+
+func countMatches(schemas []*Schema, value interface{}) int {
+	matched := 0
+	for _, sub := range schemas {
+		var failures []string
+		sub.validate(value, "", &failures)
+		if len(failures) == 0 {
+			matched++
+		}
+	}
+	return matched
+}
+
+func matchesAnyType(value interface{}, types []string) bool {
+	actual := typeOf(value)
+	for _, t := range types {
+		if t == actual || (t == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	case json.Number:
+		if n, ok := new(big.Float).SetString(v.String()); ok && n.IsInt() {
+			return "integer"
+		}
+		return "number"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func equal(a, b interface{}) bool {
+	switch av := a.(type) {
+	case json.Number:
+		bv, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		an, aok := new(big.Float).SetString(av.String())
+		bn, bok := new(big.Float).SetString(bv.String())
+		return aok && bok && an.Cmp(bn) == 0
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !equal(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for key, value := range av {
+			other, ok := bv[key]
+			if !ok || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
@@ -0,0 +1,139 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func decode(t *testing.T, doc string) interface{} {
+	t.Helper()
+	decoder := json.NewDecoder(strings.NewReader(doc))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		t.Fatalf("decoding %s: %v", doc, err)
+	}
+	return value
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+		doc    string
+		valid  bool
+	}{
+		{"true schema", `true`, `{"any":1}`, true},
+		{"false schema", `false`, `1`, false},
+		{"empty schema", `{}`, `[1, "a"]`, true},
+		{"unknown keywords ignored", `{"$schema":"x","title":"t","description":"d"}`, `1`, true},
+
+		{"type string", `{"type":"string"}`, `"a"`, true},
+		{"type string mismatch", `{"type":"string"}`, `1`, false},
+		{"type list", `{"type":["string","null"]}`, `null`, true},
+		{"type list mismatch", `{"type":["string","null"]}`, `true`, false},
+		{"type integer", `{"type":"integer"}`, `3`, true},
+		{"type integer with zero fraction", `{"type":"integer"}`, `3.0`, true},
+		{"type integer mismatch", `{"type":"integer"}`, `3.5`, false},
+		{"type number accepts integer", `{"type":"number"}`, `3`, true},
+		{"type boolean", `{"type":"boolean"}`, `false`, true},
+		{"type object", `{"type":"object"}`, `[]`, false},
+		{"type array", `{"type":"array"}`, `[]`, true},
+
+		{"enum", `{"enum":["a",1,null]}`, `1.0`, true},
+		{"enum null", `{"enum":["a",1,null]}`, `null`, true},
+		{"enum mismatch", `{"enum":["a",1,null]}`, `"b"`, false},
+		{"const", `{"const":{"a":[1,2]}}`, `{"a":[1,2]}`, true},
+		{"const mismatch", `{"const":{"a":[1,2]}}`, `{"a":[2,1]}`, false},
+		{"const null", `{"const":null}`, `null`, true},
+		{"const null mismatch", `{"const":null}`, `0`, false},
+		{"nested const null", `{"properties":{"a":{"const":null}}}`, `{"a":false}`, false},
+
+		{"properties", `{"properties":{"a":{"type":"string"}}}`, `{"a":"x","b":1}`, true},
+		{"properties mismatch", `{"properties":{"a":{"type":"string"}}}`, `{"a":1}`, false},
+		{"required", `{"required":["a"]}`, `{"a":null}`, true},
+		{"required missing", `{"required":["a"]}`, `{"b":1}`, false},
+		{"additionalProperties false", `{"properties":{"a":{}},"additionalProperties":false}`, `{"a":1,"b":2}`, false},
+		{"additionalProperties schema", `{"properties":{"a":{}},"additionalProperties":{"type":"integer"}}`, `{"a":"x","b":2}`, true},
+		{"additionalProperties schema mismatch", `{"additionalProperties":{"type":"integer"}}`, `{"b":"x"}`, false},
+
+		{"items", `{"items":{"type":"integer"}}`, `[1,2]`, true},
+		{"items mismatch", `{"items":{"type":"integer"}}`, `[1,"2"]`, false},
+		{"minItems", `{"minItems":2}`, `[1]`, false},
+		{"maxItems", `{"maxItems":2}`, `[1,2]`, true},
+		{"maxItems exceeded", `{"maxItems":2}`, `[1,2,3]`, false},
+
+		{"minimum", `{"minimum":1.5}`, `1.5`, true},
+		{"minimum violated", `{"minimum":1.5}`, `1.4`, false},
+		{"maximum", `{"maximum":10}`, `10`, true},
+		{"maximum violated", `{"maximum":10}`, `10.01`, false},
+		{"exclusiveMinimum", `{"exclusiveMinimum":0}`, `0`, false},
+		{"exclusiveMaximum", `{"exclusiveMaximum":5}`, `4.999`, true},
+		{"exclusiveMaximum violated", `{"exclusiveMaximum":5}`, `5`, false},
+		{"large numbers", `{"maximum":18446744073709551615}`, `18446744073709551616`, false},
+		{"numeric bounds ignore strings", `{"minimum":5}`, `"1"`, true},
+
+		{"minLength counts characters", `{"minLength":2}`, `"éé"`, true},
+		{"minLength violated", `{"minLength":2}`, `"é"`, false},
+		{"maxLength", `{"maxLength":3}`, `"abcd"`, false},
+		{"pattern", `{"pattern":"^[a-z]+$"}`, `"abc"`, true},
+		{"pattern mismatch", `{"pattern":"^[a-z]+$"}`, `"ab1"`, false},
+
+		{"allOf", `{"allOf":[{"type":"integer"},{"minimum":3}]}`, `4`, true},
+		{"allOf mismatch", `{"allOf":[{"type":"integer"},{"minimum":3}]}`, `2`, false},
+		{"anyOf", `{"anyOf":[{"type":"string"},{"type":"integer"}]}`, `2`, true},
+		{"anyOf mismatch", `{"anyOf":[{"type":"string"},{"type":"integer"}]}`, `true`, false},
+		{"oneOf", `{"oneOf":[{"type":"integer"},{"minimum":10}]}`, `5`, true},
+		{"oneOf matches several", `{"oneOf":[{"type":"integer"},{"minimum":10}]}`, `11`, false},
+		{"oneOf matches none", `{"oneOf":[{"type":"integer"},{"minimum":10}]}`, `1.5`, false},
+		{"not", `{"not":{"type":"null"}}`, `1`, true},
+		{"not mismatch", `{"not":{"type":"null"}}`, `null`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := Compile([]byte(tt.schema))
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			err = schema.Validate(decode(t, tt.doc))
+			if tt.valid && err != nil {
+				t.Errorf("Validate: %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("Validate succeeded, want a failure")
+			}
+		})
+	}
+}
+
+func TestValidateReportsLocation(t *testing.T) {
+	schema, err := Compile([]byte(`{"properties":{"a/b":{"items":{"type":"string"}}}}`))
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	err = schema.Validate(decode(t, `{"a/b":["x",1]}`))
+	validationErr, ok := err.(*ValidationError)
+	if !ok || len(validationErr.Failures) != 1 {
+		t.Fatalf("Validate = %v, want one failure", err)
+	}
+	if want := "/a~1b/1: expected string, got integer"; validationErr.Failures[0] != want {
+		t.Errorf("failure = %q, want %q", validationErr.Failures[0], want)
+	}
+}
+
+func TestCompileRejectsInvalidSchemas(t *testing.T) {
+	for _, schema := range []string{
+		`{"type":"text"}`,
+		`{"type":1}`,
+		`{"pattern":"("}`,
+		`{"properties":{"a":{"type":"nope"}}}`,
+		`{"anyOf":[{"pattern":"["}]}`,
+		`{"minLength":"1"}`,
+		`[]`,
+	} {
+		if _, err := Compile([]byte(schema)); err == nil {
+			t.Errorf("Compile(%s) succeeded, want an error", schema)
+		}
+	}
+}
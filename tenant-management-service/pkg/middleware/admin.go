@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"tenant-management-service/internal/response"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/logger"
+)
+
+// AdminMiddleware validates the X-Admin-Key header against the configured admin API key.
+// All requests are rejected when no key is configured.
+func AdminMiddleware(apiKey string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		adminKey := ctx.GetHeader("X-Admin-Key")
+
+		if apiKey == "" || subtle.ConstantTimeCompare([]byte(adminKey), []byte(apiKey)) != 1 {
+			logger.Warn("Invalid or missing X-Admin-Key in headers")
+			response.Error(
+				ctx,
+				http.StatusForbidden,
+				pkgerr.ErrForbidden.Error(),
+				"FORBIDDEN",
+				"Invalid or missing X-Admin-Key in headers",
+			)
+			ctx.Abort()
+			return
+		}
+
+		// Proceed to the next handler if validation is successful
+		ctx.Next()
+	}
+}
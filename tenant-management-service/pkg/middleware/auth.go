@@ -4,12 +4,16 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"net/http"
+	"tenant-management-service/internal/model"
 	"tenant-management-service/internal/response"
 	"tenant-management-service/internal/service"
 	pkgerr "tenant-management-service/pkg/error"
 	"tenant-management-service/pkg/logger"
 )
 
+// tenantContextKey is the gin context key holding the authenticated tenant.
+const tenantContextKey = "tenant"
+
 // AuthMiddleware validates client_id and client_secret in the headers.
 func AuthMiddleware(tenantService *service.TenantService) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -31,7 +35,7 @@ func AuthMiddleware(tenantService *service.TenantService) gin.HandlerFunc {
 		}
 
 		// Validate client_id and client_secret using the tenant service
-		isValid, err := tenantService.ValidateClientCredentials(clientId, clientSecret)
+		tenant, err := tenantService.AuthenticateClient(clientId, clientSecret)
 		if err != nil {
 			logger.Error("Error validating client credentials", zap.Error(err))
 			response.Error(
@@ -45,7 +49,7 @@ func AuthMiddleware(tenantService *service.TenantService) gin.HandlerFunc {
 			return
 		}
 
-		if !isValid {
+		if tenant == nil {
 			logger.Warn("Invalid client_id or client_secret", zap.String("client_id", clientId))
 			response.Error(
 				ctx,
@@ -59,6 +63,17 @@ func AuthMiddleware(tenantService *service.TenantService) gin.HandlerFunc {
 		}
 
 		// Proceed to the next handler if validation is successful
+		ctx.Set(tenantContextKey, tenant)
 		ctx.Next()
 	}
 }
+
+// CurrentTenant returns the tenant authenticated by AuthMiddleware, or nil if none.
+func CurrentTenant(ctx *gin.Context) *model.Tenant {
+	value, ok := ctx.Get(tenantContextKey)
+	if !ok {
+		return nil
+	}
+	tenant, _ := value.(*model.Tenant)
+	return tenant
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"tenant-management-service/internal/response"
+	pkgerr "tenant-management-service/pkg/error"
+	"tenant-management-service/pkg/logger"
+)
+
+// TenantOwnershipMiddleware rejects requests whose tenant_id path parameter names a tenant
+// other than the one authenticated by AuthMiddleware.
+func TenantOwnershipMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		tenantID := ctx.Param("tenant_id")
+		tenant := CurrentTenant(ctx)
+
+		if tenant == nil || strconv.FormatUint(uint64(tenant.ID), 10) != tenantID {
+			logger.Warn("Credentials do not belong to the requested tenant", zap.String("tenant_id", tenantID))
+			response.Error(
+				ctx,
+				http.StatusForbidden,
+				pkgerr.ErrForbidden.Error(),
+				"FORBIDDEN",
+				"Credentials do not belong to the requested tenant",
+			)
+			ctx.Abort()
+			return
+		}
+
+		// Proceed to the next handler if the tenant matches
+		ctx.Next()
+	}
+}
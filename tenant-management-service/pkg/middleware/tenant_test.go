@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"tenant-management-service/internal/model"
+	"testing"
+)
+
+func TestTenantOwnershipMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		tenant *model.Tenant
+		path   string
+		want   int
+	}{
+		{name: "own tenant", tenant: &model.Tenant{ID: 7}, path: "/tenants/7/configs", want: http.StatusOK},
+		{name: "other tenant", tenant: &model.Tenant{ID: 7}, path: "/tenants/8/configs", want: http.StatusForbidden},
+		{name: "padded id", tenant: &model.Tenant{ID: 7}, path: "/tenants/07/configs", want: http.StatusForbidden},
+		{name: "unauthenticated", tenant: nil, path: "/tenants/7/configs", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(ctx *gin.Context) {
+				if tt.tenant != nil {
+					ctx.Set(tenantContextKey, tt.tenant)
+				}
+			})
+			router.GET("/tenants/:tenant_id/configs", TenantOwnershipMiddleware(), func(ctx *gin.Context) {
+				ctx.Status(http.StatusOK)
+			})
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// idleBucketTTL is how long an untouched bucket is kept; any bucket idle for longer
+// has refilled completely and can be recreated on demand.
+const idleBucketTTL = 10 * time.Minute
+
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+// MemoryStore keeps buckets in process memory. It is only correct when a single
+// instance enforces the limits.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: make(map[string]*bucket)}
+}
+
+// Take implements Store.
+func (s *MemoryStore) Take(key string, limits []Limit, n int, now time.Time) (bool, []Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	// Refill every bucket and check whether all of them can serve the request
+	buckets := s.refill(key, limits, now)
+	allowed := true
+	for _, b := range buckets {
+		if b.tokens < float64(n) {
+			allowed = false
+		}
+	}
+
+	results := make([]Result, len(limits))
+	for i, limit := range limits {
+		b := buckets[i]
+		if allowed {
+			b.tokens -= float64(n)
+		}
+
+		results[i] = result(limit, b)
+		if !allowed && b.tokens < float64(n) {
+			results[i].RetryAfter = seconds((float64(n) - b.tokens) / limit.rate())
+		}
+	}
+	return allowed, results, nil
+}
+
+// Put implements Store.
+func (s *MemoryStore) Put(key string, limits []Limit, n int, now time.Time) ([]Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	buckets := s.refill(key, limits, now)
+	results := make([]Result, len(limits))
+	for i, limit := range limits {
+		b := buckets[i]
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+float64(n))
+		results[i] = result(limit, b)
+	}
+	return results, nil
+}
+
+// refill returns the buckets of key for limits, creating missing ones full and adding the
+// tokens refilled since they were last seen.
+func (s *MemoryStore) refill(key string, limits []Limit, now time.Time) []*bucket {
+	buckets := make([]*bucket, len(limits))
+	for i, limit := range limits {
+		b, ok := s.buckets[key+"|"+limit.Name]
+		if !ok {
+			b = &bucket{tokens: float64(limit.Burst), lastSeen: now}
+			s.buckets[key+"|"+limit.Name] = b
+		}
+		elapsed := now.Sub(b.lastSeen).Seconds()
+		if elapsed > 0 {
+			b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.rate())
+		}
+		b.lastSeen = now
+		buckets[i] = b
+	}
+	return buckets
+}
+
+// result describes the state of a bucket.
+func result(limit Limit, b *bucket) Result {
+	return Result{
+		Limit:     limit,
+		Remaining: int(math.Floor(b.tokens)),
+		Reset:     seconds((float64(limit.Burst) - b.tokens) / limit.rate()),
+	}
+}
+
+// sweep drops idle buckets at most once per idleBucketTTL.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < idleBucketTTL {
+		return
+	}
+	for key, b := range s.buckets {
+		if now.Sub(b.lastSeen) > idleBucketTTL {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastSweep = now
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Ceil(s * float64(time.Second)))
+}
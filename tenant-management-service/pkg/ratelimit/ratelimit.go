@@ -0,0 +1,115 @@
+// Package ratelimit implements token bucket rate limiting over a pluggable bucket store,
+// so a single process can keep buckets in memory while several instances share them.
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit describes one token bucket: Tokens are refilled every Period up to Burst.
+type Limit struct {
+	Name   string
+	Tokens int
+	Period time.Duration
+	Burst  int
+}
+
+// rate returns the refill rate in tokens per second.
+func (l Limit) rate() float64 {
+	return float64(l.Tokens) / l.Period.Seconds()
+}
+
+// Result is the state of one bucket after a Take.
+type Result struct {
+	Limit      Limit
+	Remaining  int
+	Reset      time.Duration // until the bucket is full again
+	RetryAfter time.Duration // until the requested tokens are available, zero if they were taken
+}
+
+// Store holds bucket state. Take must remove n tokens from every bucket of key if all of
+// them hold enough tokens and from none otherwise, atomically with respect to other callers.
+// Put adds n tokens back to every bucket of key, up to their burst.
+type Store interface {
+	Take(key string, limits []Limit, n int, now time.Time) (bool, []Result, error)
+	Put(key string, limits []Limit, n int, now time.Time) ([]Result, error)
+}
+
+// Decision is the outcome of a rate limit check.
+type Decision struct {
+	Allowed bool
+	Results []Result
+
+	// key and n identify the tokens taken, so that they can be refunded
+	key string
+	n   int
+}
+
+// Tightest returns the bucket closest to being exhausted, which is what clients are told
+// about in rate limit headers, or nil if no limits applied.
+func (d *Decision) Tightest() *Result {
+	var tightest *Result
+	for i := range d.Results {
+		result := &d.Results[i]
+		if tightest == nil || result.RetryAfter > tightest.RetryAfter ||
+			(result.RetryAfter == tightest.RetryAfter && result.Remaining < tightest.Remaining) {
+			tightest = result
+		}
+	}
+	return tightest
+}
+
+// Policy renders the applied limits in the RateLimit-Policy header format,
+// e.g. "10;w=1;burst=20, 600;w=60".
+func (d *Decision) Policy() string {
+	var policies []string
+	for _, result := range d.Results {
+		policy := fmt.Sprintf("%d;w=%d", result.Limit.Tokens, int(result.Limit.Period.Seconds()))
+		if result.Limit.Burst != result.Limit.Tokens {
+			policy += ";burst=" + strconv.Itoa(result.Limit.Burst)
+		}
+		policies = append(policies, policy)
+	}
+	return strings.Join(policies, ", ")
+}
+
+type Limiter struct {
+	store Store
+}
+
+func NewLimiter(store Store) *Limiter {
+	return &Limiter{store: store}
+}
+
+// Allow takes n tokens from every limit of key, or none if any of them is exhausted.
+func (l *Limiter) Allow(key string, limits []Limit, n int) (*Decision, error) {
+	if len(limits) == 0 {
+		return &Decision{Allowed: true}, nil
+	}
+	allowed, results, err := l.store.Take(key, limits, n, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	return &Decision{Allowed: allowed, Results: results, key: key, n: n}, nil
+}
+
+// Refund returns the tokens taken by an allowed decision, e.g. because the request was
+// rejected for another reason, and updates the results of the decision.
+func (l *Limiter) Refund(d *Decision) error {
+	if !d.Allowed || len(d.Results) == 0 {
+		return nil
+	}
+	limits := make([]Limit, len(d.Results))
+	for i, result := range d.Results {
+		limits[i] = result.Limit
+	}
+	results, err := l.store.Put(d.key, limits, d.n, time.Now())
+	if err != nil {
+		return err
+	}
+	d.Results = results
+	return nil
+}
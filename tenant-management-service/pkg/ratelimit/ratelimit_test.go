@@ -0,0 +1,181 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+var (
+	perSecond = Limit{Name: "second", Tokens: 2, Period: time.Second, Burst: 4}
+	perMinute = Limit{Name: "minute", Tokens: 6, Period: time.Minute, Burst: 6}
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	store := NewMemoryStore()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		name      string
+		at        time.Duration
+		n         int
+		allowed   bool
+		remaining []int
+	}{
+		{"full buckets serve a burst", 0, 4, true, []int{0, 2}},
+		{"empty second bucket rejects", 0, 1, false, []int{0, 2}},
+		{"second bucket refills at its rate", 500 * time.Millisecond, 1, true, []int{0, 1}},
+		{"rejection takes from no bucket", 2 * time.Second, 2, false, []int{3, 1}},
+		{"minute bucket refills at its rate", 12 * time.Second, 2, true, []int{2, 0}},
+		{"buckets refill up to their burst", 10 * time.Minute, 0, true, []int{4, 6}},
+	}
+	for _, step := range steps {
+		allowed, results, err := store.Take("tenant|sms", []Limit{perSecond, perMinute}, step.n, start.Add(step.at))
+		if err != nil {
+			t.Fatalf("%s: Take: %v", step.name, err)
+		}
+		if allowed != step.allowed {
+			t.Errorf("%s: allowed = %v, want %v", step.name, allowed, step.allowed)
+		}
+		for i, want := range step.remaining {
+			if results[i].Remaining != want {
+				t.Errorf("%s: %s bucket remaining = %d, want %d", step.name, results[i].Limit.Name, results[i].Remaining, want)
+			}
+		}
+	}
+}
+
+func TestMemoryStoreTakeTimings(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, _, err := store.Take("k", []Limit{perSecond}, 4, now); err != nil {
+		t.Fatalf("Take: %v", err)
+	}
+	allowed, results, err := store.Take("k", []Limit{perSecond}, 3, now)
+	if err != nil {
+		t.Fatalf("Take: %v", err)
+	}
+	if allowed {
+		t.Fatal("Take of an empty bucket was allowed")
+	}
+	if got, want := results[0].RetryAfter, 1500*time.Millisecond; got != want {
+		t.Errorf("RetryAfter = %v, want %v", got, want)
+	}
+	if got, want := results[0].Reset, 2*time.Second; got != want {
+		t.Errorf("Reset = %v, want %v", got, want)
+	}
+}
+
+func TestMemoryStoreKeysAreIndependent(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	if allowed, _, _ := store.Take("a", []Limit{perSecond}, 4, now); !allowed {
+		t.Fatal("first Take was rejected")
+	}
+	if allowed, _, _ := store.Take("b", []Limit{perSecond}, 4, now); !allowed {
+		t.Error("Take of another key was rejected")
+	}
+}
+
+func TestMemoryStorePut(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	limits := []Limit{perSecond, perMinute}
+
+	if _, _, err := store.Take("k", limits, 3, now); err != nil {
+		t.Fatalf("Take: %v", err)
+	}
+	results, err := store.Put("k", limits, 2, now)
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if results[0].Remaining != 3 || results[1].Remaining != 5 {
+		t.Errorf("remaining after Put = %d, %d, want 3, 5", results[0].Remaining, results[1].Remaining)
+	}
+
+	// Tokens put back never exceed the burst
+	results, err = store.Put("k", limits, 10, now)
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if results[0].Remaining != perSecond.Burst || results[1].Remaining != perMinute.Burst {
+		t.Errorf("remaining after Put = %d, %d, want full buckets", results[0].Remaining, results[1].Remaining)
+	}
+}
+
+func TestMemoryStoreSweepsIdleBuckets(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.Take("idle", []Limit{perSecond}, 1, now)
+	store.Take("busy", []Limit{perSecond}, 1, now.Add(idleBucketTTL+time.Second))
+
+	if _, ok := store.buckets["idle|second"]; ok {
+		t.Error("idle bucket was not swept")
+	}
+	if _, ok := store.buckets["busy|second"]; !ok {
+		t.Error("busy bucket was swept")
+	}
+}
+
+func TestLimiterRefund(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore())
+	limits := []Limit{perSecond}
+
+	decision, err := limiter.Allow("k", limits, 4)
+	if err != nil || !decision.Allowed {
+		t.Fatalf("Allow = %v, %v", decision, err)
+	}
+	if err := limiter.Refund(decision); err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	if decision.Results[0].Remaining != perSecond.Burst {
+		t.Errorf("remaining after Refund = %d, want %d", decision.Results[0].Remaining, perSecond.Burst)
+	}
+
+	// The refunded tokens can be taken again
+	if decision, err := limiter.Allow("k", limits, 4); err != nil || !decision.Allowed {
+		t.Errorf("Allow after Refund = %v, %v", decision, err)
+	}
+
+	// Rejected decisions took nothing to refund
+	rejected, err := limiter.Allow("k", limits, 1)
+	if err != nil || rejected.Allowed {
+		t.Fatalf("Allow of an empty bucket = %v, %v", rejected, err)
+	}
+	if err := limiter.Refund(rejected); err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	if decision, _ := limiter.Allow("k", limits, 1); decision.Allowed {
+		t.Error("Refund of a rejected decision added tokens")
+	}
+}
+
+func TestLimiterWithoutLimits(t *testing.T) {
+	limiter := NewLimiter(NewMemoryStore())
+	decision, err := limiter.Allow("k", nil, 100)
+	if err != nil || !decision.Allowed || decision.Tightest() != nil {
+		t.Errorf("Allow without limits = %+v, %v", decision, err)
+	}
+	if err := limiter.Refund(decision); err != nil {
+		t.Errorf("Refund: %v", err)
+	}
+}
+
+func TestDecision(t *testing.T) {
+	decision := &Decision{Results: []Result{
+		{Limit: perSecond, Remaining: 1},
+		{Limit: perMinute, Remaining: 0},
+		{Limit: Limit{Name: "other", Tokens: 5, Period: time.Second, Burst: 5}, Remaining: 3, RetryAfter: time.Second},
+	}}
+
+	if tightest := decision.Tightest(); tightest.Limit.Name != "other" {
+		t.Errorf("Tightest = %s, want the bucket with the longest RetryAfter", tightest.Limit.Name)
+	}
+	decision.Results = decision.Results[:2]
+	if tightest := decision.Tightest(); tightest.Limit.Name != "minute" {
+		t.Errorf("Tightest = %s, want the bucket with the fewest remaining tokens", tightest.Limit.Name)
+	}
+	if got, want := decision.Policy(), "2;w=1;burst=4, 6;w=60"; got != want {
+		t.Errorf("Policy = %q, want %q", got, want)
+	}
+}
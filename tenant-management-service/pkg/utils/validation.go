@@ -3,6 +3,7 @@ package utils
 import (
 	"fmt"
 	"regexp"
+	"time"
 )
 
 // ValidationError is a custom error type to capture validation error.
@@ -66,3 +67,36 @@ func ValidateMaxLength(value string, fieldName string, maxLength int) error {
 	}
 	return nil
 }
+
+// ValidateSlug checks that a string is a non-empty identifier made of lowercase letters,
+// digits, dots, dashes and underscores, not exceeding the maximum length.
+func ValidateSlug(value string, fieldName string, maxLength int) error {
+	slugRegex := `^[a-z0-9][a-z0-9._-]*$`
+	match, _ := regexp.MatchString(slugRegex, value)
+	if !match {
+		return &ValidationError{
+			Field:   fieldName,
+			Message: "Field must contain only lowercase letters, digits, '.', '-' and '_'",
+		}
+	}
+	return ValidateMaxLength(value, fieldName, maxLength)
+}
+
+// ValidateTimezone checks that a string is a known IANA timezone name.
+func ValidateTimezone(value string, fieldName string) error {
+	if _, err := time.LoadLocation(value); err != nil || value == "" || value == "Local" {
+		return &ValidationError{Field: fieldName, Message: "Field must be a valid IANA timezone such as Europe/Berlin"}
+	}
+	return nil
+}
+
+// ValidateRange checks that an integer lies within [min, max].
+func ValidateRange(value int, fieldName string, min, max int) error {
+	if value < min || value > max {
+		return &ValidationError{
+			Field:   fieldName,
+			Message: fmt.Sprintf("Field must be between %d and %d", min, max),
+		}
+	}
+	return nil
+}
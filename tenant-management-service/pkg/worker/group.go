@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"sync"
+	"tenant-management-service/pkg/logger"
+	"time"
+)
+
+// Group runs background workers that share a lifetime and stops them together.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewGroup() *Group {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Group{ctx: ctx, cancel: cancel}
+}
+
+// Go runs fn in a goroutine. fn must return once its context is cancelled.
+func (g *Group) Go(name string, fn func(ctx context.Context)) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		logger.Info("Background worker started", zap.String("worker", name))
+		fn(g.ctx)
+		logger.Info("Background worker stopped", zap.String("worker", name))
+	}()
+}
+
+// Every runs fn once per interval until the group is stopped.
+func (g *Group) Every(name string, interval time.Duration, fn func(ctx context.Context)) {
+	g.Go(name, func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				fn(ctx)
+			}
+		}
+	})
+}
+
+// Stop cancels every worker and waits for them to return.
+func (g *Group) Stop() {
+	g.cancel()
+	g.wg.Wait()
+}